@@ -12,6 +12,7 @@ import (
 
 	// us
 	"github.com/openshift/console-operator/pkg/cmd/operator"
+	"github.com/openshift/console-operator/pkg/cmd/render"
 	"github.com/openshift/console-operator/pkg/cmd/version"
 )
 
@@ -38,6 +39,7 @@ func NewOperatorCommand() *cobra.Command {
 
 	cmd.AddCommand(operator.NewOperator())
 	cmd.AddCommand(version.NewVersion())
+	cmd.AddCommand(render.NewRender())
 
 	return cmd
 }
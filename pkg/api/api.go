@@ -6,14 +6,23 @@ const (
 	CLIOIDCClientComponentName          = "cli"
 	ClusterOperatorName                 = "console"
 	ConfigResourceName                  = "cluster"
+	ConsoleCanaryConfigMapName          = "console-config-canary"
+	ConsoleCanaryName                   = "console-canary"
+	ConsoleConfigLastKnownGoodName      = "console-config-last-known-good"
+	ConsoleConfigProvenanceName         = "console-config-provenance"
+	ConsoleContainerName                = "console"
 	ConsoleContainerPort                = 443
 	ConsoleContainerPortName            = "https"
 	ConsoleContainerTargetPort          = 8443
+	ConsoleOIDCDiscoveryName            = "console-oidc-discovery"
+	ConsolePluginQuarantineName         = "console-plugin-quarantine"
 	ConsoleServingCertName              = "console-serving-cert"
 	DefaultIngressCertConfigMapName     = "default-ingress-cert"
+	DownloadsContainerName              = "download-server"
 	DownloadsPort                       = 8080
 	DownloadsPortName                   = "http"
 	DownloadsResourceName               = "downloads"
+	NextOAuthClientSecretKey            = "nextClientSecret"
 	NodeArchitectureLabel               = "kubernetes.io/arch"
 	NodeOperatingSystemLabel            = "kubernetes.io/os"
 	OAuthConfigMapName                  = "oauth-openshift"
@@ -35,9 +44,17 @@ const (
 	OpenshiftConsoleCustomRouteName     = "console-custom"
 	OpenshiftDownloadsCustomRouteName   = "downloads-custom"
 	OpenshiftConsoleRedirectServiceName = "console-redirect"
+	PluginBackendProbeAnnotation        = "console.openshift.io/backend-probe"
+	PreviousOAuthClientSecretKey        = "previousClientSecret"
+	PreviousSessionAuthenticationKey    = "previousSessionAuthenticationKey"
+	PreviousSessionEncryptionKey        = "previousSessionEncryptionKey"
 	RedirectContainerPort               = 8444
 	RedirectContainerPortName           = "custom-route-redirect"
+	ResourceSizingConfigMapName         = "console-resource-sizing"
 	ServiceCAConfigMapName              = "service-ca"
+	SessionAuthenticationKey            = "sessionAuthenticationKey"
+	SessionEncryptionKey                = "sessionEncryptionKey"
+	SessionSecretMountDir               = "/var/session-secret"
 	SessionSecretName                   = "session-secret"
 	TargetNamespace                     = "openshift-console"
 	TrustedCABundleKey                  = "ca-bundle.crt"
@@ -0,0 +1,298 @@
+package render
+
+import (
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/ghodss/yaml"
+	"github.com/spf13/cobra"
+
+	// kube
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/scheme"
+
+	// openshift
+	configv1 "github.com/openshift/api/config/v1"
+	operatorv1 "github.com/openshift/api/operator/v1"
+	routev1 "github.com/openshift/api/route/v1"
+
+	// operator
+	"github.com/openshift/console-operator/pkg/api"
+	pdb "github.com/openshift/console-operator/pkg/console/controllers/poddisruptionbudget"
+	"github.com/openshift/console-operator/pkg/console/controllers/service"
+	configmapsub "github.com/openshift/console-operator/pkg/console/subresource/configmap"
+	deploymentsub "github.com/openshift/console-operator/pkg/console/subresource/deployment"
+	routesub "github.com/openshift/console-operator/pkg/console/subresource/route"
+	secretsub "github.com/openshift/console-operator/pkg/console/subresource/secret"
+)
+
+type renderOpts struct {
+	operatorConfigFile       string
+	consoleConfigFile        string
+	infrastructureConfigFile string
+	authenticationConfigFile string
+	proxyConfigFile          string
+	ingressConfigFile        string
+	oauthConfigFile          string
+	outputDir                string
+}
+
+func NewRender() *cobra.Command {
+	opts := &renderOpts{}
+	cmd := &cobra.Command{
+		Use:   "render",
+		Short: "Render the resources managed by the Operator without a cluster",
+		Long: `Render reads the operator and cluster configuration from files and writes
+the console resources the operator would apply for them, either to stdout or
+to a directory. Any config that is not provided is treated as empty.`,
+		RunE: func(command *cobra.Command, args []string) error {
+			return opts.Run(command.OutOrStdout())
+		},
+	}
+
+	flags := cmd.Flags()
+	flags.StringVar(&opts.operatorConfigFile, "operator-config", "", "path to a consoles.operator.openshift.io manifest")
+	flags.StringVar(&opts.consoleConfigFile, "console-config", "", "path to a consoles.config.openshift.io manifest")
+	flags.StringVar(&opts.infrastructureConfigFile, "infrastructure-config", "", "path to an infrastructures.config.openshift.io manifest")
+	flags.StringVar(&opts.authenticationConfigFile, "authentication-config", "", "path to an authentications.config.openshift.io manifest")
+	flags.StringVar(&opts.proxyConfigFile, "proxy-config", "", "path to a proxies.config.openshift.io manifest")
+	flags.StringVar(&opts.ingressConfigFile, "ingress-config", "", "path to an ingresses.config.openshift.io manifest")
+	flags.StringVar(&opts.oauthConfigFile, "oauth-config", "", "path to an oauths.config.openshift.io manifest")
+	flags.StringVar(&opts.outputDir, "output-dir", "", "directory to write the rendered manifests to, defaults to stdout")
+
+	return cmd
+}
+
+func (o *renderOpts) Run(out io.Writer) error {
+	operatorConfig := &operatorv1.Console{
+		ObjectMeta: metav1.ObjectMeta{Name: api.ConfigResourceName},
+		Spec: operatorv1.ConsoleSpec{
+			OperatorSpec: operatorv1.OperatorSpec{ManagementState: operatorv1.Managed},
+		},
+	}
+	consoleConfig := &configv1.Console{}
+	infrastructureConfig := &configv1.Infrastructure{}
+	authnConfig := &configv1.Authentication{}
+	proxyConfig := &configv1.Proxy{}
+	ingressConfig := &configv1.Ingress{}
+	oauthConfig := &configv1.OAuth{}
+
+	for _, input := range []struct {
+		file string
+		obj  interface{}
+	}{
+		{o.operatorConfigFile, operatorConfig},
+		{o.consoleConfigFile, consoleConfig},
+		{o.infrastructureConfigFile, infrastructureConfig},
+		{o.authenticationConfigFile, authnConfig},
+		{o.proxyConfigFile, proxyConfig},
+		{o.ingressConfigFile, ingressConfig},
+		{o.oauthConfigFile, oauthConfig},
+	} {
+		if err := readManifest(input.file, input.obj); err != nil {
+			return err
+		}
+	}
+
+	objects, err := renderObjects(operatorConfig, consoleConfig, infrastructureConfig, authnConfig, proxyConfig, ingressConfig, oauthConfig)
+	if err != nil {
+		return err
+	}
+	return o.write(out, objects)
+}
+
+// renderObjects runs the same builders the operator controllers use, substituting
+// stubs for the resources that only exist on a live cluster.
+func renderObjects(
+	operatorConfig *operatorv1.Console,
+	consoleConfig *configv1.Console,
+	infrastructureConfig *configv1.Infrastructure,
+	authnConfig *configv1.Authentication,
+	proxyConfig *configv1.Proxy,
+	ingressConfig *configv1.Ingress,
+	oauthConfig *configv1.OAuth,
+) ([]runtime.Object, error) {
+	objects := []runtime.Object{}
+
+	consoleRouteConfig := routesub.NewRouteConfig(operatorConfig, ingressConfig, api.OpenShiftConsoleRouteName)
+	consoleRoutes := routes(consoleRouteConfig, ingressConfig, api.OpenShiftConsoleRouteName)
+	downloadsRouteConfig := routesub.NewRouteConfig(operatorConfig, ingressConfig, api.OpenShiftConsoleDownloadsRouteName)
+	downloadsRoutes := routes(downloadsRouteConfig, ingressConfig, api.OpenShiftConsoleDownloadsRouteName)
+
+	// the last route is the active one, the custom route takes precedence when set
+	activeConsoleRoute := consoleRoutes[len(consoleRoutes)-1]
+	consoleHost := activeConsoleRoute.Spec.Host
+	if len(operatorConfig.Spec.Ingress.ConsoleURL) != 0 {
+		consoleURL, err := url.Parse(operatorConfig.Spec.Ingress.ConsoleURL)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse console url: %w", err)
+		}
+		activeConsoleRoute = nil
+		consoleHost = consoleURL.Hostname()
+	}
+
+	// the OAuthClient is not available offline, only the cluster wide default applies, or
+	// the operator config one on OIDC clusters
+	inactivityTimeoutSeconds := 0
+	if oauthConfig.Spec.TokenConfig.AccessTokenInactivityTimeout != nil {
+		inactivityTimeoutSeconds = int(oauthConfig.Spec.TokenConfig.AccessTokenInactivityTimeout.Seconds())
+	}
+
+	var (
+		authServerCAConfig        *corev1.ConfigMap
+		oauthServingCertConfigMap *corev1.ConfigMap
+		sessionSecret             *corev1.Secret
+	)
+	switch authnConfig.Spec.Type {
+	case configv1.AuthenticationTypeOIDC:
+		if len(authnConfig.Spec.OIDCProviders) > 0 {
+			if caName := authnConfig.Spec.OIDCProviders[0].Issuer.CertificateAuthority.Name; len(caName) > 0 {
+				authServerCAConfig = configMapStub(caName)
+			}
+		}
+		sessionSecret = secretsub.DefaultSessionSecret(operatorConfig)
+		inactivityTimeoutSeconds, _ = configmapsub.GetOIDCInactivityTimeoutSeconds(operatorConfig)
+	default:
+		oauthServingCertConfigMap = configMapStub(api.OAuthServingCertConfigMapName)
+	}
+
+	consoleConfigMap, provenanceConfigMap, _, err := configmapsub.DefaultConfigMap(configmapsub.DefaultConfigMapOptions{
+		OperatorConfig:           operatorConfig,
+		ConsoleConfig:            consoleConfig,
+		AuthConfig:               authnConfig,
+		SessionSecret:            sessionSecret,
+		InfrastructureConfig:     infrastructureConfig,
+		ActiveConsoleRoute:       activeConsoleRoute,
+		InactivityTimeoutSeconds: inactivityTimeoutSeconds,
+		ConsoleHost:              consoleHost,
+	})
+	if err != nil {
+		return nil, err
+	}
+	objects = append(objects, consoleConfigMap, provenanceConfigMap)
+
+	objects = append(objects, deploymentsub.DefaultDeployment(
+		operatorConfig,
+		consoleConfigMap,
+		configmapsub.DefaultServiceCAConfigMap(operatorConfig),
+		oauthServingCertConfigMap,
+		authServerCAConfig,
+		configmapsub.DefaultTrustedCAConfigMap(operatorConfig),
+		secretsub.Stub(),
+		sessionSecret,
+		proxyConfig,
+		infrastructureConfig,
+		nil,
+		nil,
+		nil,
+		len(operatorConfig.Spec.Customization.CustomLogoFile.Name) != 0,
+	))
+	objects = append(objects, deploymentsub.DefaultDownloadsDeployment(operatorConfig, infrastructureConfig, nil, nil, nil))
+
+	objects = append(objects, service.DefaultService(api.OpenShiftConsoleServiceName, false))
+	if consoleRouteConfig.IsCustomHostnameSet() {
+		objects = append(objects, service.RedirectService(api.OpenShiftConsoleServiceName))
+	}
+	objects = append(objects, service.DefaultService(api.DownloadsResourceName, false))
+
+	if len(operatorConfig.Spec.Ingress.ConsoleURL) == 0 {
+		for _, route := range consoleRoutes {
+			objects = append(objects, route)
+		}
+	}
+	if len(operatorConfig.Spec.Ingress.ClientDownloadsURL) == 0 {
+		for _, route := range downloadsRoutes {
+			objects = append(objects, route)
+		}
+	}
+
+	objects = append(objects, pdb.DefaultPodDisruptionBudget(api.OpenShiftConsolePDBName))
+	objects = append(objects, pdb.DefaultPodDisruptionBudget(api.OpenShiftConsoleDownloadsPDBName))
+
+	return objects, nil
+}
+
+// routes returns the default route and, if a custom hostname is set, the custom route.
+// Custom TLS secrets are not read, the routes use the default ingress certificate.
+func routes(routeConfig *routesub.RouteConfig, ingressConfig *configv1.Ingress, routeName string) []*routev1.Route {
+	routes := []*routev1.Route{routeConfig.DefaultRoute(nil, ingressConfig)}
+	if routeConfig.IsCustomHostnameSet() && !routeConfig.HostnameMatch() {
+		routes = append(routes, routeConfig.CustomRoute(nil, routeName))
+	}
+	return routes
+}
+
+func configMapStub(name string) *corev1.ConfigMap {
+	return &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: api.OpenShiftConsoleNamespace,
+		},
+	}
+}
+
+func readManifest(file string, obj interface{}) error {
+	if len(file) == 0 {
+		return nil
+	}
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return err
+	}
+	if err := yaml.Unmarshal(data, obj); err != nil {
+		return fmt.Errorf("failed to decode %s: %w", file, err)
+	}
+	return nil
+}
+
+func (o *renderOpts) write(out io.Writer, objects []runtime.Object) error {
+	renderScheme := runtime.NewScheme()
+	if err := scheme.AddToScheme(renderScheme); err != nil {
+		return err
+	}
+	if err := routev1.Install(renderScheme); err != nil {
+		return err
+	}
+
+	if len(o.outputDir) != 0 {
+		if err := os.MkdirAll(o.outputDir, 0755); err != nil {
+			return err
+		}
+	}
+
+	for i, obj := range objects {
+		gvks, _, err := renderScheme.ObjectKinds(obj)
+		if err != nil {
+			return err
+		}
+		obj.GetObjectKind().SetGroupVersionKind(gvks[0])
+
+		data, err := yaml.Marshal(obj)
+		if err != nil {
+			return err
+		}
+
+		if len(o.outputDir) == 0 {
+			if i > 0 {
+				fmt.Fprintln(out, "---")
+			}
+			if _, err := out.Write(data); err != nil {
+				return err
+			}
+			continue
+		}
+
+		name := obj.(metav1.Object).GetName()
+		fileName := fmt.Sprintf("%s-%s.yaml", name, strings.ToLower(gvks[0].Kind))
+		if err := os.WriteFile(filepath.Join(o.outputDir, fileName), data, 0644); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,72 @@
+package render
+
+import (
+	"bytes"
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+var update = flag.Bool("update", false, "update the golden files of the render tests")
+
+func TestRender(t *testing.T) {
+	// the images and the release version come from the operator environment
+	t.Setenv("CONSOLE_IMAGE", "quay.io/openshift/origin-console:latest")
+	t.Setenv("DOWNLOADS_IMAGE", "quay.io/openshift/origin-cli-artifacts:latest")
+	t.Setenv("OPERATOR_IMAGE_VERSION", "4.99.0")
+
+	opts := &renderOpts{
+		operatorConfigFile:       filepath.Join("testdata", "operator-config.yaml"),
+		infrastructureConfigFile: filepath.Join("testdata", "infrastructure-config.yaml"),
+		ingressConfigFile:        filepath.Join("testdata", "ingress-config.yaml"),
+	}
+	out := &bytes.Buffer{}
+	if err := opts.Run(out); err != nil {
+		t.Fatal(err)
+	}
+
+	goldenFile := filepath.Join("testdata", "render.golden.yaml")
+	if *update {
+		if err := os.WriteFile(goldenFile, out.Bytes(), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	golden, err := os.ReadFile(goldenFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(string(golden), out.String()); len(diff) > 0 {
+		t.Errorf("rendered manifests differ from %s, run the test with -update if the change is expected:\n%s", goldenFile, diff)
+	}
+}
+
+func TestRenderOutputDir(t *testing.T) {
+	opts := &renderOpts{
+		ingressConfigFile: filepath.Join("testdata", "ingress-config.yaml"),
+		outputDir:         t.TempDir(),
+	}
+	if err := opts.Run(&bytes.Buffer{}); err != nil {
+		t.Fatal(err)
+	}
+	for _, fileName := range []string{
+		"console-config-configmap.yaml",
+		"console-deployment.yaml",
+		"console-route.yaml",
+		"downloads-route.yaml",
+		"console-poddisruptionbudget.yaml",
+	} {
+		if _, err := os.Stat(filepath.Join(opts.outputDir, fileName)); err != nil {
+			t.Errorf("expected %s to be rendered: %v", fileName, err)
+		}
+	}
+}
+
+func TestRenderInvalidManifest(t *testing.T) {
+	opts := &renderOpts{operatorConfigFile: filepath.Join("testdata", "missing.yaml")}
+	if err := opts.Run(&bytes.Buffer{}); err == nil {
+		t.Error("expected an error for a missing manifest")
+	}
+}
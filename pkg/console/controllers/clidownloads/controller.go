@@ -13,6 +13,9 @@ import (
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/client-go/dynamic"
+	networkinginformersv1 "k8s.io/client-go/informers/networking/v1"
+	networkingv1listers "k8s.io/client-go/listers/networking/v1"
 	"k8s.io/klog/v2"
 
 	// openshift
@@ -40,7 +43,10 @@ import (
 	// operator
 	"github.com/openshift/console-operator/pkg/api"
 	controllersutil "github.com/openshift/console-operator/pkg/console/controllers/util"
+	"github.com/openshift/console-operator/pkg/console/metrics"
 	"github.com/openshift/console-operator/pkg/console/status"
+	httproutesub "github.com/openshift/console-operator/pkg/console/subresource/httproute"
+	ingresssub "github.com/openshift/console-operator/pkg/console/subresource/ingress"
 	routesub "github.com/openshift/console-operator/pkg/console/subresource/route"
 	"github.com/openshift/console-operator/pkg/console/subresource/util"
 )
@@ -49,7 +55,9 @@ type CLIDownloadsSyncController struct {
 	// clients
 	operatorClient            v1helpers.OperatorClient
 	consoleCliDownloadsClient consoleclientv1.ConsoleCLIDownloadInterface
+	dynamicClient             dynamic.Interface
 	routeLister               routev1listers.RouteLister
+	ingressLister             networkingv1listers.IngressLister
 	ingressConfigLister       configlistersv1.IngressLister
 	operatorConfigLister      operatorv1listers.ConsoleLister
 }
@@ -60,11 +68,13 @@ func NewCLIDownloadsSyncController(
 	// clients
 	operatorClient v1helpers.OperatorClient,
 	cliDownloadsInterface consoleclientv1.ConsoleCLIDownloadInterface,
+	dynamicClient dynamic.Interface,
 	// informers
 	operatorConfigInformer operatorinformersv1.ConsoleInformer,
 	configInformer configinformer.SharedInformerFactory,
 	consoleCLIDownloadsInformers consoleinformersv1.ConsoleCLIDownloadInformer,
 	routeInformer routesinformersv1.RouteInformer,
+	ingressInformer networkinginformersv1.IngressInformer,
 	// events
 	recorder events.Recorder,
 ) factory.Controller {
@@ -73,7 +83,9 @@ func NewCLIDownloadsSyncController(
 		// clients
 		operatorClient:            operatorClient,
 		consoleCliDownloadsClient: cliDownloadsInterface,
+		dynamicClient:             dynamicClient,
 		routeLister:               routeInformer.Lister(),
+		ingressLister:             ingressInformer.Lister(),
 		ingressConfigLister:       configInformer.Config().V1().Ingresses().Lister(),
 		operatorConfigLister:      operatorConfigInformer.Lister(),
 	}
@@ -86,11 +98,12 @@ func NewCLIDownloadsSyncController(
 			operatorConfigInformer.Informer(),
 			configV1Informers.Ingresses().Informer(),
 		).WithFilteredEventsInformers( // console resources
-		controllersutil.IncludeNamesFilter(api.OpenShiftConsoleDownloadsRouteName),
+		controllersutil.IncludeNamesFilter(api.OpenShiftConsoleDownloadsRouteName, api.OpenshiftDownloadsCustomRouteName),
 		routeInformer.Informer(),
+		ingressInformer.Informer(),
 	).WithInformers(
 		consoleCLIDownloadsInformers.Informer(),
-	).ResyncEvery(time.Minute).WithSync(ctrl.Sync).
+	).ResyncEvery(time.Minute).WithSync(metrics.InstrumentSync("ConsoleCLIDownloadsController", ctrl.Sync)).
 		ToController("ConsoleCLIDownloadsController", recorder.WithComponentSuffix("console-cli-downloads-controller"))
 }
 
@@ -132,14 +145,26 @@ func (c *CLIDownloadsSyncController) Sync(ctx context.Context, controllerContext
 			activeRouteName = api.OpenshiftDownloadsCustomRouteName
 		}
 
-		downloadsRoute, downloadsRouteErr := c.routeLister.Routes(api.TargetNamespace).Get(activeRouteName)
-		if downloadsRouteErr != nil {
-			return downloadsRouteErr
-		}
-
-		downloadsURI, _, downloadsErr = routeapihelpers.IngressURI(downloadsRoute, downloadsRoute.Spec.Host)
-		if downloadsErr != nil {
-			return downloadsErr
+		if httproutesub.IsGatewayEnabled(updatedOperatorConfig) {
+			_, downloadsURI, _, downloadsErr = httproutesub.GetActiveHTTPRouteInfo(ctx, c.dynamicClient, activeRouteName)
+			if downloadsErr != nil {
+				return downloadsErr
+			}
+		} else if ingresssub.IsIngressEnabled(updatedOperatorConfig) {
+			_, downloadsURI, _, downloadsErr = ingresssub.GetActiveIngressInfo(c.ingressLister, activeRouteName)
+			if downloadsErr != nil {
+				return downloadsErr
+			}
+		} else {
+			downloadsRoute, downloadsRouteErr := c.routeLister.Routes(api.TargetNamespace).Get(activeRouteName)
+			if downloadsRouteErr != nil {
+				return downloadsRouteErr
+			}
+
+			downloadsURI, _, downloadsErr = routeapihelpers.IngressURI(downloadsRoute, downloadsRoute.Spec.Host)
+			if downloadsErr != nil {
+				return downloadsErr
+			}
 		}
 	} else {
 		downloadsURI, downloadsErr = url.Parse(operatorConfig.Spec.Ingress.ClientDownloadsURL)
@@ -15,6 +15,7 @@ import (
 	operatorv1informers "github.com/openshift/client-go/operator/informers/externalversions/operator/v1"
 	"github.com/openshift/console-operator/pkg/api"
 	"github.com/openshift/console-operator/pkg/console/controllers/util"
+	"github.com/openshift/console-operator/pkg/console/metrics"
 	"github.com/openshift/console-operator/pkg/console/status"
 	"github.com/openshift/library-go/pkg/controller/factory"
 	"github.com/openshift/library-go/pkg/operator/events"
@@ -62,7 +63,7 @@ func NewCLIOIDCClientStatusController(
 		operatorClient:             operatorClient,
 	}
 	return factory.New().
-		WithSync(c.sync).
+		WithSync(metrics.InstrumentSync("CLIOIDCClientStatusController", c.sync)).
 		ResyncEvery(wait.Jitter(time.Minute, 1.0)).
 		WithInformers(
 			authnInformer.Informer(),
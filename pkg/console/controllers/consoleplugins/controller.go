@@ -0,0 +1,473 @@
+package consoleplugins
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	// k8s
+	corev1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/wait"
+	coreinformersv1 "k8s.io/client-go/informers/core/v1"
+	discoveryv1informers "k8s.io/client-go/informers/discovery/v1"
+	corev1client "k8s.io/client-go/kubernetes/typed/core/v1"
+	corev1listers "k8s.io/client-go/listers/core/v1"
+	discoveryv1listers "k8s.io/client-go/listers/discovery/v1"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/klog/v2"
+
+	// openshift
+	consolev1 "github.com/openshift/api/console/v1"
+	operatorv1 "github.com/openshift/api/operator/v1"
+	consolev1informers "github.com/openshift/client-go/console/informers/externalversions/console/v1"
+	consolev1listers "github.com/openshift/client-go/console/listers/console/v1"
+	operatorv1informers "github.com/openshift/client-go/operator/informers/externalversions/operator/v1"
+	operatorv1listers "github.com/openshift/client-go/operator/listers/operator/v1"
+	"github.com/openshift/library-go/pkg/controller/factory"
+	"github.com/openshift/library-go/pkg/operator/events"
+	"github.com/openshift/library-go/pkg/operator/resource/resourceapply"
+	"github.com/openshift/library-go/pkg/operator/v1helpers"
+
+	// console-operator
+	"github.com/openshift/console-operator/pkg/api"
+	"github.com/openshift/console-operator/pkg/console/controllers/util"
+	"github.com/openshift/console-operator/pkg/console/metrics"
+	"github.com/openshift/console-operator/pkg/console/status"
+	configmapsub "github.com/openshift/console-operator/pkg/console/subresource/configmap"
+	utilsub "github.com/openshift/console-operator/pkg/console/subresource/util"
+)
+
+// PluginState is the state of a plugin enabled in the operator config.
+type PluginState string
+
+const (
+	PluginStateEnabled               PluginState = "Enabled"
+	PluginStateMissingCR             PluginState = "MissingCR"
+	PluginStateBackendServiceMissing PluginState = "BackendServiceMissing"
+	PluginStateNoReadyEndpoints      PluginState = "NoReadyEndpoints"
+	PluginStateQuarantined           PluginState = "Quarantined"
+	PluginStateAwaitingProbe         PluginState = "AwaitingProbe"
+	// PluginStateUnknown is reported when the state of the plugin could not be read, the
+	// other plugins are still reported.
+	PluginStateUnknown PluginState = "Unknown"
+)
+
+const (
+	// a probed plugin is quarantined after this many failed probes in a row,
+	// and released again after as many successful ones.
+	quarantineAfterFailures = 3
+	releaseAfterSuccesses   = 3
+
+	pluginManifestFile = "plugin-manifest.json"
+)
+
+// probeResults counts the consecutive outcomes of the probes of a plugin backend.
+type probeResults struct {
+	failures  int
+	successes int
+}
+
+// ConsolePluginsController reports the state of the plugins enabled in the operator config.
+//
+// Plugins annotated with console.openshift.io/backend-probe=true additionally have their
+// plugin-manifest.json fetched from the backend on every sync. They are only added to
+// console-config after their first successful probe. Plugins that keep failing are
+// quarantined, which leaves them out of console-config until they recover.
+//
+//	writes:
+//	- consoles.operator.openshift.io/cluster .status.conditions:
+//		- type=PluginsDegraded
+//	- configmaps/console-plugin-quarantine in openshift-console
+//	- console_operator_plugin_state metric
+type ConsolePluginsController struct {
+	operatorClient       v1helpers.OperatorClient
+	operatorConfigLister operatorv1listers.ConsoleLister
+	consolePluginLister  consolev1listers.ConsolePluginLister
+	configMapLister      corev1listers.ConfigMapLister
+	configMapClient      corev1client.ConfigMapsGetter
+	serviceLister        corev1listers.ServiceLister
+	endpointSliceLister  discoveryv1listers.EndpointSliceLister
+	// probePlugin is swapped in tests, the backends are only reachable in cluster
+	probePlugin  func(ctx context.Context, plugin *consolev1.ConsolePlugin) error
+	probeResults map[string]*probeResults
+	// probeClient is shared by the probes and rebuilt when the service CA changes
+	probeClient   *http.Client
+	probeClientCA string
+	recorder      events.Recorder
+}
+
+func NewConsolePluginsController(
+	// clients
+	operatorClient v1helpers.OperatorClient,
+	configMapClient corev1client.ConfigMapsGetter,
+	// informers
+	operatorConfigInformer operatorv1informers.ConsoleInformer,
+	consolePluginInformer consolev1informers.ConsolePluginInformer,
+	coreInformer coreinformersv1.Interface,
+	// plugin backends, in any namespace
+	serviceInformer coreinformersv1.ServiceInformer,
+	endpointSliceInformer discoveryv1informers.EndpointSliceInformer,
+	// events
+	recorder events.Recorder,
+) factory.Controller {
+	ctrl := &ConsolePluginsController{
+		operatorClient:       operatorClient,
+		operatorConfigLister: operatorConfigInformer.Lister(),
+		consolePluginLister:  consolePluginInformer.Lister(),
+		configMapLister:      coreInformer.ConfigMaps().Lister(),
+		configMapClient:      configMapClient,
+		serviceLister:        serviceInformer.Lister(),
+		endpointSliceLister:  endpointSliceInformer.Lister(),
+		probeResults:         map[string]*probeResults{},
+		recorder:             recorder.WithComponentSuffix("console-plugins-controller"),
+	}
+	ctrl.probePlugin = ctrl.probePluginBackend
+
+	// plugin backends can live in any namespace, only the events of the services and
+	// endpoint slices of enabled plugins trigger a sync.
+	return factory.New().
+		WithFilteredEventsInformers(
+			util.IncludeNamesFilter(api.ConfigResourceName),
+			operatorConfigInformer.Informer(),
+		).
+		WithInformers(
+			consolePluginInformer.Informer(),
+		).
+		WithFilteredEventsInformers(
+			util.IncludeNamesFilter(api.ServiceCAConfigMapName, api.ConsolePluginQuarantineName),
+			coreInformer.ConfigMaps().Informer(),
+		).
+		WithFilteredEventsInformers(
+			ctrl.pluginBackendFilter(func(obj interface{}) (string, string) {
+				service, ok := obj.(*corev1.Service)
+				if !ok {
+					return "", ""
+				}
+				return service.Namespace, service.Name
+			}),
+			serviceInformer.Informer(),
+		).
+		WithFilteredEventsInformers(
+			ctrl.pluginBackendFilter(func(obj interface{}) (string, string) {
+				endpointSlice, ok := obj.(*discoveryv1.EndpointSlice)
+				if !ok {
+					return "", ""
+				}
+				return endpointSlice.Namespace, endpointSlice.Labels[discoveryv1.LabelServiceName]
+			}),
+			endpointSliceInformer.Informer(),
+		).
+		ResyncEvery(wait.Jitter(time.Minute, 1.0)).
+		WithSync(metrics.InstrumentSync("ConsolePluginsController", ctrl.Sync)).
+		ToController("ConsolePluginsController", ctrl.recorder)
+}
+
+func (c *ConsolePluginsController) Sync(ctx context.Context, controllerContext factory.SyncContext) error {
+	return util.HandleManagementState(ctx, c, c.operatorClient)
+}
+
+func (c *ConsolePluginsController) HandleUnmanaged(ctx context.Context) error {
+	klog.V(4).Info("Console is in unmanaged state, skipping console plugins sync.")
+	return nil
+}
+
+func (c *ConsolePluginsController) HandleRemoved(ctx context.Context) error {
+	klog.V(4).Info("Console is in removed state, resetting console plugins status.")
+	metrics.HandlePluginStates(nil)
+	statusHandler := status.NewStatusHandler(c.operatorClient)
+	statusHandler.AddCondition(status.HandleDegraded("Plugins", "", nil))
+	return statusHandler.FlushAndReturn(nil)
+}
+
+func (c *ConsolePluginsController) HandleManaged(ctx context.Context) error {
+	statusHandler := status.NewStatusHandler(c.operatorClient)
+
+	operatorConfig, err := c.operatorConfigLister.Get(api.ConfigResourceName)
+	if err != nil {
+		return statusHandler.FlushAndReturn(err)
+	}
+
+	states := c.GetPluginStates(operatorConfig.Spec.Plugins)
+
+	quarantineErr := c.syncQuarantine(ctx, operatorConfig, states)
+	statusHandler.AddConditions(status.HandleProgressingOrDegraded("PluginQuarantineSync", "FailedApply", quarantineErr))
+	if quarantineErr != nil {
+		return statusHandler.FlushAndReturn(quarantineErr)
+	}
+
+	metricStates := map[string]string{}
+	for pluginName, state := range states {
+		metricStates[pluginName] = string(state)
+	}
+	metrics.HandlePluginStates(metricStates)
+
+	statusHandler.AddCondition(status.HandleDegraded("Plugins", "PluginsUnavailable", unavailablePluginsError(states)))
+	return statusHandler.FlushAndReturn(nil)
+}
+
+// syncQuarantine probes the backend of every available plugin that opted in, updates
+// the quarantine config map accordingly and marks quarantined plugins, and the ones that
+// never passed a probe, in states.
+func (c *ConsolePluginsController) syncQuarantine(ctx context.Context, operatorConfig *operatorv1.Console, states map[string]PluginState) error {
+	quarantined, probed, err := c.getQuarantined()
+	if err != nil {
+		return err
+	}
+	nextQuarantined := map[string]string{}
+	nextProbed := sets.NewString()
+
+	for pluginName, state := range states {
+		// keep the quarantine of a plugin whose state is unknown as it is
+		if state == PluginStateUnknown {
+			if reason, ok := quarantined[pluginName]; ok {
+				nextQuarantined[pluginName] = reason
+			}
+			if probed.Has(pluginName) {
+				nextProbed.Insert(pluginName)
+			}
+			continue
+		}
+		if state != PluginStateEnabled {
+			continue
+		}
+		plugin, err := c.consolePluginLister.Get(pluginName)
+		if err != nil {
+			return err
+		}
+		if plugin.Annotations[api.PluginBackendProbeAnnotation] != "true" {
+			delete(c.probeResults, pluginName)
+			continue
+		}
+
+		probeErr := c.probePlugin(ctx, plugin)
+		reason, isQuarantined := c.updateProbeResults(pluginName, probeErr, quarantined)
+		if isQuarantined {
+			nextQuarantined[pluginName] = reason
+			states[pluginName] = PluginStateQuarantined
+		}
+		if probeErr == nil || probed.Has(pluginName) {
+			nextProbed.Insert(pluginName)
+		} else if !isQuarantined {
+			states[pluginName] = PluginStateAwaitingProbe
+		}
+	}
+
+	// forget about plugins that were disabled or opted out
+	for pluginName := range c.probeResults {
+		if _, ok := states[pluginName]; !ok {
+			delete(c.probeResults, pluginName)
+		}
+	}
+
+	if equality.Semantic.DeepEqual(quarantined, nextQuarantined) && probed.Equal(nextProbed) {
+		return nil
+	}
+	_, _, err = resourceapply.ApplyConfigMap(ctx, c.configMapClient, c.recorder, configmapsub.DefaultPluginQuarantineConfigMap(operatorConfig, nextQuarantined, nextProbed))
+	return err
+}
+
+// updateProbeResults records the outcome of a probe and returns whether the plugin
+// is quarantined, along with the reason for it.
+func (c *ConsolePluginsController) updateProbeResults(pluginName string, probeErr error, quarantined map[string]string) (string, bool) {
+	results, ok := c.probeResults[pluginName]
+	if !ok {
+		results = &probeResults{}
+		c.probeResults[pluginName] = results
+	}
+
+	reason, wasQuarantined := quarantined[pluginName]
+	if probeErr != nil {
+		klog.V(4).Infof("probe of %q plugin failed: %v", pluginName, probeErr)
+		results.failures++
+		results.successes = 0
+		if wasQuarantined {
+			return reason, true
+		}
+		if results.failures >= quarantineAfterFailures {
+			return probeErr.Error(), true
+		}
+		return "", false
+	}
+
+	results.successes++
+	results.failures = 0
+	if wasQuarantined && results.successes < releaseAfterSuccesses {
+		return reason, true
+	}
+	return "", false
+}
+
+// getQuarantined returns the quarantined plugins along with the reason for it, and the
+// probed plugins that passed a probe at least once.
+func (c *ConsolePluginsController) getQuarantined() (map[string]string, sets.String, error) {
+	configMap, err := c.configMapLister.ConfigMaps(api.TargetNamespace).Get(api.ConsolePluginQuarantineName)
+	if apierrors.IsNotFound(err) {
+		return map[string]string{}, sets.NewString(), nil
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	quarantined := map[string]string{}
+	for pluginName, reason := range configMap.Data {
+		quarantined[pluginName] = reason
+	}
+	return quarantined, configmapsub.GetProbedPlugins(configMap), nil
+}
+
+// probePluginBackend fetches the plugin manifest from the plugin service, trusting the service CA.
+func (c *ConsolePluginsController) probePluginBackend(ctx context.Context, plugin *consolev1.ConsolePlugin) error {
+	serviceCAConfigMap, err := c.configMapLister.ConfigMaps(api.TargetNamespace).Get(api.ServiceCAConfigMapName)
+	if err != nil {
+		return fmt.Errorf("failed to get service CA: %w", err)
+	}
+	client, err := c.getProbeClient(serviceCAConfigMap.Data["service-ca.crt"])
+	if err != nil {
+		return err
+	}
+
+	manifestURL := strings.TrimSuffix(configmapsub.PluginServiceURL(&plugin.Spec.Backend), "/") + "/" + pluginManifestFile
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, manifestURL, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to GET %s: %w", manifestURL, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("GET %s returned %s", manifestURL, resp.Status)
+	}
+	return nil
+}
+
+// getProbeClient returns the client the probes share, trusting the given service CA. The
+// connections of the previous client are closed when the CA changes.
+func (c *ConsolePluginsController) getProbeClient(serviceCA string) (*http.Client, error) {
+	if c.probeClient != nil && c.probeClientCA == serviceCA {
+		return c.probeClient, nil
+	}
+	caPool := x509.NewCertPool()
+	if ok := caPool.AppendCertsFromPEM([]byte(serviceCA)); !ok {
+		return nil, fmt.Errorf("failed to parse service CA")
+	}
+	if c.probeClient != nil {
+		c.probeClient.CloseIdleConnections()
+	}
+	c.probeClient = &http.Client{
+		Timeout: 5 * time.Second,
+		Transport: &http.Transport{
+			TLSClientConfig: &tls.Config{
+				RootCAs: caPool,
+			},
+		},
+	}
+	c.probeClientCA = serviceCA
+	return c.probeClient, nil
+}
+
+// GetPluginStates returns the state of each enabled plugin, keyed by plugin name. A plugin
+// whose state could not be read is reported as Unknown.
+func (c *ConsolePluginsController) GetPluginStates(enabledPluginsNames []string) map[string]PluginState {
+	states := map[string]PluginState{}
+	for _, pluginName := range utilsub.RemoveDuplicateStr(enabledPluginsNames) {
+		state, err := c.getPluginState(pluginName)
+		if err != nil {
+			klog.Errorf("failed to get the state of the %q plugin: %v", pluginName, err)
+			state = PluginStateUnknown
+		}
+		states[pluginName] = state
+	}
+	return states
+}
+
+func (c *ConsolePluginsController) getPluginState(pluginName string) (PluginState, error) {
+	plugin, err := c.consolePluginLister.Get(pluginName)
+	if apierrors.IsNotFound(err) {
+		return PluginStateMissingCR, nil
+	}
+	if err != nil {
+		return "", err
+	}
+
+	if plugin.Spec.Backend.Type != consolev1.Service {
+		return PluginStateEnabled, nil
+	}
+	backend := plugin.Spec.Backend.Service
+	if backend == nil {
+		return PluginStateBackendServiceMissing, nil
+	}
+
+	_, err = c.serviceLister.Services(backend.Namespace).Get(backend.Name)
+	if apierrors.IsNotFound(err) {
+		return PluginStateBackendServiceMissing, nil
+	}
+	if err != nil {
+		return "", err
+	}
+
+	endpointSlices, err := c.endpointSliceLister.EndpointSlices(backend.Namespace).List(
+		labels.SelectorFromSet(labels.Set{discoveryv1.LabelServiceName: backend.Name}),
+	)
+	if err != nil {
+		return "", err
+	}
+	for _, endpointSlice := range endpointSlices {
+		for _, endpoint := range endpointSlice.Endpoints {
+			// a nil ready condition is to be interpreted as ready
+			if endpoint.Conditions.Ready == nil || *endpoint.Conditions.Ready {
+				return PluginStateEnabled, nil
+			}
+		}
+	}
+	return PluginStateNoReadyEndpoints, nil
+}
+
+// pluginBackendFilter only lets through the events of the objects of a plugin backend
+// service, the namespace and service name of an object are given by serviceOf.
+func (c *ConsolePluginsController) pluginBackendFilter(serviceOf func(obj interface{}) (string, string)) factory.EventFilterFunc {
+	return func(obj interface{}) bool {
+		if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+			obj = tombstone.Obj
+		}
+		namespace, name := serviceOf(obj)
+		if len(name) == 0 {
+			return false
+		}
+		plugins, err := c.consolePluginLister.List(labels.Everything())
+		if err != nil {
+			return true
+		}
+		for _, plugin := range plugins {
+			backend := plugin.Spec.Backend.Service
+			if backend != nil && backend.Namespace == namespace && backend.Name == name {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+func unavailablePluginsError(states map[string]PluginState) error {
+	unavailable := []string{}
+	for pluginName, state := range states {
+		if state != PluginStateEnabled {
+			unavailable = append(unavailable, fmt.Sprintf("%s (%s)", pluginName, state))
+		}
+	}
+	if len(unavailable) == 0 {
+		return nil
+	}
+	sort.Strings(unavailable)
+	return fmt.Errorf("plugins not available: %s", strings.Join(unavailable, ", "))
+}
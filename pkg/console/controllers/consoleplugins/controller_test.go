@@ -0,0 +1,292 @@
+package consoleplugins
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/go-test/deep"
+
+	corev1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	corev1listers "k8s.io/client-go/listers/core/v1"
+	discoveryv1listers "k8s.io/client-go/listers/discovery/v1"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/utils/ptr"
+
+	consolev1 "github.com/openshift/api/console/v1"
+	operatorv1 "github.com/openshift/api/operator/v1"
+	consolev1listers "github.com/openshift/client-go/console/listers/console/v1"
+	"github.com/openshift/library-go/pkg/crypto"
+	"github.com/openshift/library-go/pkg/operator/events"
+
+	"github.com/openshift/console-operator/pkg/api"
+)
+
+func testPlugin(name string) *consolev1.ConsolePlugin {
+	return &consolev1.ConsolePlugin{
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		Spec: consolev1.ConsolePluginSpec{
+			Backend: consolev1.ConsolePluginBackend{
+				Type: consolev1.Service,
+				Service: &consolev1.ConsolePluginService{
+					Name:      name,
+					Namespace: "plugins",
+					Port:      9443,
+				},
+			},
+		},
+	}
+}
+
+func testService(name string) *corev1.Service {
+	return &corev1.Service{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "plugins"}}
+}
+
+func testEndpointSlice(serviceName string, ready bool) *discoveryv1.EndpointSlice {
+	return &discoveryv1.EndpointSlice{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      serviceName + "-abcde",
+			Namespace: "plugins",
+			Labels:    map[string]string{discoveryv1.LabelServiceName: serviceName},
+		},
+		Endpoints: []discoveryv1.Endpoint{{
+			Addresses:  []string{"10.0.0.1"},
+			Conditions: discoveryv1.EndpointConditions{Ready: ptr.To(ready)},
+		}},
+	}
+}
+
+func TestGetPluginStates(t *testing.T) {
+	tests := []struct {
+		name    string
+		enabled []string
+		plugins []*consolev1.ConsolePlugin
+		objects []runtime.Object
+		want    map[string]PluginState
+	}{
+		{
+			name:    "Plugin with ready endpoints is enabled",
+			enabled: []string{"plugin-a"},
+			plugins: []*consolev1.ConsolePlugin{testPlugin("plugin-a")},
+			objects: []runtime.Object{testService("plugin-a"), testEndpointSlice("plugin-a", true)},
+			want:    map[string]PluginState{"plugin-a": PluginStateEnabled},
+		},
+		{
+			name:    "Plugin without a ConsolePlugin is reported as missing",
+			enabled: []string{"plugin-a", "plugin-a"},
+			want:    map[string]PluginState{"plugin-a": PluginStateMissingCR},
+		},
+		{
+			name:    "Plugin without its backend service is reported",
+			enabled: []string{"plugin-a"},
+			plugins: []*consolev1.ConsolePlugin{testPlugin("plugin-a")},
+			want:    map[string]PluginState{"plugin-a": PluginStateBackendServiceMissing},
+		},
+		{
+			name:    "Plugin without ready endpoints is reported",
+			enabled: []string{"plugin-a", "plugin-b"},
+			plugins: []*consolev1.ConsolePlugin{testPlugin("plugin-a"), testPlugin("plugin-b")},
+			objects: []runtime.Object{
+				testService("plugin-a"), testEndpointSlice("plugin-a", false),
+				testService("plugin-b"),
+			},
+			want: map[string]PluginState{
+				"plugin-a": PluginStateNoReadyEndpoints,
+				"plugin-b": PluginStateNoReadyEndpoints,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := newStatesController(t, tt.plugins, tt.objects)
+			if diff := deep.Equal(c.GetPluginStates(tt.enabled), tt.want); diff != nil {
+				t.Error(diff)
+			}
+		})
+	}
+}
+
+// failingServiceLister fails to read the services of a namespace.
+type failingServiceLister struct {
+	corev1listers.ServiceLister
+	namespace string
+}
+
+func (l failingServiceLister) Services(namespace string) corev1listers.ServiceNamespaceLister {
+	if namespace == l.namespace {
+		return failingServiceNamespaceLister{}
+	}
+	return l.ServiceLister.Services(namespace)
+}
+
+type failingServiceNamespaceLister struct {
+	corev1listers.ServiceNamespaceLister
+}
+
+func (failingServiceNamespaceLister) Get(name string) (*corev1.Service, error) {
+	return nil, fmt.Errorf("failed to get service %q", name)
+}
+
+func TestGetPluginStatesUnknown(t *testing.T) {
+	pluginB := testPlugin("plugin-b")
+	pluginB.Spec.Backend.Service.Namespace = "other"
+	c := newStatesController(t,
+		[]*consolev1.ConsolePlugin{testPlugin("plugin-a"), pluginB},
+		[]runtime.Object{testService("plugin-a"), testEndpointSlice("plugin-a", true)},
+	)
+	c.serviceLister = failingServiceLister{ServiceLister: c.serviceLister, namespace: "other"}
+
+	want := map[string]PluginState{
+		"plugin-a": PluginStateEnabled,
+		"plugin-b": PluginStateUnknown,
+	}
+	if diff := deep.Equal(c.GetPluginStates([]string{"plugin-a", "plugin-b"}), want); diff != nil {
+		t.Error(diff)
+	}
+}
+
+func newStatesController(t *testing.T, plugins []*consolev1.ConsolePlugin, objects []runtime.Object) *ConsolePluginsController {
+	t.Helper()
+	pluginIndexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+	for _, plugin := range plugins {
+		if err := pluginIndexer.Add(plugin); err != nil {
+			t.Fatal(err)
+		}
+	}
+	serviceIndexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
+	endpointSliceIndexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
+	for _, obj := range objects {
+		indexer := serviceIndexer
+		if _, ok := obj.(*discoveryv1.EndpointSlice); ok {
+			indexer = endpointSliceIndexer
+		}
+		if err := indexer.Add(obj); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return &ConsolePluginsController{
+		consolePluginLister: consolev1listers.NewConsolePluginLister(pluginIndexer),
+		serviceLister:       corev1listers.NewServiceLister(serviceIndexer),
+		endpointSliceLister: discoveryv1listers.NewEndpointSliceLister(endpointSliceIndexer),
+	}
+}
+
+func TestUnavailablePluginsError(t *testing.T) {
+	err := unavailablePluginsError(map[string]PluginState{
+		"plugin-c": PluginStateEnabled,
+		"plugin-b": PluginStateNoReadyEndpoints,
+		"plugin-a": PluginStateMissingCR,
+	})
+	want := "plugins not available: plugin-a (MissingCR), plugin-b (NoReadyEndpoints)"
+	if err == nil || err.Error() != want {
+		t.Errorf("expected %q, got %v", want, err)
+	}
+	if err := unavailablePluginsError(map[string]PluginState{"plugin-a": PluginStateEnabled}); err != nil {
+		t.Errorf("expected no error, got %v", err)
+	}
+}
+
+func TestSyncQuarantine(t *testing.T) {
+	probeErr := fmt.Errorf("connection refused")
+	probes := []struct {
+		err             error
+		wantQuarantined bool
+		wantProbed      bool
+	}{
+		{probeErr, false, false},
+		{probeErr, false, false},
+		{probeErr, true, false},
+		{nil, true, true},
+		{probeErr, true, true},
+		{nil, true, true},
+		{nil, true, true},
+		{nil, false, true},
+		{probeErr, false, true},
+	}
+
+	plugin := testPlugin("plugin-a")
+	plugin.Annotations = map[string]string{api.PluginBackendProbeAnnotation: "true"}
+	pluginIndexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+	if err := pluginIndexer.Add(plugin); err != nil {
+		t.Fatal(err)
+	}
+	configMapIndexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
+	kubeClient := fake.NewSimpleClientset()
+	c := &ConsolePluginsController{
+		consolePluginLister: consolev1listers.NewConsolePluginLister(pluginIndexer),
+		configMapLister:     corev1listers.NewConfigMapLister(configMapIndexer),
+		configMapClient:     kubeClient.CoreV1(),
+		probeResults:        map[string]*probeResults{},
+		recorder:            events.NewInMemoryRecorder("test"),
+	}
+
+	for i, probe := range probes {
+		c.probePlugin = func(ctx context.Context, plugin *consolev1.ConsolePlugin) error {
+			return probe.err
+		}
+		states := map[string]PluginState{"plugin-a": PluginStateEnabled}
+		if err := c.syncQuarantine(context.TODO(), &operatorv1.Console{}, states); err != nil {
+			t.Fatal(err)
+		}
+		// feed the applied config map back to the lister, as the informer would
+		if configMap, err := kubeClient.CoreV1().ConfigMaps(api.TargetNamespace).Get(context.TODO(), api.ConsolePluginQuarantineName, metav1.GetOptions{}); err == nil {
+			if err := configMapIndexer.Update(configMap); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		quarantined, probed, err := c.getQuarantined()
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, isQuarantined := quarantined["plugin-a"]
+		if isQuarantined != probe.wantQuarantined {
+			t.Errorf("probe %d: expected quarantined to be %v", i, probe.wantQuarantined)
+		}
+		if isQuarantined != (states["plugin-a"] == PluginStateQuarantined) {
+			t.Errorf("probe %d: state %q does not match the quarantine", i, states["plugin-a"])
+		}
+		// a plugin is held back until its first successful probe
+		if probed.Has("plugin-a") != probe.wantProbed {
+			t.Errorf("probe %d: expected probed to be %v", i, probe.wantProbed)
+		}
+		if !isQuarantined && !probe.wantProbed && states["plugin-a"] != PluginStateAwaitingProbe {
+			t.Errorf("probe %d: expected state %q, got %q", i, PluginStateAwaitingProbe, states["plugin-a"])
+		}
+	}
+}
+
+func TestGetProbeClient(t *testing.T) {
+	c := &ConsolePluginsController{}
+	if _, err := c.getProbeClient("not a certificate"); err == nil {
+		t.Error("expected an error for an invalid service CA")
+	}
+
+	serviceCA, otherServiceCA := testCA(t), testCA(t)
+	client, err := c.getProbeClient(serviceCA)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sameClient, _ := c.getProbeClient(serviceCA); sameClient != client {
+		t.Error("expected the probe client to be reused for the same service CA")
+	}
+	if newClient, _ := c.getProbeClient(otherServiceCA); newClient == client {
+		t.Error("expected a new probe client for a new service CA")
+	}
+}
+
+func testCA(t *testing.T) string {
+	ca, err := crypto.MakeSelfSignedCAConfig("service-ca", 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	certPEM, _, err := ca.GetPEMBytes()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(certPEM)
+}
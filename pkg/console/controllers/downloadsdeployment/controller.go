@@ -6,10 +6,16 @@ import (
 	"time"
 
 	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
 	appsinformersv1 "k8s.io/client-go/informers/apps/v1"
+	coreinformersv1 "k8s.io/client-go/informers/core/v1"
+	schedulinginformersv1 "k8s.io/client-go/informers/scheduling/v1"
 	appsclientv1 "k8s.io/client-go/kubernetes/typed/apps/v1"
+	corev1listers "k8s.io/client-go/listers/core/v1"
+	schedulingv1listers "k8s.io/client-go/listers/scheduling/v1"
 	"k8s.io/klog/v2"
 
 	configv1 "github.com/openshift/api/config/v1"
@@ -19,6 +25,7 @@ import (
 	operatorinformerv1 "github.com/openshift/client-go/operator/informers/externalversions/operator/v1"
 	operatorlistersv1 "github.com/openshift/client-go/operator/listers/operator/v1"
 	"github.com/openshift/console-operator/pkg/api"
+	"github.com/openshift/console-operator/pkg/console/metrics"
 	"github.com/openshift/console-operator/pkg/console/status"
 	"github.com/openshift/library-go/pkg/controller/factory"
 	"github.com/openshift/library-go/pkg/operator/events"
@@ -37,6 +44,12 @@ type DownloadsDeploymentSyncController struct {
 	infrastructureLister  configlistersv1.InfrastructureLister
 	// core kube
 	deploymentClient appsclientv1.DeploymentsGetter
+	configMapLister  corev1listers.ConfigMapLister
+	nodeLister       corev1listers.NodeLister
+	// priority classes, for the placement
+	priorityClassLister schedulingv1listers.PriorityClassLister
+	// openshift-config config maps, for the placement
+	configNSConfigMapLister corev1listers.ConfigMapLister
 }
 
 func NewDownloadsDeploymentSyncController(
@@ -48,6 +61,10 @@ func NewDownloadsDeploymentSyncController(
 	// core kube
 	deploymentClient appsclientv1.DeploymentsGetter,
 	deploymentInformer appsinformersv1.DeploymentInformer,
+	configMapInformer coreinformersv1.ConfigMapInformer,
+	nodeInformer coreinformersv1.NodeInformer,
+	priorityClassInformer schedulinginformersv1.PriorityClassInformer,
+	configNSConfigMapInformer coreinformersv1.ConfigMapInformer,
 	// events
 	recorder events.Recorder,
 ) factory.Controller {
@@ -60,6 +77,11 @@ func NewDownloadsDeploymentSyncController(
 		infrastructureLister:  configInformer.Config().V1().Infrastructures().Lister(),
 		// client
 		deploymentClient: deploymentClient,
+		configMapLister:  configMapInformer.Lister(),
+		nodeLister:       nodeInformer.Lister(),
+
+		priorityClassLister:     priorityClassInformer.Lister(),
+		configNSConfigMapLister: configNSConfigMapInformer.Lister(),
 	}
 
 	configNameFilter := util.IncludeNamesFilter(api.ConfigResourceName)
@@ -73,7 +95,18 @@ func NewDownloadsDeploymentSyncController(
 		).WithFilteredEventsInformers( // downloads deployment
 		downloadsNameFilter,
 		deploymentInformer.Informer(),
-	).ResyncEvery(time.Minute).WithSync(ctrl.Sync).
+	).WithFilteredEventsInformers( // auto-sized requests
+		util.IncludeNamesFilter(api.ResourceSizingConfigMapName),
+		configMapInformer.Informer(),
+	).WithFilteredEventsInformers( // placement config map
+		util.IncludeReferencedNamesFilter(func() []string {
+			return deploymentsub.GetPlacementConfigMapNames(ctrl.consoleOperatorLister)
+		}),
+		configNSConfigMapInformer.Informer(),
+	).WithInformers( // node zones and placement priority classes
+		nodeInformer.Informer(),
+		priorityClassInformer.Informer(),
+	).ResyncEvery(time.Minute).WithSync(metrics.InstrumentSync("ConsoleDownloadsDeploymentSyncController", ctrl.Sync)).
 		ToController("ConsoleDownloadsDeploymentSyncController", recorder.WithComponentSuffix("console-downloads-deployment-controller"))
 }
 
@@ -104,7 +137,20 @@ func (c *DownloadsDeploymentSyncController) Sync(ctx context.Context, controller
 		return statusHandler.FlushAndReturn(err)
 	}
 
-	actualDownloadsDownloadsDeployment, _, downloadsDeploymentErr := c.SyncDownloadsDeployment(ctx, operatorConfigCopy, infrastructureConfig, controllerContext)
+	resourceSizingConfigMap, err := c.configMapLister.ConfigMaps(api.OpenShiftConsoleNamespace).Get(api.ResourceSizingConfigMapName)
+	if err != nil && !apierrors.IsNotFound(err) {
+		return statusHandler.FlushAndReturn(err)
+	}
+
+	nodes, err := c.nodeLister.List(labels.Everything())
+	if err != nil {
+		return statusHandler.FlushAndReturn(err)
+	}
+	// the downloads keep their default placement until the custom one is valid and schedulable
+	placement, placementReason, placementErr := deploymentsub.GetSchedulablePlacement(operatorConfigCopy, c.configNSConfigMapLister, c.priorityClassLister, api.OpenShiftConsoleDownloadsDeploymentName, infrastructureConfig, nodes)
+	statusHandler.AddCondition(status.HandleDegraded("DownloadsPlacement", placementReason, placementErr))
+
+	actualDownloadsDownloadsDeployment, _, downloadsDeploymentErr := c.SyncDownloadsDeployment(ctx, operatorConfigCopy, infrastructureConfig, resourceSizingConfigMap, nodes, placement, controllerContext)
 	statusHandler.AddConditions(status.HandleProgressingOrDegraded("DownloadsDeploymentSync", "FailedApply", downloadsDeploymentErr))
 	if downloadsDeploymentErr != nil {
 		return statusHandler.FlushAndReturn(downloadsDeploymentErr)
@@ -114,9 +160,9 @@ func (c *DownloadsDeploymentSyncController) Sync(ctx context.Context, controller
 	return statusHandler.FlushAndReturn(nil)
 }
 
-func (c *DownloadsDeploymentSyncController) SyncDownloadsDeployment(ctx context.Context, operatorConfigCopy *operatorv1.Console, infrastructureConfig *configv1.Infrastructure, controllerContext factory.SyncContext) (*appsv1.Deployment, bool, error) {
+func (c *DownloadsDeploymentSyncController) SyncDownloadsDeployment(ctx context.Context, operatorConfigCopy *operatorv1.Console, infrastructureConfig *configv1.Infrastructure, resourceSizingConfigMap *corev1.ConfigMap, nodes []*corev1.Node, placement *deploymentsub.Placement, controllerContext factory.SyncContext) (*appsv1.Deployment, bool, error) {
 
-	requiredDownloadsDeployment := deploymentsub.DefaultDownloadsDeployment(operatorConfigCopy, infrastructureConfig)
+	requiredDownloadsDeployment := deploymentsub.DefaultDownloadsDeployment(operatorConfigCopy, infrastructureConfig, resourceSizingConfigMap, nodes, placement)
 
 	return resourceapply.ApplyDeployment(ctx,
 		c.deploymentClient,
@@ -7,13 +7,17 @@ import (
 	"fmt"
 	"net/http"
 	"net/url"
+	"strings"
 	"time"
 
 	// k8s
-
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/dynamic"
 	coreinformersv1 "k8s.io/client-go/informers/core/v1"
+	networkinginformersv1 "k8s.io/client-go/informers/networking/v1"
 	corev1listers "k8s.io/client-go/listers/core/v1"
+	networkingv1listers "k8s.io/client-go/listers/networking/v1"
 	"k8s.io/client-go/util/retry"
 	"k8s.io/klog/v2"
 
@@ -36,7 +40,11 @@ import (
 	// console-operator
 	"github.com/openshift/console-operator/pkg/api"
 	"github.com/openshift/console-operator/pkg/console/controllers/util"
+	"github.com/openshift/console-operator/pkg/console/metrics"
 	"github.com/openshift/console-operator/pkg/console/status"
+	deploymentsub "github.com/openshift/console-operator/pkg/console/subresource/deployment"
+	httproutesub "github.com/openshift/console-operator/pkg/console/subresource/httproute"
+	ingresssub "github.com/openshift/console-operator/pkg/console/subresource/ingress"
 	routesub "github.com/openshift/console-operator/pkg/console/subresource/route"
 )
 
@@ -46,8 +54,10 @@ type HealthCheckController struct {
 	infrastructureConfigLister configlistersv1.InfrastructureLister
 	configMapLister            corev1listers.ConfigMapLister
 	routeLister                routev1listers.RouteLister
+	ingressLister              networkingv1listers.IngressLister
 	ingressConfigLister        configlistersv1.IngressLister
 	operatorConfigLister       operatorv1listers.ConsoleLister
+	dynamicClient              dynamic.Interface
 }
 
 func NewHealthCheckController(
@@ -55,11 +65,13 @@ func NewHealthCheckController(
 	configClient configclientv1.ConfigV1Interface,
 	// clients
 	operatorClient v1helpers.OperatorClient,
+	dynamicClient dynamic.Interface,
 	// informers
 	operatorConfigInformer v1.ConsoleInformer,
 	configInformer configinformer.SharedInformerFactory,
 	coreInformer coreinformersv1.Interface,
 	routeInformer routesinformersv1.RouteInformer,
+	ingressInformer networkinginformersv1.IngressInformer,
 	// events
 	recorder events.Recorder,
 ) factory.Controller {
@@ -69,7 +81,9 @@ func NewHealthCheckController(
 		infrastructureConfigLister: configInformer.Config().V1().Infrastructures().Lister(),
 		ingressConfigLister:        configInformer.Config().V1().Ingresses().Lister(),
 		routeLister:                routeInformer.Lister(),
+		ingressLister:              ingressInformer.Lister(),
 		configMapLister:            coreInformer.ConfigMaps().Lister(),
+		dynamicClient:              dynamicClient,
 	}
 
 	configMapInformer := coreInformer.ConfigMaps()
@@ -84,9 +98,10 @@ func NewHealthCheckController(
 		util.IncludeNamesFilter(api.TrustedCAConfigMapName, api.OAuthServingCertConfigMapName),
 		configMapInformer.Informer(),
 	).WithFilteredEventsInformers( // route
-		util.IncludeNamesFilter(api.OpenShiftConsoleRouteName, api.OpenshiftConsoleCustomRouteName),
+		util.IncludeNamesOrPrefixesFilter([]string{api.OpenShiftConsoleRouteName, api.OpenshiftConsoleCustomRouteName}, routesub.HostnameAliasRouteNamePrefix),
 		routeInformer.Informer(),
-	).ResyncEvery(30*time.Second).WithSync(ctrl.Sync).
+		ingressInformer.Informer(),
+	).ResyncEvery(30*time.Second).WithSync(metrics.InstrumentSync("HealthCheckController", ctrl.Sync)).
 		ToController("HealthCheckController", recorder.WithComponentSuffix("health-check-controller"))
 }
 
@@ -135,24 +150,37 @@ func (c *HealthCheckController) Sync(ctx context.Context, controllerContext fact
 		activeRouteName = api.OpenshiftConsoleCustomRouteName
 	}
 
-	activeRoute, activeRouteErr := c.routeLister.Routes(api.OpenShiftConsoleNamespace).Get(activeRouteName)
-	statusHandler.AddConditions(status.HandleProgressingOrDegraded("RouteHealth", "FailedRouteGet", activeRouteErr))
-	if activeRouteErr != nil {
-		klog.V(4).Infof("failed getting %q route for performing health check: %v", activeRouteName, activeRouteErr)
-		return statusHandler.FlushAndReturn(activeRouteErr)
+	var activeRoute *routev1.Route
+	if !httproutesub.IsGatewayEnabled(updatedOperatorConfig) && !ingresssub.IsIngressEnabled(updatedOperatorConfig) {
+		var activeRouteErr error
+		activeRoute, activeRouteErr = c.routeLister.Routes(api.OpenShiftConsoleNamespace).Get(activeRouteName)
+		statusHandler.AddConditions(status.HandleProgressingOrDegraded("RouteHealth", "FailedRouteGet", activeRouteErr))
+		if activeRouteErr != nil {
+			klog.V(4).Infof("failed getting %q route for performing health check: %v", activeRouteName, activeRouteErr)
+			return statusHandler.FlushAndReturn(activeRouteErr)
+		}
 	}
 
-	routeHealthCheckErrReason, routeHealthCheckErr := c.CheckRouteHealth(ctx, updatedOperatorConfig, activeRoute)
+	routeHealthCheckErrReason, routeHealthCheckErr := c.CheckRouteHealth(ctx, updatedOperatorConfig, ingressConfig, activeRoute)
 	if routeHealthCheckErr != nil {
 		klog.V(4).Infof("failed to performing health check: %v", routeHealthCheckErr)
 	}
 	statusHandler.AddCondition(status.HandleDegraded("RouteHealth", routeHealthCheckErrReason, routeHealthCheckErr))
 	statusHandler.AddCondition(status.HandleAvailable("RouteHealth", routeHealthCheckErrReason, routeHealthCheckErr))
 
+	// hostname aliases are only served by routes
+	if activeRoute != nil {
+		aliasHealthCheckErrReason, aliasHealthCheckErr := c.CheckHostnameAliasHealth(ctx, updatedOperatorConfig)
+		statusHandler.AddCondition(status.HandleDegraded("HostnameAliasHealth", aliasHealthCheckErrReason, aliasHealthCheckErr))
+	}
+
 	return statusHandler.FlushAndReturn(routeHealthCheckErr)
 }
 
-func (c *HealthCheckController) CheckRouteHealth(ctx context.Context, operatorConfig *operatorsv1.Console, route *routev1.Route) (string, error) {
+// CheckRouteHealth checks that the console answers on its URL. The route is nil when the
+// console is exposed through a Gateway or Ingresses, the URL is then read from the
+// HTTPRoute or the Ingress.
+func (c *HealthCheckController) CheckRouteHealth(ctx context.Context, operatorConfig *operatorsv1.Console, ingressConfig *configv1.Ingress, route *routev1.Route) (string, error) {
 	var reason string
 	healthCheckBackoff := wait.Backoff{
 		Steps:    10,
@@ -168,7 +196,7 @@ func (c *HealthCheckController) CheckRouteHealth(ctx context.Context, operatorCo
 				url *url.URL
 				err error
 			)
-			if len(operatorConfig.Spec.Ingress.ConsoleURL) == 0 {
+			if len(operatorConfig.Spec.Ingress.ConsoleURL) == 0 && route != nil {
 				url, _, err = routeapihelpers.IngressURI(route, route.Spec.Host)
 				if err != nil {
 					reason = "RouteNotAdmitted"
@@ -176,6 +204,23 @@ func (c *HealthCheckController) CheckRouteHealth(ctx context.Context, operatorCo
 					logHealthCheckError(errStr)
 					return fmt.Errorf(errStr)
 				}
+			} else if len(operatorConfig.Spec.Ingress.ConsoleURL) == 0 && ingresssub.IsIngressEnabled(operatorConfig) {
+				_, url, _, err = ingresssub.GetActiveIngressInfo(c.ingressLister, activeConsoleRouteName(operatorConfig, ingressConfig))
+				if err != nil {
+					reason = "IngressNotAdmitted"
+					errStr := fmt.Sprintf("failed to get console url: %v", err)
+					logHealthCheckError(errStr)
+					return fmt.Errorf(errStr)
+				}
+			} else if len(operatorConfig.Spec.Ingress.ConsoleURL) == 0 {
+				// without a route the console is exposed through a Gateway
+				_, url, _, err = httproutesub.GetActiveHTTPRouteInfo(ctx, c.dynamicClient, activeConsoleRouteName(operatorConfig, ingressConfig))
+				if err != nil {
+					reason = "HTTPRouteNotAccepted"
+					errStr := fmt.Sprintf("failed to get console url: %v", err)
+					logHealthCheckError(errStr)
+					return fmt.Errorf(errStr)
+				}
 			} else {
 				url, err = url.Parse(operatorConfig.Spec.Ingress.ConsoleURL)
 				if err != nil {
@@ -186,44 +231,99 @@ func (c *HealthCheckController) CheckRouteHealth(ctx context.Context, operatorCo
 				}
 			}
 
-			caPool, err := c.getCA(ctx, route.Spec.TLS)
-			if err != nil {
-				reason = "FailedLoadCA"
-				errStr := fmt.Sprintf("failed to read CA to check route health: %v", err)
-				logHealthCheckError(errStr)
-				return fmt.Errorf(errStr)
+			var routeTLS *routev1.TLSConfig
+			if route != nil {
+				routeTLS = route.Spec.TLS
 			}
-			client := clientWithCA(caPool)
-
-			req, err := http.NewRequest(http.MethodGet, url.String(), nil)
-			if err != nil {
-				reason = "FailedRequest"
-				errStr := fmt.Sprintf("failed to build request to route (%s): %v", url, err)
-				logHealthCheckError(errStr)
-				return fmt.Errorf(errStr)
-			}
-			resp, err := client.Do(req)
-			if err != nil {
-				reason = "FailedGet"
-				errStr := fmt.Sprintf("failed to GET route (%s): %v", url, err)
-				logHealthCheckError(errStr)
-				return fmt.Errorf("failed to GET route (%s): %v", url, err)
-			}
-			defer resp.Body.Close()
-
-			if resp.StatusCode != http.StatusOK {
-				reason = "StatusError"
-				errStr := fmt.Sprintf("route not yet available, %s returns '%s'", url, resp.Status)
-				logHealthCheckError(errStr)
-				return fmt.Errorf(errStr)
-			}
-			reason = ""
-			return nil
+			reason, err = c.checkURLHealth(ctx, url, routeTLS)
+			return err
 		},
 	)
 	return reason, err
 }
 
+// CheckHostnameAliasHealth checks that the console answers on each admitted hostname
+// alias. Unlike the main URL, the aliases are checked once per sync.
+func (c *HealthCheckController) CheckHostnameAliasHealth(ctx context.Context, operatorConfig *operatorsv1.Console) (string, error) {
+	aliases, err := routesub.GetHostnameAliases(operatorConfig)
+	if err != nil {
+		return "InvalidHostnameAliases", err
+	}
+	aliasErrors := []string{}
+	for _, alias := range aliases {
+		aliasRoute, err := c.routeLister.Routes(api.OpenShiftConsoleNamespace).Get(routesub.GetHostnameAliasRouteName(alias.Name))
+		if err != nil {
+			aliasErrors = append(aliasErrors, fmt.Sprintf("%s: %v", alias.Name, err))
+			continue
+		}
+		aliasURL, _, err := routeapihelpers.IngressURI(aliasRoute, aliasRoute.Spec.Host)
+		if err != nil {
+			aliasErrors = append(aliasErrors, fmt.Sprintf("%s: route is not admitted", alias.Name))
+			continue
+		}
+		if _, err := c.checkURLHealth(ctx, aliasURL, aliasRoute.Spec.TLS); err != nil {
+			aliasErrors = append(aliasErrors, fmt.Sprintf("%s: %v", alias.Name, err))
+		}
+	}
+	if len(aliasErrors) != 0 {
+		return "HostnameAliasesUnhealthy", fmt.Errorf("hostname aliases failing health check: %s", strings.Join(aliasErrors, "; "))
+	}
+	return "", nil
+}
+
+func (c *HealthCheckController) checkURLHealth(ctx context.Context, url *url.URL, routeTLS *routev1.TLSConfig) (string, error) {
+	caPool, err := c.getCA(ctx, routeTLS)
+	if err != nil {
+		errStr := fmt.Sprintf("failed to read CA to check route health: %v", err)
+		logHealthCheckError(errStr)
+		return "FailedLoadCA", fmt.Errorf(errStr)
+	}
+	return getURLHealth(clientWithCA(caPool), url)
+}
+
+// CheckCanaryHealth checks that the canary console answers on its service. The canary
+// serves the certificate of the console service, trusted through the service CA.
+func CheckCanaryHealth(serviceCAConfigMap *corev1.ConfigMap) (string, error) {
+	caPool := x509.NewCertPool()
+	if ok := caPool.AppendCertsFromPEM([]byte(serviceCAConfigMap.Data["service-ca.crt"])); !ok {
+		return "FailedLoadCA", fmt.Errorf("failed to parse service CA to check canary health")
+	}
+	client := clientWithCA(caPool)
+	transport := client.Transport.(*http.Transport)
+	// the canary service is only reachable from within the cluster
+	transport.Proxy = nil
+	transport.TLSClientConfig.ServerName = deploymentsub.CanaryServerName
+
+	canaryURL, err := url.Parse(deploymentsub.CanaryHealthURL)
+	if err != nil {
+		return "FailedParseCanaryURL", err
+	}
+	return getURLHealth(client, canaryURL)
+}
+
+func getURLHealth(client *http.Client, url *url.URL) (string, error) {
+	req, err := http.NewRequest(http.MethodGet, url.String(), nil)
+	if err != nil {
+		errStr := fmt.Sprintf("failed to build request to route (%s): %v", url, err)
+		logHealthCheckError(errStr)
+		return "FailedRequest", fmt.Errorf(errStr)
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		errStr := fmt.Sprintf("failed to GET route (%s): %v", url, err)
+		logHealthCheckError(errStr)
+		return "FailedGet", fmt.Errorf("failed to GET route (%s): %v", url, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		errStr := fmt.Sprintf("route not yet available, %s returns '%s'", url, resp.Status)
+		logHealthCheckError(errStr)
+		return "StatusError", fmt.Errorf(errStr)
+	}
+	return "", nil
+}
+
 func (c *HealthCheckController) getCA(ctx context.Context, tls *routev1.TLSConfig) (*x509.CertPool, error) {
 	caCertPool := x509.NewCertPool()
 
@@ -247,6 +347,13 @@ func (c *HealthCheckController) getCA(ctx context.Context, tls *routev1.TLSConfi
 	return caCertPool, nil
 }
 
+func activeConsoleRouteName(operatorConfig *operatorsv1.Console, ingressConfig *configv1.Ingress) string {
+	if routesub.NewRouteConfig(operatorConfig, ingressConfig, api.OpenShiftConsoleRouteName).IsCustomHostnameSet() {
+		return api.OpenshiftConsoleCustomRouteName
+	}
+	return api.OpenShiftConsoleRouteName
+}
+
 func clientWithCA(caPool *x509.CertPool) *http.Client {
 	return &http.Client{
 		Timeout: 5 * time.Second,
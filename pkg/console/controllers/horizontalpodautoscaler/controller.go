@@ -0,0 +1,111 @@
+package hpa
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	// k8s
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	autoscalingv2 "k8s.io/client-go/informers/autoscaling/v2"
+	autoscalingclientv2 "k8s.io/client-go/kubernetes/typed/autoscaling/v2"
+	"k8s.io/klog/v2"
+
+	// openshift
+	operatorsv1 "github.com/openshift/api/operator/v1"
+	operatorv1informers "github.com/openshift/client-go/operator/informers/externalversions/operator/v1"
+	operatorv1listers "github.com/openshift/client-go/operator/listers/operator/v1"
+	"github.com/openshift/library-go/pkg/controller/factory"
+	"github.com/openshift/library-go/pkg/operator/events"
+	"github.com/openshift/library-go/pkg/operator/v1helpers"
+
+	// console-operator
+	"github.com/openshift/console-operator/pkg/api"
+	"github.com/openshift/console-operator/pkg/console/controllers/util"
+	"github.com/openshift/console-operator/pkg/console/metrics"
+	"github.com/openshift/console-operator/pkg/console/status"
+	hpasub "github.com/openshift/console-operator/pkg/console/subresource/hpa"
+)
+
+// HorizontalPodAutoscalerController owns the HPA of the console deployment while the
+// autoscaling is set on the operator config.
+type HorizontalPodAutoscalerController struct {
+	operatorClient       v1helpers.OperatorClient
+	operatorConfigLister operatorv1listers.ConsoleLister
+	hpaClient            autoscalingclientv2.HorizontalPodAutoscalersGetter
+}
+
+func NewHorizontalPodAutoscalerController(
+	// clients
+	operatorClient v1helpers.OperatorClient,
+	operatorConfigInformer operatorv1informers.ConsoleInformer,
+	hpaClient autoscalingclientv2.HorizontalPodAutoscalersGetter,
+	// informer
+	hpaInformer autoscalingv2.HorizontalPodAutoscalerInformer,
+	// events
+	recorder events.Recorder,
+) factory.Controller {
+	ctrl := &HorizontalPodAutoscalerController{
+		operatorClient:       operatorClient,
+		operatorConfigLister: operatorConfigInformer.Lister(),
+		hpaClient:            hpaClient,
+	}
+
+	return factory.New().
+		WithFilteredEventsInformers(
+			util.IncludeNamesFilter(api.OpenShiftConsoleDeploymentName),
+			hpaInformer.Informer(),
+		).
+		WithFilteredEventsInformers( // configs
+			util.IncludeNamesFilter(api.ConfigResourceName),
+			operatorConfigInformer.Informer(),
+		).
+		ResyncEvery(time.Minute).WithSync(metrics.InstrumentSync("HorizontalPodAutoscalerController", ctrl.Sync)).
+		ToController("HorizontalPodAutoscalerController", recorder.WithComponentSuffix("console-hpa-controller"))
+}
+
+func (c *HorizontalPodAutoscalerController) Sync(ctx context.Context, controllerContext factory.SyncContext) error {
+	operatorConfig, err := c.operatorConfigLister.Get(api.ConfigResourceName)
+	if err != nil {
+		return err
+	}
+	updatedOperatorConfig := operatorConfig.DeepCopy()
+
+	switch updatedOperatorConfig.Spec.ManagementState {
+	case operatorsv1.Managed:
+		klog.V(4).Infoln("console-operator is in a managed state: syncing console hpa")
+	case operatorsv1.Unmanaged:
+		klog.V(4).Infoln("console-operator is in an unmanaged state: skipping console hpa sync")
+		return nil
+	case operatorsv1.Removed:
+		klog.V(4).Infoln("console-operator is in a removed state: deleting console hpa")
+		return c.removeHorizontalPodAutoscaler(ctx)
+	default:
+		return fmt.Errorf("unknown state: %v", updatedOperatorConfig.Spec.ManagementState)
+	}
+
+	statusHandler := status.NewStatusHandler(c.operatorClient)
+
+	// an invalid autoscaling leaves the console on a fixed number of replicas
+	autoscaling, autoscalingErr := hpasub.GetAutoscaling(updatedOperatorConfig)
+	statusHandler.AddCondition(status.HandleDegraded("Autoscaling", "InvalidAutoscaling", autoscalingErr))
+	if autoscaling == nil {
+		removeErr := c.removeHorizontalPodAutoscaler(ctx)
+		statusHandler.AddConditions(status.HandleProgressingOrDegraded("HorizontalPodAutoscalerSync", "FailedDelete", removeErr))
+		return statusHandler.FlushAndReturn(removeErr)
+	}
+
+	requiredHPA := hpasub.DefaultHorizontalPodAutoscaler(updatedOperatorConfig, autoscaling)
+	_, _, hpaErr := hpasub.ApplyHorizontalPodAutoscaler(ctx, c.hpaClient, requiredHPA)
+	statusHandler.AddConditions(status.HandleProgressingOrDegraded("HorizontalPodAutoscalerSync", "FailedApply", hpaErr))
+	return statusHandler.FlushAndReturn(hpaErr)
+}
+
+func (c *HorizontalPodAutoscalerController) removeHorizontalPodAutoscaler(ctx context.Context) error {
+	err := c.hpaClient.HorizontalPodAutoscalers(api.OpenShiftConsoleNamespace).Delete(ctx, api.OpenShiftConsoleDeploymentName, metav1.DeleteOptions{})
+	if apierrors.IsNotFound(err) {
+		return nil
+	}
+	return err
+}
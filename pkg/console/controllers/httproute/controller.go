@@ -0,0 +1,241 @@
+package httproute
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	// k8s
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/dynamic"
+	coreinformersv1 "k8s.io/client-go/informers/core/v1"
+	corev1client "k8s.io/client-go/kubernetes/typed/core/v1"
+	corev1listers "k8s.io/client-go/listers/core/v1"
+	"k8s.io/klog/v2"
+
+	// openshift
+	operatorsv1 "github.com/openshift/api/operator/v1"
+	configinformer "github.com/openshift/client-go/config/informers/externalversions"
+	configlistersv1 "github.com/openshift/client-go/config/listers/config/v1"
+	v1 "github.com/openshift/client-go/operator/informers/externalversions/operator/v1"
+	operatorv1listers "github.com/openshift/client-go/operator/listers/operator/v1"
+	"github.com/openshift/library-go/pkg/controller/factory"
+	"github.com/openshift/library-go/pkg/operator/events"
+	"github.com/openshift/library-go/pkg/operator/resource/resourceapply"
+	"github.com/openshift/library-go/pkg/operator/v1helpers"
+
+	// console-operator
+	"github.com/openshift/console-operator/pkg/api"
+	"github.com/openshift/console-operator/pkg/console/controllers/util"
+	"github.com/openshift/console-operator/pkg/console/metrics"
+	"github.com/openshift/console-operator/pkg/console/status"
+	httproutesub "github.com/openshift/console-operator/pkg/console/subresource/httproute"
+	routesub "github.com/openshift/console-operator/pkg/console/subresource/route"
+	utilsub "github.com/openshift/console-operator/pkg/console/subresource/util"
+)
+
+// HTTPRouteSyncController exposes the console or the downloads through a Gateway when
+// one is set on the operator config, it is the Gateway API counterpart of the
+// RouteSyncController. The default and custom hostnames get an HTTPRoute each, as
+// Routes would, the default one points to the redirect service when the console has a
+// custom hostname.
+type HTTPRouteSyncController struct {
+	routeName string
+	// clients
+	operatorClient       v1helpers.OperatorClient
+	dynamicClient        dynamic.Interface
+	configMapClient      corev1client.ConfigMapsGetter
+	operatorConfigLister operatorv1listers.ConsoleLister
+	ingressConfigLister  configlistersv1.IngressLister
+	configMapLister      corev1listers.ConfigMapLister
+	// removed records that the Gateway API objects were cleaned up since the
+	// gateway was last enabled, to spare the API calls on every resync.
+	removed bool
+}
+
+func NewHTTPRouteSyncController(
+	routeName string,
+	// top level config
+	configInformer configinformer.SharedInformerFactory,
+	// clients
+	operatorClient v1helpers.OperatorClient,
+	dynamicClient dynamic.Interface,
+	configMapClient corev1client.ConfigMapsGetter,
+	// informers
+	operatorConfigInformer v1.ConsoleInformer,
+	coreInformer coreinformersv1.Interface,
+	// events
+	recorder events.Recorder,
+) factory.Controller {
+	ctrl := &HTTPRouteSyncController{
+		routeName:            routeName,
+		operatorClient:       operatorClient,
+		dynamicClient:        dynamicClient,
+		configMapClient:      configMapClient,
+		operatorConfigLister: operatorConfigInformer.Lister(),
+		ingressConfigLister:  configInformer.Config().V1().Ingresses().Lister(),
+		configMapLister:      coreInformer.ConfigMaps().Lister(),
+	}
+
+	configV1Informers := configInformer.Config().V1()
+	controllerName := fmt.Sprintf("%sHTTPRouteController", strings.Title(routeName))
+
+	// the Gateway API may not be installed, HTTPRoutes are read on resync rather than watched.
+	return factory.New().
+		WithFilteredEventsInformers( // configs
+			util.IncludeNamesFilter(api.ConfigResourceName),
+			operatorConfigInformer.Informer(),
+			configV1Informers.Ingresses().Informer(),
+		).WithFilteredEventsInformers(
+		util.IncludeNamesFilter(api.ServiceCAConfigMapName),
+		coreInformer.ConfigMaps().Informer(),
+	).ResyncEvery(time.Minute).WithSync(metrics.InstrumentSync(controllerName, ctrl.Sync)).
+		ToController(controllerName, recorder.WithComponentSuffix(fmt.Sprintf("%s-httproute-controller", routeName)))
+}
+
+func (c *HTTPRouteSyncController) Sync(ctx context.Context, controllerContext factory.SyncContext) error {
+	operatorConfig, err := c.operatorConfigLister.Get(api.ConfigResourceName)
+	if err != nil {
+		return err
+	}
+
+	switch operatorConfig.Spec.ManagementState {
+	case operatorsv1.Managed:
+		klog.V(4).Infof("console-operator is in a managed state: syncing %q HTTPRoute", c.routeName)
+	case operatorsv1.Unmanaged:
+		klog.V(4).Infof("console-operator is in an unmanaged state: skipping %q HTTPRoute sync", c.routeName)
+		return nil
+	case operatorsv1.Removed:
+		klog.V(4).Infof("console-operator is in a removed state: deleting %q HTTPRoute", c.routeName)
+		return c.removeHTTPRoutes(ctx)
+	default:
+		return fmt.Errorf("unknown state: %v", operatorConfig.Spec.ManagementState)
+	}
+
+	statusHandler := status.NewStatusHandler(c.operatorClient)
+	typePrefix := fmt.Sprintf("%sHTTPRouteSync", strings.Title(c.routeName))
+
+	if !httproutesub.IsGatewayEnabled(operatorConfig) {
+		statusHandler.AddConditions(status.HandleProgressingOrDegraded(typePrefix, "", nil))
+		if c.removed {
+			return statusHandler.FlushAndReturn(nil)
+		}
+		return statusHandler.FlushAndReturn(c.removeHTTPRoutes(ctx))
+	}
+	c.removed = false
+
+	// Do not proceed if alternative ingress is requested.
+	switch c.routeName {
+	case api.OpenShiftConsoleRouteName:
+		if len(operatorConfig.Spec.Ingress.ConsoleURL) != 0 {
+			return statusHandler.FlushAndReturn(nil)
+		}
+	case api.OpenShiftConsoleDownloadsRouteName:
+		if len(operatorConfig.Spec.Ingress.ClientDownloadsURL) != 0 {
+			return statusHandler.FlushAndReturn(nil)
+		}
+	}
+
+	gateway, err := httproutesub.GetGatewayReference(operatorConfig)
+	if err != nil {
+		statusHandler.AddConditions(status.HandleProgressingOrDegraded(typePrefix, "InvalidGatewayReference", err))
+		return statusHandler.FlushAndReturn(err)
+	}
+
+	ingressConfig, err := c.ingressConfigLister.Get(api.ConfigResourceName)
+	if err != nil {
+		return statusHandler.FlushAndReturn(err)
+	}
+	routeConfig := routesub.NewRouteConfig(operatorConfig, ingressConfig, c.routeName)
+
+	reason, err := c.SyncHTTPRoutes(ctx, operatorConfig, routeConfig, gateway, controllerContext.Recorder())
+	statusHandler.AddConditions(status.HandleProgressingOrDegraded(typePrefix, reason, err))
+	return statusHandler.FlushAndReturn(err)
+}
+
+// SyncHTTPRoutes applies the HTTPRoutes for the default and, when set, the custom
+// hostname. The console additionally gets the BackendTLSPolicy that lets the Gateway
+// re-encrypt the traffic to its service.
+func (c *HTTPRouteSyncController) SyncHTTPRoutes(
+	ctx context.Context,
+	operatorConfig *operatorsv1.Console,
+	routeConfig *routesub.RouteConfig,
+	gateway *httproutesub.GatewayReference,
+	recorder events.Recorder,
+) (string, error) {
+	serviceName, servicePort := c.routeName, int64(80)
+	if c.routeName == api.OpenShiftConsoleRouteName {
+		serviceName, servicePort = api.OpenShiftConsoleServiceName, int64(api.ConsoleContainerPort)
+		if reason, err := c.syncBackendTLS(ctx, operatorConfig, recorder); err != nil {
+			return reason, err
+		}
+	}
+
+	customRouteName := routesub.GetCustomRouteName(c.routeName)
+	defaultServiceName, defaultServicePort := serviceName, servicePort
+	if routeConfig.IsCustomHostnameSet() {
+		customHTTPRoute := httproutesub.DefaultHTTPRoute(customRouteName, routeConfig.GetCustomRouteHostname(), serviceName, servicePort, gateway)
+		utilsub.AddOwnerRef(customHTTPRoute, utilsub.OwnerRefFrom(operatorConfig))
+		if _, _, err := httproutesub.Apply(ctx, c.dynamicClient, httproutesub.HTTPRouteResource, customHTTPRoute); err != nil {
+			return "FailedCustomHTTPRouteApply", err
+		}
+		// the console redirects the default hostname to the custom one
+		if c.routeName == api.OpenShiftConsoleRouteName {
+			defaultServiceName, defaultServicePort = api.OpenshiftConsoleRedirectServiceName, int64(api.RedirectContainerPort)
+		}
+	} else if err := httproutesub.Delete(ctx, c.dynamicClient, httproutesub.HTTPRouteResource, customRouteName); err != nil {
+		return "FailedCustomHTTPRouteDelete", err
+	}
+
+	defaultHTTPRoute := httproutesub.DefaultHTTPRoute(c.routeName, routeConfig.GetDefaultRouteHostname(), defaultServiceName, defaultServicePort, gateway)
+	utilsub.AddOwnerRef(defaultHTTPRoute, utilsub.OwnerRefFrom(operatorConfig))
+	if _, _, err := httproutesub.Apply(ctx, c.dynamicClient, httproutesub.HTTPRouteResource, defaultHTTPRoute); err != nil {
+		return "FailedDefaultHTTPRouteApply", err
+	}
+	return "", nil
+}
+
+func (c *HTTPRouteSyncController) syncBackendTLS(ctx context.Context, operatorConfig *operatorsv1.Console, recorder events.Recorder) (string, error) {
+	serviceCA, err := c.configMapLister.ConfigMaps(api.OpenShiftConsoleNamespace).Get(api.ServiceCAConfigMapName)
+	if err != nil {
+		return "FailedGetServiceCA", err
+	}
+	backendCA := &corev1.ConfigMap{
+		ObjectMeta: utilsub.SharedMeta(),
+		Data:       map[string]string{"ca.crt": serviceCA.Data["service-ca.crt"]},
+	}
+	backendCA.Name = httproutesub.BackendCAConfigMapName
+	utilsub.AddOwnerRef(backendCA, utilsub.OwnerRefFrom(operatorConfig))
+	if _, _, err := resourceapply.ApplyConfigMap(ctx, c.configMapClient, recorder, backendCA); err != nil {
+		return "FailedBackendCAApply", err
+	}
+
+	backendTLSPolicy := httproutesub.DefaultBackendTLSPolicy()
+	utilsub.AddOwnerRef(backendTLSPolicy, utilsub.OwnerRefFrom(operatorConfig))
+	if _, _, err := httproutesub.Apply(ctx, c.dynamicClient, httproutesub.BackendTLSPolicyResource, backendTLSPolicy); err != nil {
+		return "FailedBackendTLSPolicyApply", err
+	}
+	return "", nil
+}
+
+func (c *HTTPRouteSyncController) removeHTTPRoutes(ctx context.Context) error {
+	for _, name := range []string{routesub.GetCustomRouteName(c.routeName), c.routeName} {
+		if err := httproutesub.Delete(ctx, c.dynamicClient, httproutesub.HTTPRouteResource, name); err != nil {
+			return err
+		}
+	}
+	if c.routeName == api.OpenShiftConsoleRouteName {
+		if err := httproutesub.Delete(ctx, c.dynamicClient, httproutesub.BackendTLSPolicyResource, api.OpenShiftConsoleName); err != nil {
+			return err
+		}
+		err := c.configMapClient.ConfigMaps(api.OpenShiftConsoleNamespace).Delete(ctx, httproutesub.BackendCAConfigMapName, metav1.DeleteOptions{})
+		if err != nil && !apierrors.IsNotFound(err) {
+			return err
+		}
+	}
+	c.removed = true
+	return nil
+}
@@ -0,0 +1,61 @@
+package httproute
+
+import (
+	"context"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/tools/cache"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+	operatorv1listers "github.com/openshift/client-go/operator/listers/operator/v1"
+	"github.com/openshift/library-go/pkg/controller/factory"
+	"github.com/openshift/library-go/pkg/operator/events"
+	"github.com/openshift/library-go/pkg/operator/v1helpers"
+
+	"github.com/openshift/console-operator/pkg/api"
+	httproutesub "github.com/openshift/console-operator/pkg/console/subresource/httproute"
+)
+
+func TestSyncWithEmptyGatewayAnnotation(t *testing.T) {
+	operatorConfig := &operatorv1.Console{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        api.ConfigResourceName,
+			Annotations: map[string]string{httproutesub.GatewayAnnotation: ""},
+		},
+		Spec: operatorv1.ConsoleSpec{
+			OperatorSpec: operatorv1.OperatorSpec{ManagementState: operatorv1.Managed},
+		},
+	}
+	operatorConfigIndexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+	if err := operatorConfigIndexer.Add(operatorConfig); err != nil {
+		t.Fatal(err)
+	}
+	dynamicClient := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), map[schema.GroupVersionResource]string{
+		httproutesub.HTTPRouteResource:        "HTTPRouteList",
+		httproutesub.BackendTLSPolicyResource: "BackendTLSPolicyList",
+	})
+	c := &HTTPRouteSyncController{
+		routeName:            api.OpenShiftConsoleRouteName,
+		operatorClient:       v1helpers.NewFakeOperatorClient(&operatorConfig.Spec.OperatorSpec, &operatorv1.OperatorStatus{}, nil),
+		dynamicClient:        dynamicClient,
+		configMapClient:      fake.NewSimpleClientset().CoreV1(),
+		operatorConfigLister: operatorv1listers.NewConsoleLister(operatorConfigIndexer),
+	}
+
+	// the console stays on Routes, there is no Gateway to attach HTTPRoutes to
+	if err := c.Sync(context.TODO(), factory.NewSyncContext("test", events.NewInMemoryRecorder("test"))); err != nil {
+		t.Fatal(err)
+	}
+	httpRoutes, err := dynamicClient.Resource(httproutesub.HTTPRouteResource).Namespace(api.OpenShiftConsoleNamespace).List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(httpRoutes.Items) != 0 {
+		t.Errorf("expected no HTTPRoutes, got %d", len(httpRoutes.Items))
+	}
+}
@@ -9,8 +9,11 @@ import (
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/dynamic"
 	corev1informers "k8s.io/client-go/informers/core/v1"
+	networkinginformersv1 "k8s.io/client-go/informers/networking/v1"
 	corev1listers "k8s.io/client-go/listers/core/v1"
+	networkingv1listers "k8s.io/client-go/listers/networking/v1"
 	"k8s.io/client-go/tools/cache"
 	"k8s.io/klog/v2"
 
@@ -31,7 +34,10 @@ import (
 
 	"github.com/openshift/console-operator/pkg/api"
 	"github.com/openshift/console-operator/pkg/console/controllers/util"
+	"github.com/openshift/console-operator/pkg/console/metrics"
 	"github.com/openshift/console-operator/pkg/console/status"
+	httproutesub "github.com/openshift/console-operator/pkg/console/subresource/httproute"
+	ingresssub "github.com/openshift/console-operator/pkg/console/subresource/ingress"
 	oauthsub "github.com/openshift/console-operator/pkg/console/subresource/oauthclient"
 	routesub "github.com/openshift/console-operator/pkg/console/subresource/route"
 	secretsub "github.com/openshift/console-operator/pkg/console/subresource/secret"
@@ -48,6 +54,7 @@ import (
 type oauthClientsController struct {
 	oauthClient    oauthv1client.OAuthClientsGetter
 	operatorClient v1helpers.OperatorClient
+	dynamicClient  dynamic.Interface
 
 	oauthClientLister           oauthv1lister.OAuthClientLister
 	oauthClientSwitchedInformer *util.InformerWithSwitch
@@ -55,15 +62,18 @@ type oauthClientsController struct {
 	consoleOperatorLister       operatorv1listers.ConsoleLister
 	routesLister                routev1listers.RouteLister
 	ingressConfigLister         configv1lister.IngressLister
+	ingressLister               networkingv1listers.IngressLister
 	targetNSSecretsLister       corev1listers.SecretLister
 }
 
 func NewOAuthClientsController(
 	operatorClient v1helpers.OperatorClient,
 	oauthClient oauthclient.Interface,
+	dynamicClient dynamic.Interface,
 	authnInformer configv1informers.AuthenticationInformer,
 	consoleOperatorInformer operatorv1informers.ConsoleInformer,
 	routeInformer routev1informers.RouteInformer,
+	ingressInformer networkinginformersv1.IngressInformer,
 	ingressConfigInformer configv1informers.IngressInformer,
 	targetNSsecretsInformer corev1informers.SecretInformer,
 	oauthClientSwitchedInformer *util.InformerWithSwitch,
@@ -72,22 +82,25 @@ func NewOAuthClientsController(
 	c := oauthClientsController{
 		oauthClient:    oauthClient.OauthV1(),
 		operatorClient: operatorClient,
+		dynamicClient:  dynamicClient,
 
 		oauthClientLister:           oauthClientSwitchedInformer.Lister(),
 		oauthClientSwitchedInformer: oauthClientSwitchedInformer,
 		authnLister:                 authnInformer.Lister(),
 		consoleOperatorLister:       consoleOperatorInformer.Lister(),
 		routesLister:                routeInformer.Lister(),
+		ingressLister:               ingressInformer.Lister(),
 		ingressConfigLister:         ingressConfigInformer.Lister(),
 		targetNSSecretsLister:       targetNSsecretsInformer.Lister(),
 	}
 
 	return factory.New().
-		WithSync(c.sync).
+		WithSync(metrics.InstrumentSync("OAuthClientsController", c.sync)).
 		WithInformers(
 			authnInformer.Informer(),
 			consoleOperatorInformer.Informer(),
 			routeInformer.Informer(),
+			ingressInformer.Informer(),
 			ingressConfigInformer.Informer(),
 			targetNSsecretsInformer.Informer(),
 		).
@@ -133,7 +146,10 @@ func (c *oauthClientsController) sync(ctx context.Context, controllerContext fac
 		return err
 	}
 
-	var consoleURL *url.URL
+	var (
+		consoleURL *url.URL
+		aliasHosts []string
+	)
 
 	if len(operatorConfig.Spec.Ingress.ConsoleURL) == 0 {
 		routeName := api.OpenShiftConsoleRouteName
@@ -142,7 +158,26 @@ func (c *oauthClientsController) sync(ctx context.Context, controllerContext fac
 			routeName = api.OpenshiftConsoleCustomRouteName
 		}
 
-		_, url, _, routeErr := routesub.GetActiveRouteInfo(c.routesLister, routeName)
+		var (
+			url      *url.URL
+			routeErr error
+		)
+		if httproutesub.IsGatewayEnabled(operatorConfig) {
+			_, url, _, routeErr = httproutesub.GetActiveHTTPRouteInfo(ctx, c.dynamicClient, routeName)
+		} else if ingresssub.IsIngressEnabled(operatorConfig) {
+			_, url, _, routeErr = ingresssub.GetActiveIngressInfo(c.ingressLister, routeName)
+		} else {
+			_, url, _, routeErr = routesub.GetActiveRouteInfo(c.routesLister, routeName)
+			if routeErr == nil {
+				aliasURLs, aliasErr := routesub.GetAdmittedHostnameAliasURLs(c.routesLister, operatorConfig)
+				if aliasErr != nil {
+					klog.V(4).Infof("skipping the hostname aliases: %v", aliasErr)
+				}
+				for _, aliasURL := range aliasURLs {
+					aliasHosts = append(aliasHosts, aliasURL.String())
+				}
+			}
+		}
 		if routeErr != nil {
 			return routeErr
 		}
@@ -166,7 +201,7 @@ func (c *oauthClientsController) sync(ctx context.Context, controllerContext fac
 		return err
 	}
 
-	oauthErrReason, err := c.syncOAuthClient(ctx, clientSecret, consoleURL.String())
+	oauthErrReason, err := c.syncOAuthClient(ctx, clientSecret, consoleURL.String(), aliasHosts)
 	statusHandler.AddConditions(status.HandleProgressingOrDegraded("OAuthClientSync", oauthErrReason, err))
 	if err != nil {
 		return statusHandler.FlushAndReturn(err)
@@ -205,6 +240,7 @@ func (c *oauthClientsController) syncOAuthClient(
 	ctx context.Context,
 	sec *corev1.Secret,
 	consoleURL string,
+	aliasHosts []string,
 ) (reason string, err error) {
 	oauthClient, err := c.oauthClientLister.Get(oauthsub.Stub().Name)
 	if err != nil {
@@ -212,7 +248,10 @@ func (c *oauthClientsController) syncOAuthClient(
 		return "FailedGet", fmt.Errorf("oauth client for console does not exist and cannot be created (%w)", err)
 	}
 	clientCopy := oauthClient.DeepCopy()
-	oauthsub.RegisterConsoleToOAuthClient(clientCopy, consoleURL, secretsub.GetSecretString(sec))
+	oauthsub.RegisterConsoleToOAuthClient(clientCopy, consoleURL, secretsub.GetSecretString(sec), aliasHosts...)
+	// during a rotation the new secret is accepted before the console uses it, and the
+	// replaced one until the console no longer does
+	oauthsub.SetAdditionalSecrets(clientCopy, secretsub.GetNextSecretString(sec), secretsub.GetPreviousSecretString(sec))
 	_, _, oauthErr := oauthsub.CustomApplyOAuth(c.oauthClient, clientCopy, ctx)
 	if oauthErr != nil {
 		return "FailedRegister", oauthErr
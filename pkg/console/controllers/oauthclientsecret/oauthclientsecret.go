@@ -3,10 +3,16 @@ package oauthclientsecret
 import (
 	"context"
 	"fmt"
+	"time"
 
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/util/wait"
+	appsv1informers "k8s.io/client-go/informers/apps/v1"
 	corev1informers "k8s.io/client-go/informers/core/v1"
 	corev1clients "k8s.io/client-go/kubernetes/typed/core/v1"
+	appsv1listers "k8s.io/client-go/listers/apps/v1"
 	corev1listers "k8s.io/client-go/listers/core/v1"
 	"k8s.io/klog/v2"
 
@@ -14,28 +20,36 @@ import (
 	operatorv1 "github.com/openshift/api/operator/v1"
 	configv1informers "github.com/openshift/client-go/config/informers/externalversions/config/v1"
 	configv1listers "github.com/openshift/client-go/config/listers/config/v1"
+	oauthv1listers "github.com/openshift/client-go/oauth/listers/oauth/v1"
 	operatorv1informers "github.com/openshift/client-go/operator/informers/externalversions/operator/v1"
 	operatorv1listers "github.com/openshift/client-go/operator/listers/operator/v1"
 	"github.com/openshift/console-operator/pkg/api"
+	"github.com/openshift/console-operator/pkg/console/controllers/util"
+	customerrors "github.com/openshift/console-operator/pkg/console/errors"
+	"github.com/openshift/console-operator/pkg/console/metrics"
 	"github.com/openshift/console-operator/pkg/console/status"
-	"github.com/openshift/console-operator/pkg/crypto"
 	"github.com/openshift/library-go/pkg/controller/factory"
 	"github.com/openshift/library-go/pkg/operator/events"
 	"github.com/openshift/library-go/pkg/operator/resource/resourceapply"
 	v1helpers "github.com/openshift/library-go/pkg/operator/v1helpers"
 
 	authnsub "github.com/openshift/console-operator/pkg/console/subresource/authentication"
+	configmapsub "github.com/openshift/console-operator/pkg/console/subresource/configmap"
 	secretsub "github.com/openshift/console-operator/pkg/console/subresource/secret"
 )
 
 // oauthClientSecretController behaves differently based on authentication/cluster .spec.type:
 //
-//   - IntegratedOAuth - self-manage the client secret string
+//   - IntegratedOAuth - self-manage the client secret string, rotated on a schedule or on
+//     demand along with the OAuthClient and the console deployment
 //   - OIDC - lookup our client in the authentication/cluster .spec.oidcProviders[x].oidcClients
-//     slice and use the 'clientSecret' from the secret referred to by .clientSecret.name
+//     slice and use the 'clientSecret' from the secret referred to by .clientSecret.name, the
+//     live secret is kept until the provider's issuer passed the discovery pre-flight
 //   - None - do nothing
 //
-// The secret written is 'openshift-console/console-oauth-config' in .Data['clientSecret']
+// The secret written is 'openshift-console/console-oauth-config' in .Data['clientSecret'],
+// and a rotation keeps the new and the replaced secret in .Data['nextClientSecret'] and
+// .Data['previousClientSecret']
 //
 // ==========
 //
@@ -44,6 +58,9 @@ import (
 //	- consoles.operator.openshift.io/cluster .status.conditions:
 //		- type=OAuthClientSecretSyncProgressing
 //		- type=OAuthClientSecretSyncDegraded
+//		- type=OAuthClientSecretRotationProgressing
+//		- type=OAuthClientSecretRotationDegraded
+//		- type=OAuthClientSecretRotationScheduleDegraded
 type oauthClientSecretController struct {
 	operatorClient v1helpers.OperatorClient
 	secretsClient  corev1clients.SecretsGetter
@@ -52,6 +69,11 @@ type oauthClientSecretController struct {
 	consoleOperatorLister operatorv1listers.ConsoleLister
 	configSecretsLister   corev1listers.SecretLister
 	targetNSSecretsLister corev1listers.SecretLister
+	// the OIDC issuers that passed the discovery pre-flight
+	targetNSConfigMapLister corev1listers.ConfigMapLister
+	// the OAuthClient and the console deployment a rotation waits on
+	oauthClientLister         oauthv1listers.OAuthClientLister
+	targetNSDeploymentsLister appsv1listers.DeploymentLister
 }
 
 func NewOAuthClientSecretController(
@@ -61,6 +83,9 @@ func NewOAuthClientSecretController(
 	consoleOperatorInformer operatorv1informers.ConsoleInformer,
 	configSecretsInformer corev1informers.SecretInformer,
 	targetNSsecretsInformer corev1informers.SecretInformer,
+	targetNSConfigMapInformer corev1informers.ConfigMapInformer,
+	targetNSDeploymentsInformer appsv1informers.DeploymentInformer,
+	oauthClientSwitchedInformer *util.InformerWithSwitch,
 	recorder events.Recorder,
 ) factory.Controller {
 	c := &oauthClientSecretController{
@@ -71,10 +96,15 @@ func NewOAuthClientSecretController(
 		consoleOperatorLister: consoleOperatorInformer.Lister(),
 		configSecretsLister:   configSecretsInformer.Lister(),
 		targetNSSecretsLister: targetNSsecretsInformer.Lister(),
+
+		targetNSConfigMapLister: targetNSConfigMapInformer.Lister(),
+
+		oauthClientLister:         oauthClientSwitchedInformer.Lister(),
+		targetNSDeploymentsLister: targetNSDeploymentsInformer.Lister(),
 	}
 
 	return factory.New().
-		WithSync(c.sync).
+		WithSync(metrics.InstrumentSync("OAuthClientSecretController", c.sync)).
 		WithInformers(
 			authnInformer.Informer(),
 			consoleOperatorInformer.Informer(),
@@ -83,6 +113,17 @@ func NewOAuthClientSecretController(
 		WithFilteredEventsInformers(
 			factory.NamesFilter("console-oauth-config"), targetNSsecretsInformer.Informer(),
 		).
+		WithFilteredEventsInformers(
+			factory.NamesFilter(api.ConsoleOIDCDiscoveryName), targetNSConfigMapInformer.Informer(),
+		).
+		WithFilteredEventsInformers(
+			factory.NamesFilter(api.OpenShiftConsoleDeploymentName), targetNSDeploymentsInformer.Informer(),
+		).
+		WithFilteredEventsInformers(
+			factory.NamesFilter(api.OAuthClientName), oauthClientSwitchedInformer.Informer(),
+		).
+		// scheduled rotations are not triggered by any event
+		ResyncEvery(wait.Jitter(time.Minute, 1.0)).
 		ToController("OAuthClientSecretController", recorder.WithComponentSuffix("oauthclient-secret-controller"))
 }
 
@@ -105,19 +146,33 @@ func (c *oauthClientSecretController) sync(ctx context.Context, syncCtx factory.
 		return fmt.Errorf("failed to retrieve authentication config: %w", err)
 	}
 
-	var secretString string
+	operatorConfig, err := c.consoleOperatorLister.Get(api.ConfigResourceName)
+	if err != nil {
+		return err
+	}
+
+	// the client secret only rotates with the integrated OAuth server
+	statusHandler.AddConditions(status.HandleProgressingOrDegraded("OAuthClientSecretRotation", "", nil))
+	statusHandler.AddCondition(status.HandleDegraded("OAuthClientSecretRotationSchedule", "", nil))
+
+	var required *corev1.Secret
 	switch authConfig.Spec.Type {
 	// We don't disable auth since the internal OAuth server is not disabled even with auth type 'None'.
 	case "", configv1.AuthenticationTypeIntegratedOAuth, configv1.AuthenticationTypeNone:
-		// in OpenShift controlled world, we generate the client secret ourselves
-		if clientSecret != nil {
-			secretString = secretsub.GetSecretString(clientSecret)
-		}
-		if len(secretString) == 0 {
-			secretString = crypto.Random256BitsString()
+		// in OpenShift controlled world, we generate and rotate the client secret ourselves,
+		// an invalid period leaves it to on-demand rotations
+		period, periodErr := secretsub.GetClientSecretRotationPeriod(operatorConfig)
+		statusHandler.AddCondition(status.HandleDegraded("OAuthClientSecretRotationSchedule", "InvalidRotationPeriod", periodErr))
+
+		var rotationReason string
+		var rotationErr error
+		required, rotationReason, rotationErr = c.integratedOAuthSecret(operatorConfig, clientSecret, period, syncCtx.Recorder())
+		statusHandler.AddConditions(status.HandleProgressingOrDegraded("OAuthClientSecretRotation", rotationReason, rotationErr))
+		if rotationErr != nil && !customerrors.IsSyncError(rotationErr) {
+			return statusHandler.FlushAndReturn(rotationErr)
 		}
 	case configv1.AuthenticationTypeOIDC:
-		_, clientConfig := authnsub.GetOIDCClientConfig(authConfig, api.TargetNamespace, api.OpenShiftConsoleName)
+		oidcProvider, clientConfig := authnsub.GetOIDCClientConfig(authConfig, api.TargetNamespace, api.OpenShiftConsoleName)
 		if clientConfig == nil {
 			// no config, flush the condition and return
 			statusHandler.AddConditions(status.HandleProgressingOrDegraded("OAuthClientSecretSync", "", nil))
@@ -135,11 +190,16 @@ func (c *oauthClientSecretController) sync(ctx context.Context, syncCtx factory.
 			return statusHandler.FlushAndReturn(err)
 		}
 
-		secretString = secretsub.GetSecretString(conficClientSecret)
+		secretString := secretsub.GetSecretString(conficClientSecret)
 		if len(secretString) == 0 {
 			statusHandler.AddConditions(status.HandleProgressingOrDegraded("OAuthClientSecretSync", "ClientSecretKeyMissing", fmt.Errorf("missing the 'clientSecret' key in the client secret secret %q", clientConfig.ClientSecret.Name)))
 			return statusHandler.FlushAndReturn(nil)
 		}
+		required, err = c.oidcClientSecret(operatorConfig, oidcProvider, clientSecret, secretString)
+		if err != nil {
+			statusHandler.AddConditions(status.HandleProgressingOrDegraded("OAuthClientSecretSync", "FailedGetOIDCDiscovery", err))
+			return statusHandler.FlushAndReturn(err)
+		}
 
 	default:
 		klog.V(2).Infof("unknown authentication type: %s", authConfig.Spec.Type)
@@ -147,24 +207,52 @@ func (c *oauthClientSecretController) sync(ctx context.Context, syncCtx factory.
 		return statusHandler.FlushAndReturn(nil)
 	}
 
-	err = c.syncSecret(ctx, secretString, syncCtx.Recorder())
+	err = c.syncSecret(ctx, required, syncCtx.Recorder())
 	statusHandler.AddConditions(status.HandleProgressingOrDegraded("OAuthClientSecretSync", "FailedApply", err))
 	return statusHandler.FlushAndReturn(err)
 }
 
-func (c *oauthClientSecretController) syncSecret(ctx context.Context, clientSecret string, recorder events.Recorder) error {
-	operatorConfig, err := c.consoleOperatorLister.Get(api.ConfigResourceName)
-	if err != nil {
-		return err
+// oidcClientSecret returns the secret with the OIDC client secret. While the provider's issuer
+// has not passed the discovery pre-flight the live secret is returned as is, the same way
+// console-config keeps its auth and the console deployment the CA it mounts.
+func (c *oauthClientSecretController) oidcClientSecret(operatorConfig *operatorv1.Console, oidcProvider *configv1.OIDCProvider, clientSecret *corev1.Secret, secretString string) (*corev1.Secret, error) {
+	required := secretsub.DefaultSecret(operatorConfig, secretString)
+	// nothing to keep on a fresh install
+	if clientSecret == nil {
+		return required, nil
+	}
+
+	discoveryConfigMap, err := c.targetNSConfigMapLister.ConfigMaps(api.TargetNamespace).Get(api.ConsoleOIDCDiscoveryName)
+	if err != nil && !apierrors.IsNotFound(err) {
+		return nil, err
+	}
+	if apierrors.IsNotFound(err) {
+		discoveryConfigMap = nil
 	}
+	if configmapsub.IsOIDCIssuerVerified(discoveryConfigMap, oidcProvider.Issuer.URL) {
+		return required, nil
+	}
+	klog.V(4).Infof("OIDC issuer %q has not passed the discovery, keeping the live client secret", oidcProvider.Issuer.URL)
+	return clientSecret, nil
+}
 
+func (c *oauthClientSecretController) syncSecret(ctx context.Context, required *corev1.Secret, recorder events.Recorder) error {
 	secret, err := c.targetNSSecretsLister.Secrets(api.TargetNamespace).Get("console-oauth-config")
-	if apierrors.IsNotFound(err) || secretsub.GetSecretString(secret) != clientSecret {
-		_, _, err = resourceapply.ApplySecret(ctx, c.secretsClient, recorder, secretsub.DefaultSecret(operatorConfig, clientSecret))
+	if apierrors.IsNotFound(err) || !equality.Semantic.DeepEqual(secret.Data, required.Data) || !hasAnnotations(secret, required.Annotations) {
+		_, _, err = resourceapply.ApplySecret(ctx, c.secretsClient, recorder, required)
 	}
 	return err
 }
 
+func hasAnnotations(secret *corev1.Secret, annotations map[string]string) bool {
+	for key, value := range annotations {
+		if existing, ok := secret.Annotations[key]; !ok || existing != value {
+			return false
+		}
+	}
+	return true
+}
+
 // handleStatus returns whether sync should happen and any error encountering
 // determining the operator's management state
 // TODO: extract this logic to where it can be used for all controllers
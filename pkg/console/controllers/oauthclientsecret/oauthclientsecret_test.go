@@ -0,0 +1,67 @@
+package oauthclientsecret
+
+import (
+	"testing"
+
+	"github.com/go-test/deep"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	corev1listers "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+
+	configv1 "github.com/openshift/api/config/v1"
+	operatorv1 "github.com/openshift/api/operator/v1"
+
+	configmapsub "github.com/openshift/console-operator/pkg/console/subresource/configmap"
+	secretsub "github.com/openshift/console-operator/pkg/console/subresource/secret"
+)
+
+func TestOIDCClientSecret(t *testing.T) {
+	operatorConfig := &operatorv1.Console{}
+	oidcProvider := &configv1.OIDCProvider{
+		Issuer: configv1.TokenIssuer{URL: "https://new.example.com"},
+	}
+	live := secretsub.DefaultSecret(operatorConfig, "old")
+
+	tests := []struct {
+		name            string
+		clientSecret    *corev1.Secret
+		verifiedIssuers sets.Set[string]
+		want            *corev1.Secret
+	}{
+		{
+			name:            "Issuer verified",
+			clientSecret:    live,
+			verifiedIssuers: sets.New("https://new.example.com"),
+			want:            secretsub.DefaultSecret(operatorConfig, "new"),
+		},
+		{
+			name:            "Issuer change that fails discovery keeps the live secret",
+			clientSecret:    live,
+			verifiedIssuers: sets.New("https://old.example.com"),
+			want:            live,
+		},
+		{
+			name:            "Issuer that fails discovery on a fresh install",
+			verifiedIssuers: sets.New[string](),
+			want:            secretsub.DefaultSecret(operatorConfig, "new"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			configMapIndexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
+			configMapIndexer.Add(configmapsub.DefaultOIDCDiscoveryConfigMap(operatorConfig, tt.verifiedIssuers))
+			c := &oauthClientSecretController{
+				targetNSConfigMapLister: corev1listers.NewConfigMapLister(configMapIndexer),
+			}
+
+			got, err := c.oidcClientSecret(operatorConfig, oidcProvider, tt.clientSecret, "new")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if diff := deep.Equal(tt.want, got); diff != nil {
+				t.Error(diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,82 @@
+package oauthclientsecret
+
+import (
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+	"github.com/openshift/library-go/pkg/operator/events"
+
+	"github.com/openshift/console-operator/pkg/api"
+	customerrors "github.com/openshift/console-operator/pkg/console/errors"
+	deploymentsub "github.com/openshift/console-operator/pkg/console/subresource/deployment"
+	oauthsub "github.com/openshift/console-operator/pkg/console/subresource/oauthclient"
+	secretsub "github.com/openshift/console-operator/pkg/console/subresource/secret"
+	"github.com/openshift/console-operator/pkg/crypto"
+)
+
+// integratedOAuthSecret works out the console-oauth-config of the integrated OAuth server.
+// The client secret rotates in three steps, on a schedule or on demand:
+//
+//   - a new secret is staged, for the OAuthClient to accept it as an additional secret
+//   - once accepted it becomes the console client secret, and the replaced one is still
+//     accepted while the console rolls out
+//   - once the console pods are available with the new secret, the replaced one is dropped
+//
+// A rotation in flight is returned as a sync error, along with the secret to apply.
+func (c *oauthClientSecretController) integratedOAuthSecret(
+	operatorConfig *operatorv1.Console,
+	clientSecret *corev1.Secret,
+	period time.Duration,
+	recorder events.Recorder,
+) (*corev1.Secret, string, error) {
+	now := time.Now()
+	if clientSecret == nil || len(secretsub.GetSecretString(clientSecret)) == 0 {
+		required := secretsub.DefaultSecret(operatorConfig, crypto.Random256BitsString())
+		secretsub.SetClientSecretCreated(required, now)
+		return required, "", nil
+	}
+
+	required := secretsub.DefaultSecret(operatorConfig, secretsub.GetSecretString(clientSecret))
+	if _, ok := secretsub.ClientSecretCreated(clientSecret); !ok {
+		// secrets from before rotation was around start their period now
+		secretsub.SetClientSecretCreated(required, now)
+	}
+
+	nextSecretString, previousSecretString := secretsub.GetNextSecretString(clientSecret), secretsub.GetPreviousSecretString(clientSecret)
+	switch {
+	case len(nextSecretString) > 0:
+		required.Data[api.NextOAuthClientSecretKey] = []byte(nextSecretString)
+		oauthClient, err := c.oauthClientLister.Get(oauthsub.Stub().Name)
+		if err != nil {
+			return nil, "FailedGetOAuthClient", err
+		}
+		if !oauthsub.AcceptsSecret(oauthClient, nextSecretString) {
+			return required, "RegisteringClientSecret", customerrors.NewSyncError("waiting for the OAuthClient to accept the new client secret")
+		}
+		secretsub.PromoteClientSecret(required, now)
+		recorder.Eventf("OAuthClientSecretRotated", "console client secret rotated, the previous secret is accepted until the console rolls out")
+		return required, "RollingOutClientSecret", customerrors.NewSyncError("rolling the console out with the new client secret")
+
+	case len(previousSecretString) > 0:
+		required.Data[api.PreviousOAuthClientSecretKey] = []byte(previousSecretString)
+		deployment, err := c.targetNSDeploymentsLister.Deployments(api.OpenShiftConsoleNamespace).Get(api.OpenShiftConsoleDeploymentName)
+		if err != nil && !apierrors.IsNotFound(err) {
+			return nil, "FailedGetDeployment", err
+		}
+		// without a console deployment nothing uses the previous secret anymore
+		if deployment != nil && (!deploymentsub.HasOAuthClientSecretContent(deployment, required) || !deploymentsub.IsAvailableAndUpdated(deployment)) {
+			return required, "RollingOutClientSecret", customerrors.NewSyncError("rolling the console out with the new client secret")
+		}
+		delete(required.Data, api.PreviousOAuthClientSecretKey)
+		recorder.Eventf("OAuthClientSecretRotationCompleted", "console rolled out with the new client secret, the previous secret is no longer accepted")
+		return required, "", nil
+
+	case secretsub.IsClientSecretRotationDue(operatorConfig, clientSecret, period, now):
+		secretsub.StageClientSecret(operatorConfig, required, crypto.Random256BitsString())
+		return required, "RegisteringClientSecret", customerrors.NewSyncError("waiting for the OAuthClient to accept the new client secret")
+	}
+	return required, "", nil
+}
@@ -0,0 +1,102 @@
+package oauthclientsecret
+
+import (
+	"testing"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	appsv1listers "k8s.io/client-go/listers/apps/v1"
+	"k8s.io/client-go/tools/cache"
+
+	configv1 "github.com/openshift/api/config/v1"
+	oauthv1 "github.com/openshift/api/oauth/v1"
+	operatorv1 "github.com/openshift/api/operator/v1"
+	oauthv1listers "github.com/openshift/client-go/oauth/listers/oauth/v1"
+	"github.com/openshift/library-go/pkg/operator/events"
+
+	"github.com/openshift/console-operator/pkg/api"
+	customerrors "github.com/openshift/console-operator/pkg/console/errors"
+	deploymentsub "github.com/openshift/console-operator/pkg/console/subresource/deployment"
+	secretsub "github.com/openshift/console-operator/pkg/console/subresource/secret"
+)
+
+// applied merges the required secret into the live one the way ApplySecret does.
+func applied(live, required *corev1.Secret) *corev1.Secret {
+	secret := live.DeepCopy()
+	secret.Data = required.Data
+	for key, value := range required.Annotations {
+		secret.Annotations[key] = value
+	}
+	return secret
+}
+
+func TestIntegratedOAuthSecretRotation(t *testing.T) {
+	operatorConfig := &operatorv1.Console{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        api.ConfigResourceName,
+			Annotations: map[string]string{secretsub.RotateClientSecretAnnotation: "1"},
+		},
+	}
+	oauthClient := &oauthv1.OAuthClient{ObjectMeta: metav1.ObjectMeta{Name: api.OAuthClientName}, Secret: "current"}
+	oauthClientIndexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+	oauthClientIndexer.Add(oauthClient)
+	deploymentIndexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
+	c := &oauthClientSecretController{
+		oauthClientLister:         oauthv1listers.NewOAuthClientLister(oauthClientIndexer),
+		targetNSDeploymentsLister: appsv1listers.NewDeploymentLister(deploymentIndexer),
+	}
+	recorder := events.NewInMemoryRecorder("test")
+
+	live := secretsub.DefaultSecret(operatorConfig, "current")
+	secretsub.SetClientSecretCreated(live, time.Now())
+	consoleDeployment := func(clientSecret *corev1.Secret) *appsv1.Deployment {
+		deployment := deploymentsub.DefaultDeployment(operatorConfig, &corev1.ConfigMap{}, &corev1.ConfigMap{}, nil, nil, &corev1.ConfigMap{}, clientSecret, nil, &configv1.Proxy{}, &configv1.Infrastructure{}, nil, nil, nil, false)
+		deployment.Status = appsv1.DeploymentStatus{Replicas: 2, UpdatedReplicas: 2, AvailableReplicas: 2}
+		return deployment
+	}
+	deploymentIndexer.Add(consoleDeployment(live))
+
+	sync := func(wantReason string) {
+		t.Helper()
+		required, reason, err := c.integratedOAuthSecret(operatorConfig, live, 0, recorder)
+		if err != nil && !customerrors.IsSyncError(err) {
+			t.Fatal(err)
+		}
+		if reason != wantReason {
+			t.Fatalf("reason = %q, want %q", reason, wantReason)
+		}
+		live = applied(live, required)
+	}
+
+	// the on-demand request stages a new secret
+	sync("RegisteringClientSecret")
+	nextSecretString := secretsub.GetNextSecretString(live)
+	if len(nextSecretString) == 0 || secretsub.GetSecretString(live) != "current" {
+		t.Fatalf("expected a staged secret along with the current one, got %v", live.Data)
+	}
+	sync("RegisteringClientSecret")
+
+	// once the OAuthClient accepts it, the console switches to it
+	oauthClient.AdditionalSecrets = []string{nextSecretString}
+	oauthClientIndexer.Update(oauthClient)
+	sync("RollingOutClientSecret")
+	if secretsub.GetSecretString(live) != nextSecretString || secretsub.GetPreviousSecretString(live) != "current" {
+		t.Fatalf("expected the staged secret to replace the current one, got %v", live.Data)
+	}
+	sync("RollingOutClientSecret")
+
+	// the previous secret is dropped once the console rolled out with the new one
+	deploymentIndexer.Update(consoleDeployment(live))
+	sync("")
+	if len(secretsub.GetPreviousSecretString(live)) != 0 {
+		t.Fatalf("expected the previous secret to be dropped, got %v", live.Data)
+	}
+
+	// the same request does not rotate again
+	sync("")
+	if secretsub.GetSecretString(live) != nextSecretString {
+		t.Errorf("client secret rotated twice for the same request")
+	}
+}
@@ -0,0 +1,213 @@
+package oidcsetup
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	configv1 "github.com/openshift/api/config/v1"
+)
+
+const (
+	// OIDCProviderDiscoveryCondition prefixes the condition reporting the discovery
+	// pre-flight of the OIDC providers.
+	OIDCProviderDiscoveryCondition = "OIDCProviderDiscovery"
+
+	discoveryTimeout = 10 * time.Second
+	// responses larger than this are not a discovery document or a key set
+	discoveryMaxBodyBytes = 1 << 20
+)
+
+// providerMetadata holds the fields of the OIDC discovery document the console relies on.
+type providerMetadata struct {
+	Issuer          string   `json:"issuer"`
+	JWKSURI         string   `json:"jwks_uri"`
+	TokenEndpoint   string   `json:"token_endpoint"`
+	ScopesSupported []string `json:"scopes_supported"`
+}
+
+// checkProviderDiscovery fetches the discovery document of the issuer and its key set,
+// checks that the document is for the issuer and supports the extra scopes the console
+// asks for, and that the token endpoint answers.
+func checkProviderDiscovery(ctx context.Context, client *http.Client, issuerURL string, extraScopes []string) error {
+	discoveryURL := strings.TrimSuffix(issuerURL, "/") + "/.well-known/openid-configuration"
+	metadata := &providerMetadata{}
+	if err := getJSON(ctx, client, discoveryURL, metadata); err != nil {
+		return fmt.Errorf("failed to get the discovery document: %w", err)
+	}
+
+	if metadata.Issuer != issuerURL {
+		return fmt.Errorf("discovery document is for the issuer %q, not %q", metadata.Issuer, issuerURL)
+	}
+
+	// scopes_supported is optional in the discovery document
+	if len(metadata.ScopesSupported) > 0 {
+		if unsupported := sets.List(sets.New(extraScopes...).Difference(sets.New(metadata.ScopesSupported...))); len(unsupported) > 0 {
+			return fmt.Errorf("extra scopes %v are not supported by the issuer", unsupported)
+		}
+	}
+
+	if len(metadata.JWKSURI) == 0 {
+		return fmt.Errorf("discovery document has no jwks_uri")
+	}
+	keySet := &struct {
+		Keys []json.RawMessage `json:"keys"`
+	}{}
+	if err := getJSON(ctx, client, metadata.JWKSURI, keySet); err != nil {
+		return fmt.Errorf("failed to get the key set: %w", err)
+	}
+	if len(keySet.Keys) == 0 {
+		return fmt.Errorf("key set %s has no keys", metadata.JWKSURI)
+	}
+
+	if len(metadata.TokenEndpoint) == 0 {
+		return fmt.Errorf("discovery document has no token_endpoint")
+	}
+	// the token endpoint only takes POST requests with a grant, any answer means it is reachable
+	resp, err := doRequest(ctx, client, metadata.TokenEndpoint)
+	if err != nil {
+		return fmt.Errorf("token endpoint is unreachable: %w", err)
+	}
+	resp.Body.Close()
+	return nil
+}
+
+func getJSON(ctx context.Context, client *http.Client, url string, into interface{}) error {
+	resp, err := doRequest(ctx, client, url)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s returned %s", url, resp.Status)
+	}
+	body, err := io.ReadAll(io.LimitReader(resp.Body, discoveryMaxBodyBytes))
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(body, into); err != nil {
+		return fmt.Errorf("failed to decode %s: %w", url, err)
+	}
+	return nil
+}
+
+func doRequest(ctx context.Context, client *http.Client, url string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	return client.Do(req)
+}
+
+// getDiscoveryClient returns the client shared by the pre-flights of the providers with
+// the given CA bundle. All the clients are dropped, closing their connections, when the
+// cluster proxy changes.
+func (c *oidcSetupController) getDiscoveryClient(caBundle string, proxyConfig *configv1.Proxy) (*http.Client, error) {
+	proxyStatus := configv1.ProxyStatus{}
+	if proxyConfig != nil {
+		proxyStatus = proxyConfig.Status
+	}
+	if proxyStatus != c.discoveryProxy {
+		c.pruneDiscoveryClients(sets.New[string]())
+		c.discoveryProxy = proxyStatus
+	}
+	if client, ok := c.discoveryClients[caBundle]; ok {
+		return client, nil
+	}
+	client, err := discoveryClient(caBundle, proxyConfig)
+	if err != nil {
+		return nil, err
+	}
+	if c.discoveryClients == nil {
+		c.discoveryClients = map[string]*http.Client{}
+	}
+	c.discoveryClients[caBundle] = client
+	return client, nil
+}
+
+// pruneDiscoveryClients closes the connections of the clients for the CA bundles no
+// longer in use and drops them.
+func (c *oidcSetupController) pruneDiscoveryClients(caBundles sets.Set[string]) {
+	for caBundle, client := range c.discoveryClients {
+		if !caBundles.Has(caBundle) {
+			client.CloseIdleConnections()
+			delete(c.discoveryClients, caBundle)
+		}
+	}
+}
+
+// discoveryClient trusts the CA bundle configured for the provider, the system roots
+// without one, and goes through the cluster proxy.
+func discoveryClient(caBundle string, proxyConfig *configv1.Proxy) (*http.Client, error) {
+	tlsConfig := &tls.Config{}
+	if len(caBundle) > 0 {
+		roots := x509.NewCertPool()
+		if !roots.AppendCertsFromPEM([]byte(caBundle)) {
+			return nil, fmt.Errorf("no certificate found in the CA bundle")
+		}
+		tlsConfig.RootCAs = roots
+	}
+	return &http.Client{
+		Timeout: discoveryTimeout,
+		Transport: &http.Transport{
+			Proxy:           clusterProxyFunc(proxyConfig),
+			TLSClientConfig: tlsConfig,
+		},
+	}, nil
+}
+
+// clusterProxyFunc picks the proxy of the cluster proxy config for a request, following
+// the rules of its noProxy list: hosts, domain suffixes, IPs and CIDRs, or "*".
+func clusterProxyFunc(proxyConfig *configv1.Proxy) func(*http.Request) (*url.URL, error) {
+	return func(req *http.Request) (*url.URL, error) {
+		if proxyConfig == nil {
+			return nil, nil
+		}
+		proxy := proxyConfig.Status.HTTPProxy
+		if req.URL.Scheme == "https" {
+			proxy = proxyConfig.Status.HTTPSProxy
+		}
+		if len(proxy) == 0 || isNoProxy(req.URL.Hostname(), proxyConfig.Status.NoProxy) {
+			return nil, nil
+		}
+		return url.Parse(proxy)
+	}
+}
+
+func isNoProxy(host, noProxy string) bool {
+	hostIP := net.ParseIP(host)
+	for _, entry := range strings.Split(noProxy, ",") {
+		entry = strings.ToLower(strings.TrimSpace(entry))
+		switch {
+		case len(entry) == 0:
+			continue
+		case entry == "*":
+			return true
+		case hostIP != nil:
+			if _, cidr, err := net.ParseCIDR(entry); err == nil && cidr.Contains(hostIP) {
+				return true
+			}
+			if entryIP := net.ParseIP(entry); entryIP != nil && entryIP.Equal(hostIP) {
+				return true
+			}
+		default:
+			host = strings.ToLower(host)
+			domain := strings.TrimPrefix(entry, ".")
+			if host == domain || strings.HasSuffix(host, "."+domain) {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,194 @@
+package oidcsetup
+
+import (
+	"context"
+	"encoding/json"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	configv1 "github.com/openshift/api/config/v1"
+)
+
+// oidcStub serves the discovery document and key set of an OIDC provider.
+type oidcStub struct {
+	issuer          string
+	scopesSupported []string
+	keys            []string
+}
+
+func (s *oidcStub) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch r.URL.Path {
+	case "/.well-known/openid-configuration":
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"issuer":           s.issuer,
+			"jwks_uri":         "https://" + r.Host + "/keys",
+			"token_endpoint":   "https://" + r.Host + "/token",
+			"scopes_supported": s.scopesSupported,
+		})
+	case "/keys":
+		keys := []json.RawMessage{}
+		for _, kid := range s.keys {
+			keys = append(keys, json.RawMessage(`{"kty":"RSA","kid":"`+kid+`"}`))
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{"keys": keys})
+	case "/token":
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func TestCheckProviderDiscovery(t *testing.T) {
+	tests := []struct {
+		name        string
+		stub        oidcStub
+		issuerURL   string
+		extraScopes []string
+		untrusted   bool
+		wantErr     string
+	}{
+		{
+			name:        "Valid provider",
+			stub:        oidcStub{scopesSupported: []string{"openid", "email", "groups"}, keys: []string{"key-1"}},
+			extraScopes: []string{"email", "groups"},
+		},
+		{
+			name: "Provider without scopes_supported",
+			stub: oidcStub{keys: []string{"key-1"}},
+		},
+		{
+			name:    "Issuer mismatch",
+			stub:    oidcStub{issuer: "https://sso.example.com", keys: []string{"key-1"}},
+			wantErr: "is for the issuer \"https://sso.example.com\"",
+		},
+		{
+			name:        "Unsupported extra scope",
+			stub:        oidcStub{scopesSupported: []string{"openid", "email"}, keys: []string{"key-1"}},
+			extraScopes: []string{"email", "groups"},
+			wantErr:     "[groups] are not supported",
+		},
+		{
+			name:    "Empty key set",
+			stub:    oidcStub{},
+			wantErr: "has no keys",
+		},
+		{
+			name:      "Untrusted issuer certificate",
+			stub:      oidcStub{keys: []string{"key-1"}},
+			untrusted: true,
+			wantErr:   "certificate",
+		},
+		{
+			name:      "Unknown issuer",
+			stub:      oidcStub{keys: []string{"key-1"}},
+			issuerURL: "/realms/unknown",
+			wantErr:   "404",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stub := tt.stub
+			server := httptest.NewTLSServer(&stub)
+			defer server.Close()
+			issuerURL := server.URL + tt.issuerURL
+			if len(stub.issuer) == 0 {
+				stub.issuer = issuerURL
+			}
+
+			caBundle := string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw}))
+			if tt.untrusted {
+				caBundle = ""
+			}
+			client, err := discoveryClient(caBundle, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			err = checkProviderDiscovery(context.TODO(), client, issuerURL, tt.extraScopes)
+			if len(tt.wantErr) == 0 && err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+			if len(tt.wantErr) != 0 && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+				t.Errorf("error = %v, want one containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestClusterProxyFunc(t *testing.T) {
+	proxyConfig := &configv1.Proxy{
+		Status: configv1.ProxyStatus{
+			HTTPSProxy: "http://proxy.example.com:3128",
+			NoProxy:    ".cluster.local,sso.internal.example.com,10.0.0.0/16,192.168.1.10",
+		},
+	}
+	tests := []struct {
+		url       string
+		wantProxy bool
+	}{
+		{url: "https://sso.example.com", wantProxy: true},
+		{url: "https://sso.partner.com/realms/corp", wantProxy: true},
+		{url: "https://keycloak.sso.svc.cluster.local"},
+		{url: "https://sso.internal.example.com:8443"},
+		{url: "https://10.0.12.1"},
+		{url: "https://10.1.0.1", wantProxy: true},
+		{url: "https://192.168.1.10"},
+		// no HTTP proxy configured
+		{url: "http://sso.partner.com"},
+	}
+	proxyFunc := clusterProxyFunc(proxyConfig)
+	for _, tt := range tests {
+		t.Run(tt.url, func(t *testing.T) {
+			req, err := http.NewRequest(http.MethodGet, tt.url, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			proxyURL, err := proxyFunc(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if (proxyURL != nil) != tt.wantProxy {
+				t.Errorf("proxy = %v, want a proxy %v", proxyURL, tt.wantProxy)
+			}
+		})
+	}
+}
+
+func TestGetDiscoveryClient(t *testing.T) {
+	server := httptest.NewTLSServer(http.NotFoundHandler())
+	defer server.Close()
+	caBundle := string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw}))
+
+	c := &oidcSetupController{}
+	if _, err := c.getDiscoveryClient("not a certificate", nil); err == nil {
+		t.Error("expected an error for an invalid CA bundle")
+	}
+	client, err := c.getDiscoveryClient(caBundle, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sameClient, _ := c.getDiscoveryClient(caBundle, nil); sameClient != client {
+		t.Error("expected the client to be reused for the same CA bundle")
+	}
+	if systemRootsClient, _ := c.getDiscoveryClient("", nil); systemRootsClient == client {
+		t.Error("expected another client for another CA bundle")
+	}
+
+	proxyConfig := &configv1.Proxy{Status: configv1.ProxyStatus{HTTPSProxy: "http://proxy.example.com:3128"}}
+	if proxiedClient, _ := c.getDiscoveryClient(caBundle, proxyConfig); proxiedClient == client {
+		t.Error("expected a new client when the cluster proxy changes")
+	}
+	if len(c.discoveryClients) != 1 {
+		t.Errorf("expected the clients of the previous proxy to be dropped, got %d clients", len(c.discoveryClients))
+	}
+
+	c.pruneDiscoveryClients(sets.New[string]())
+	if len(c.discoveryClients) != 0 {
+		t.Errorf("expected unused clients to be pruned, got %d clients", len(c.discoveryClients))
+	}
+}
@@ -3,11 +3,14 @@ package oidcsetup
 import (
 	"context"
 	"fmt"
+	"net/http"
 	"time"
 
 	configv1client "github.com/openshift/client-go/config/clientset/versioned/typed/config/v1"
 	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
 	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/apimachinery/pkg/util/wait"
 	appsv1informers "k8s.io/client-go/informers/apps/v1"
@@ -24,6 +27,7 @@ import (
 	operatorv1informers "github.com/openshift/client-go/operator/informers/externalversions/operator/v1"
 	operatorv1listers "github.com/openshift/client-go/operator/listers/operator/v1"
 	"github.com/openshift/console-operator/pkg/api"
+	"github.com/openshift/console-operator/pkg/console/metrics"
 	"github.com/openshift/console-operator/pkg/console/status"
 	"github.com/openshift/library-go/pkg/controller/factory"
 	"github.com/openshift/library-go/pkg/operator/events"
@@ -31,6 +35,7 @@ import (
 	"github.com/openshift/library-go/pkg/operator/v1helpers"
 
 	authnsub "github.com/openshift/console-operator/pkg/console/subresource/authentication"
+	configmapsub "github.com/openshift/console-operator/pkg/console/subresource/configmap"
 	deploymentsub "github.com/openshift/console-operator/pkg/console/subresource/deployment"
 	utilsub "github.com/openshift/console-operator/pkg/console/subresource/util"
 )
@@ -49,13 +54,17 @@ import (
 //	- consoles.operator.openshift.io/cluster .status.conditions:
 //		- type=OIDCClientConfigProgressing
 //		- type=OIDCClientConfigDegraded
+//		- type=OIDCProviderDiscoveryDegraded
 //		- type=AuthStatusHandlerProgressing
 //		- type=AuthStatusHandlerDegraded
+//	- configmaps/console-oidc-discovery in openshift-console, listing the OIDC issuers that
+//	  passed the discovery pre-flight
 type oidcSetupController struct {
 	operatorClient  v1helpers.OperatorClient
 	configMapClient corev1client.ConfigMapsGetter
 
 	authnLister               configv1listers.AuthenticationLister
+	proxyLister               configv1listers.ProxyLister
 	consoleOperatorLister     operatorv1listers.ConsoleLister
 	configConfigMapLister     corev1listers.ConfigMapLister
 	targetNSSecretsLister     corev1listers.SecretLister
@@ -65,6 +74,11 @@ type oidcSetupController struct {
 	externalOIDCFeatureEnabled bool
 
 	authStatusHandler *status.AuthStatusHandler
+
+	// discoveryClients are shared by the discovery pre-flights, keyed by CA bundle, and
+	// rebuilt when the cluster proxy changes
+	discoveryClients map[string]*http.Client
+	discoveryProxy   configv1.ProxyStatus
 }
 
 func NewOIDCSetupController(
@@ -72,6 +86,7 @@ func NewOIDCSetupController(
 	configMapClient corev1client.ConfigMapsGetter,
 	authnInformer configv1informers.AuthenticationInformer,
 	authenticationClient configv1client.AuthenticationInterface,
+	proxyInformer configv1informers.ProxyInformer,
 	consoleOperatorInformer operatorv1informers.ConsoleInformer,
 	configConfigMapInformer corev1informers.ConfigMapInformer,
 	targetNSsecretsInformer corev1informers.SecretInformer,
@@ -85,6 +100,7 @@ func NewOIDCSetupController(
 		configMapClient: configMapClient,
 
 		authnLister:               authnInformer.Lister(),
+		proxyLister:               proxyInformer.Lister(),
 		consoleOperatorLister:     consoleOperatorInformer.Lister(),
 		configConfigMapLister:     configConfigMapInformer.Lister(),
 		targetNSSecretsLister:     targetNSsecretsInformer.Lister(),
@@ -96,10 +112,11 @@ func NewOIDCSetupController(
 		authStatusHandler: status.NewAuthStatusHandler(authenticationClient, api.OpenShiftConsoleName, api.TargetNamespace, api.OpenShiftConsoleOperator),
 	}
 	return factory.New().
-		WithSync(c.sync).
+		WithSync(metrics.InstrumentSync("OIDCSetupController", c.sync)).
 		ResyncEvery(wait.Jitter(time.Minute, 1.0)).
 		WithInformers(
 			authnInformer.Informer(),
+			proxyInformer.Informer(),
 			configConfigMapInformer.Informer(),
 			consoleOperatorInformer.Informer(),
 			targetNSsecretsInformer.Informer(),
@@ -124,6 +141,7 @@ func (c *oidcSetupController) sync(ctx context.Context, syncCtx factory.SyncCont
 		// reset all conditions set by this controller
 		statusHandler.AddConditions(status.HandleProgressingOrDegraded("OIDCClientConfig", "", nil))
 		statusHandler.AddConditions(status.HandleProgressingOrDegraded("AuthStatusHandler", "", nil))
+		statusHandler.AddCondition(status.HandleDegraded(OIDCProviderDiscoveryCondition, "", nil))
 		return statusHandler.FlushAndReturn(nil)
 	}
 
@@ -151,15 +169,39 @@ func (c *oidcSetupController) sync(ctx context.Context, syncCtx factory.SyncCont
 		applyErr := c.authStatusHandler.Apply(ctx, authnConfig)
 		statusHandler.AddConditions(status.HandleProgressingOrDegraded("AuthStatusHandler", "FailedApply", applyErr))
 
-		// reset the other condition set by this controller
+		// issuers verified before must go through the discovery again when OIDC comes back
+		deleteErr := c.removeVerifiedIssuers(ctx)
+		statusHandler.AddCondition(status.HandleDegraded(OIDCProviderDiscoveryCondition, "FailedDelete", deleteErr))
+
+		// reset the other conditions set by this controller
 		statusHandler.AddConditions(status.HandleProgressingOrDegraded("OIDCClientConfig", "", nil))
-		return statusHandler.FlushAndReturn(applyErr)
+		return statusHandler.FlushAndReturn(utilerrors.NewAggregate([]error{applyErr, deleteErr}))
 	}
 
 	// we need to keep track of errors during the sync so that we can requeue
 	// if any occur
 	var errs []error
-	syncErr := c.syncAuthTypeOIDC(ctx, authnConfig, operatorConfig, syncCtx.Recorder())
+	// the operator keeps the auth of the live console-config, the client secret and the CA
+	// the console deployment mounts until the issuer they are changed to is listed as verified
+	verifiedIssuers, discoveryErr := c.verifyProviderDiscovery(ctx, authnConfig)
+	discoveryReason, discoveryConditionErr := "DiscoveryFailed", discoveryErr
+	// the console deployment mounts the provider's CA copy once the issuer is listed as
+	// verified, so the copy is only updated before that
+	if discoveryErr == nil {
+		if caErr := c.syncProviderCA(ctx, authnConfig, operatorConfig, syncCtx.Recorder()); caErr != nil {
+			verifiedIssuers = sets.New[string]()
+			discoveryErr = caErr
+			discoveryReason, discoveryConditionErr = "FailedSyncCA", caErr
+			errs = append(errs, caErr)
+		}
+	}
+	if _, _, applyErr := resourceapply.ApplyConfigMap(ctx, c.configMapClient, syncCtx.Recorder(), configmapsub.DefaultOIDCDiscoveryConfigMap(operatorConfig, verifiedIssuers)); applyErr != nil {
+		discoveryReason, discoveryConditionErr = "FailedApply", utilerrors.NewAggregate([]error{discoveryErr, applyErr})
+		errs = append(errs, applyErr)
+	}
+	statusHandler.AddCondition(status.HandleDegraded(OIDCProviderDiscoveryCondition, discoveryReason, discoveryConditionErr))
+
+	syncErr := c.syncAuthTypeOIDC(authnConfig, discoveryErr)
 	statusHandler.AddConditions(
 		status.HandleProgressingOrDegraded(
 			"OIDCClientConfig", "OIDCConfigSyncFailed",
@@ -182,8 +224,8 @@ func (c *oidcSetupController) sync(ctx context.Context, syncCtx factory.SyncCont
 	return statusHandler.FlushAndReturn(nil)
 }
 
-func (c *oidcSetupController) syncAuthTypeOIDC(ctx context.Context, authnConfig *configv1.Authentication, operatorConfig *operatorv1.Console, recorder events.Recorder) error {
-	oidcProvider, clientConfig := authnsub.GetOIDCClientConfig(authnConfig, api.TargetNamespace, api.OpenShiftConsoleName)
+func (c *oidcSetupController) syncAuthTypeOIDC(authnConfig *configv1.Authentication, discoveryErr error) error {
+	_, clientConfig := authnsub.GetOIDCClientConfig(authnConfig, api.TargetNamespace, api.OpenShiftConsoleName)
 	if clientConfig == nil {
 		c.authStatusHandler.WithCurrentOIDCClient("")
 		c.authStatusHandler.Unavailable("OIDCClientConfig", "no OIDC client found")
@@ -206,22 +248,9 @@ func (c *oidcSetupController) syncAuthTypeOIDC(ctx context.Context, authnConfig
 		return err
 	}
 
-	if caCMName := oidcProvider.Issuer.CertificateAuthority.Name; len(caCMName) > 0 {
-		caCM, err := c.configConfigMapLister.ConfigMaps(api.OpenShiftConfigNamespace).Get(caCMName)
-		if err != nil {
-			return fmt.Errorf("failed to get the CA configMap %q configured for the OIDC provider %q: %w", caCMName, oidcProvider.Name, err)
-		}
-
-		_, _, err = resourceapply.SyncPartialConfigMap(ctx,
-			c.configMapClient,
-			recorder,
-			caCM.Namespace, caCM.Name,
-			api.TargetNamespace, caCM.Name,
-			sets.New[string]("ca-bundle.crt"),
-			[]metav1.OwnerReference{*utilsub.OwnerRefFrom(operatorConfig)})
-		if err != nil {
-			return fmt.Errorf("failed to sync the provider's CA configMap: %w", err)
-		}
+	if discoveryErr != nil {
+		c.authStatusHandler.Degraded("OIDCDiscoveryFailed", discoveryErr.Error())
+		return nil
 	}
 
 	if valid, msg, err := c.checkClientConfigStatus(authnConfig, clientSecret); err != nil {
@@ -237,9 +266,95 @@ func (c *oidcSetupController) syncAuthTypeOIDC(ctx context.Context, authnConfig
 	return nil
 }
 
+// syncProviderCA copies the CA of the OIDC provider the console has a client on to the
+// target namespace.
+func (c *oidcSetupController) syncProviderCA(ctx context.Context, authnConfig *configv1.Authentication, operatorConfig *operatorv1.Console, recorder events.Recorder) error {
+	oidcProvider, clientConfig := authnsub.GetOIDCClientConfig(authnConfig, api.TargetNamespace, api.OpenShiftConsoleName)
+	if clientConfig == nil {
+		return nil
+	}
+	caCMName := oidcProvider.Issuer.CertificateAuthority.Name
+	if len(caCMName) == 0 {
+		return nil
+	}
+
+	caCM, err := c.configConfigMapLister.ConfigMaps(api.OpenShiftConfigNamespace).Get(caCMName)
+	if err != nil {
+		return fmt.Errorf("failed to get the CA configMap %q configured for the OIDC provider %q: %w", caCMName, oidcProvider.Name, err)
+	}
+
+	_, _, err = resourceapply.SyncPartialConfigMap(ctx,
+		c.configMapClient,
+		recorder,
+		caCM.Namespace, caCM.Name,
+		api.TargetNamespace, caCM.Name,
+		sets.New[string]("ca-bundle.crt"),
+		[]metav1.OwnerReference{*utilsub.OwnerRefFrom(operatorConfig)})
+	if err != nil {
+		return fmt.Errorf("failed to sync the provider's CA configMap: %w", err)
+	}
+	return nil
+}
+
+// removeVerifiedIssuers removes the config map listing the OIDC issuers that passed the
+// discovery pre-flight.
+func (c *oidcSetupController) removeVerifiedIssuers(ctx context.Context) error {
+	_, err := c.targetNSConfigMapLister.ConfigMaps(api.TargetNamespace).Get(api.ConsoleOIDCDiscoveryName)
+	if apierrors.IsNotFound(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	err = c.configMapClient.ConfigMaps(api.TargetNamespace).Delete(ctx, api.ConsoleOIDCDiscoveryName, metav1.DeleteOptions{})
+	if apierrors.IsNotFound(err) {
+		return nil
+	}
+	return err
+}
+
+// verifyProviderDiscovery runs the discovery pre-flight against the OIDC provider the
+// console has a client on, and returns its issuer when it passed it.
+func (c *oidcSetupController) verifyProviderDiscovery(ctx context.Context, authnConfig *configv1.Authentication) (sets.Set[string], error) {
+	verifiedIssuers := sets.New[string]()
+	oidcProvider, clientConfig := authnsub.GetOIDCClientConfig(authnConfig, api.TargetNamespace, api.OpenShiftConsoleName)
+	if clientConfig == nil {
+		c.pruneDiscoveryClients(sets.New[string]())
+		return verifiedIssuers, nil
+	}
+
+	proxyConfig, err := c.proxyLister.Get(api.ConfigResourceName)
+	if err != nil && !apierrors.IsNotFound(err) {
+		return verifiedIssuers, err
+	}
+	if apierrors.IsNotFound(err) {
+		proxyConfig = nil
+	}
+
+	var caBundle string
+	if caCMName := oidcProvider.Issuer.CertificateAuthority.Name; len(caCMName) > 0 {
+		caCM, err := c.configConfigMapLister.ConfigMaps(api.OpenShiftConfigNamespace).Get(caCMName)
+		if err != nil {
+			return verifiedIssuers, fmt.Errorf("failed to get the CA configMap %q configured for the OIDC provider %q: %w", caCMName, oidcProvider.Name, err)
+		}
+		caBundle = caCM.Data["ca-bundle.crt"]
+	}
+
+	c.pruneDiscoveryClients(sets.New(caBundle))
+	client, err := c.getDiscoveryClient(caBundle, proxyConfig)
+	if err == nil {
+		err = checkProviderDiscovery(ctx, client, oidcProvider.Issuer.URL, clientConfig.ExtraScopes)
+	}
+	if err != nil {
+		return verifiedIssuers, fmt.Errorf("OIDC provider %q: %w", oidcProvider.Name, err)
+	}
+	verifiedIssuers.Insert(oidcProvider.Issuer.URL)
+	return verifiedIssuers, nil
+}
+
 // checkClientConfigStatus checks whether the current client configuration is being currently in use,
 // by looking at the deployment status. It checks whether the deployment is available and updated,
-// and also whether the resource versions for the oauth secret and server CA trust configmap match
+// and also whether the content of the oauth secret and server CA trust configmap match
 // the deployment.
 func (c *oidcSetupController) checkClientConfigStatus(authnConfig *configv1.Authentication, clientSecret *corev1.Secret) (bool, string, error) {
 	depl, err := c.targetNSDeploymentsLister.Deployments(api.OpenShiftConsoleNamespace).Get(api.OpenShiftConsoleDeploymentName)
@@ -252,7 +367,7 @@ func (c *oidcSetupController) checkClientConfigStatus(authnConfig *configv1.Auth
 		return false, "deployment unavailable or outdated", nil
 	}
 
-	if clientSecret.GetResourceVersion() != depl.ObjectMeta.Annotations["console.openshift.io/oauth-secret-version"] {
+	if !deploymentsub.HasOAuthClientSecretContent(depl, clientSecret) {
 		return false, "client secret version not up to date in current deployment", nil
 	}
 
@@ -267,7 +382,7 @@ func (c *oidcSetupController) checkClientConfigStatus(authnConfig *configv1.Auth
 			return false, "", err
 		}
 
-		if serverCAConfig.GetResourceVersion() != depl.ObjectMeta.Annotations["console.openshift.io/authn-ca-trust-config-version"] {
+		if !deploymentsub.HasAuthnCATrustContent(depl, serverCAConfig) {
 			return false, "OIDC provider CA version not up to date in current deployment", nil
 		}
 	}
@@ -3,6 +3,7 @@ package pdb
 import (
 	"context"
 	"fmt"
+	"strings"
 	"time"
 
 	// k8s
@@ -18,6 +19,7 @@ import (
 	operatorv1informers "github.com/openshift/client-go/operator/informers/externalversions/operator/v1"
 	operatorv1listers "github.com/openshift/client-go/operator/listers/operator/v1"
 	"github.com/openshift/console-operator/bindata"
+	"github.com/openshift/console-operator/pkg/console/metrics"
 	"github.com/openshift/console-operator/pkg/console/status"
 	"github.com/openshift/library-go/pkg/controller/factory"
 	"github.com/openshift/library-go/pkg/operator/resource/resourceapply"
@@ -57,6 +59,7 @@ func NewPodDisruptionBudgetController(
 		operatorConfigLister: operatorConfigInformer.Lister(),
 		pdbClient:            pdbClient,
 	}
+	controllerName := fmt.Sprintf("%sPodDisruptionBudgetController", strings.Title(pdbName))
 
 	return factory.New().
 		WithFilteredEventsInformers(
@@ -67,8 +70,8 @@ func NewPodDisruptionBudgetController(
 			util.IncludeNamesFilter(api.ConfigResourceName),
 			operatorConfigInformer.Informer(),
 		).
-		ResyncEvery(time.Minute).WithSync(ctrl.Sync).
-		ToController("PodDisruptionBudgetController", recorder.WithComponentSuffix(fmt.Sprintf("%s-pdb-controller", pdbName)))
+		ResyncEvery(time.Minute).WithSync(metrics.InstrumentSync(controllerName, ctrl.Sync)).
+		ToController(controllerName, recorder.WithComponentSuffix(fmt.Sprintf("%s-pdb-controller", pdbName)))
 }
 
 func (c *PodDisruptionBudgetController) Sync(ctx context.Context, controllerContext factory.SyncContext) error {
@@ -96,7 +99,7 @@ func (c *PodDisruptionBudgetController) Sync(ctx context.Context, controllerCont
 
 	statusHandler := status.NewStatusHandler(c.operatorClient)
 
-	requiredPDB := c.getDefaultPodDisruptionBudget()
+	requiredPDB := DefaultPodDisruptionBudget(c.pdbName)
 	_, _, pdbErr := resourceapply.ApplyPodDisruptionBudget(ctx, c.pdbClient, controllerContext.Recorder(), requiredPDB)
 	statusHandler.AddConditions(status.HandleProgressingOrDegraded("PDBSync", "FailedApply", pdbErr))
 	if pdbErr != nil {
@@ -115,7 +118,7 @@ func (c *PodDisruptionBudgetController) removePodDisruptionBudget(ctx context.Co
 }
 
 // Load manifests and create the PDBs
-func (c *PodDisruptionBudgetController) getDefaultPodDisruptionBudget() *v1.PodDisruptionBudget {
-	pdb := resourceread.ReadPodDisruptionBudgetV1OrDie(bindata.MustAsset(fmt.Sprintf("assets/pdb/%s-pdb.yaml", c.pdbName)))
+func DefaultPodDisruptionBudget(pdbName string) *v1.PodDisruptionBudget {
+	pdb := resourceread.ReadPodDisruptionBudgetV1OrDie(bindata.MustAsset(fmt.Sprintf("assets/pdb/%s-pdb.yaml", pdbName)))
 	return pdb
 }
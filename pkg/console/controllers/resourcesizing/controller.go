@@ -0,0 +1,156 @@
+package resourcesizing
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	// k8s
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	coreinformersv1 "k8s.io/client-go/informers/core/v1"
+	coreclientv1 "k8s.io/client-go/kubernetes/typed/core/v1"
+	corev1listers "k8s.io/client-go/listers/core/v1"
+	"k8s.io/klog/v2"
+
+	// openshift
+	operatorsv1 "github.com/openshift/api/operator/v1"
+	operatorv1informers "github.com/openshift/client-go/operator/informers/externalversions/operator/v1"
+	operatorv1listers "github.com/openshift/client-go/operator/listers/operator/v1"
+	"github.com/openshift/library-go/pkg/controller/factory"
+	"github.com/openshift/library-go/pkg/operator/events"
+	"github.com/openshift/library-go/pkg/operator/resource/resourceapply"
+	"github.com/openshift/library-go/pkg/operator/v1helpers"
+
+	// console-operator
+	"github.com/openshift/console-operator/pkg/api"
+	"github.com/openshift/console-operator/pkg/console/controllers/util"
+	"github.com/openshift/console-operator/pkg/console/metrics"
+	"github.com/openshift/console-operator/pkg/console/status"
+	deploymentsub "github.com/openshift/console-operator/pkg/console/subresource/deployment"
+)
+
+// consolePodsSelector matches both the console and the downloads pods, their containers
+// have distinct names.
+const consolePodsSelector = "app=console"
+
+// ResourceSizingController derives the requests of the console and download-server
+// containers from the usage of their pods when the auto-sizing is enabled. The
+// recommended requests are stored in the resource sizing config map the deployments
+// are built from.
+type ResourceSizingController struct {
+	operatorClient       v1helpers.OperatorClient
+	operatorConfigLister operatorv1listers.ConsoleLister
+	configMapClient      coreclientv1.ConfigMapsGetter
+	configMapLister      corev1listers.ConfigMapLister
+	usageSource          PodUsageSource
+}
+
+func NewResourceSizingController(
+	// clients
+	operatorClient v1helpers.OperatorClient,
+	configMapClient coreclientv1.ConfigMapsGetter,
+	usageSource PodUsageSource,
+	// informers
+	operatorConfigInformer operatorv1informers.ConsoleInformer,
+	configMapInformer coreinformersv1.ConfigMapInformer,
+	// events
+	recorder events.Recorder,
+) factory.Controller {
+	ctrl := &ResourceSizingController{
+		operatorClient:       operatorClient,
+		operatorConfigLister: operatorConfigInformer.Lister(),
+		configMapClient:      configMapClient,
+		configMapLister:      configMapInformer.Lister(),
+		usageSource:          usageSource,
+	}
+
+	return factory.New().
+		WithFilteredEventsInformers( // configs
+			util.IncludeNamesFilter(api.ConfigResourceName),
+			operatorConfigInformer.Informer(),
+		).WithFilteredEventsInformers( // recommendations
+		util.IncludeNamesFilter(api.ResourceSizingConfigMapName),
+		configMapInformer.Informer(),
+	).ResyncEvery(5*time.Minute).WithSync(metrics.InstrumentSync("ConsoleResourceSizingController", ctrl.Sync)).
+		ToController("ConsoleResourceSizingController", recorder.WithComponentSuffix("console-resource-sizing-controller"))
+}
+
+func (c *ResourceSizingController) Sync(ctx context.Context, controllerContext factory.SyncContext) error {
+	operatorConfig, err := c.operatorConfigLister.Get(api.ConfigResourceName)
+	if err != nil {
+		return err
+	}
+	operatorConfigCopy := operatorConfig.DeepCopy()
+
+	switch operatorConfigCopy.Spec.ManagementState {
+	case operatorsv1.Managed:
+		klog.V(4).Infoln("console is in a managed state: syncing resource sizing")
+	case operatorsv1.Unmanaged:
+		klog.V(4).Infoln("console is in an unmanaged state: skipping resource sizing sync")
+		return nil
+	case operatorsv1.Removed:
+		klog.V(4).Infoln("console is in a removed state: removing resource sizing")
+		return c.removeResourceSizingConfigMap(ctx)
+	default:
+		return fmt.Errorf("unknown state: %v", operatorConfigCopy.Spec.ManagementState)
+	}
+
+	statusHandler := status.NewStatusHandler(c.operatorClient)
+
+	// the auto-sizing is rejected while the console is autoscaled
+	conflictErr := deploymentsub.ResourceAutoSizingConflict(operatorConfigCopy)
+	statusHandler.AddCondition(status.HandleDegraded("ResourceAutoSizing", "ConflictsWithAutoscaling", conflictErr))
+	if !deploymentsub.IsResourceAutoSizingEnabled(operatorConfigCopy) || conflictErr != nil {
+		statusHandler.AddCondition(status.HandleWarning("ResourceAutoSizing", "", nil))
+		removeErr := c.removeResourceSizingConfigMap(ctx)
+		statusHandler.AddConditions(status.HandleProgressingOrDegraded("ResourceSizingSync", "FailedDelete", removeErr))
+		return statusHandler.FlushAndReturn(removeErr)
+	}
+
+	existing, err := c.configMapLister.ConfigMaps(api.OpenShiftConsoleNamespace).Get(api.ResourceSizingConfigMapName)
+	if err != nil && !apierrors.IsNotFound(err) {
+		return statusHandler.FlushAndReturn(err)
+	}
+	current := deploymentsub.GetResourceRecommendations(existing)
+
+	// without usage the deployments keep the requests recommended so far
+	usage, usageErr := c.usageSource.GetContainerUsage(ctx, api.OpenShiftConsoleNamespace, consolePodsSelector)
+	statusHandler.AddCondition(status.HandleWarning("ResourceAutoSizing", "FailedGetPodMetrics", usageErr))
+	if usageErr != nil {
+		return statusHandler.FlushAndReturn(nil)
+	}
+
+	required, err := deploymentsub.DefaultResourceSizingConfigMap(operatorConfigCopy, RecommendContainerRequests(usage, current))
+	if err != nil {
+		return statusHandler.FlushAndReturn(err)
+	}
+	_, _, applyErr := resourceapply.ApplyConfigMap(ctx, c.configMapClient, controllerContext.Recorder(), required)
+	statusHandler.AddConditions(status.HandleProgressingOrDegraded("ResourceSizingSync", "FailedApply", applyErr))
+	return statusHandler.FlushAndReturn(applyErr)
+}
+
+// RecommendContainerRequests recommends the requests of every sized container with
+// usage, the containers without usage keep their current recommendation.
+func RecommendContainerRequests(usage map[string][]corev1.ResourceList, current map[string]corev1.ResourceList) map[string]corev1.ResourceList {
+	recommendations := map[string]corev1.ResourceList{}
+	for containerName, defaults := range deploymentsub.DefaultContainerRequests() {
+		if len(usage[containerName]) == 0 {
+			if recommendation, ok := current[containerName]; ok {
+				recommendations[containerName] = recommendation
+			}
+			continue
+		}
+		recommendations[containerName] = deploymentsub.RecommendRequests(usage[containerName], defaults, current[containerName])
+	}
+	return recommendations
+}
+
+func (c *ResourceSizingController) removeResourceSizingConfigMap(ctx context.Context) error {
+	err := c.configMapClient.ConfigMaps(api.OpenShiftConsoleNamespace).Delete(ctx, api.ResourceSizingConfigMapName, metav1.DeleteOptions{})
+	if apierrors.IsNotFound(err) {
+		return nil
+	}
+	return err
+}
@@ -0,0 +1,63 @@
+package resourcesizing
+
+import (
+	"context"
+	"fmt"
+
+	// k8s
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+)
+
+var PodMetricsResource = schema.GroupVersionResource{Group: "metrics.k8s.io", Version: "v1beta1", Resource: "pods"}
+
+// PodUsageSource reports the current usage of the containers of the pods matching a
+// label selector, per container name.
+type PodUsageSource interface {
+	GetContainerUsage(ctx context.Context, namespace string, selector string) (map[string][]corev1.ResourceList, error)
+}
+
+// metricsAPIUsageSource reads the usage from the PodMetrics served by metrics.k8s.io.
+type metricsAPIUsageSource struct {
+	dynamicClient dynamic.Interface
+}
+
+func NewMetricsAPIUsageSource(dynamicClient dynamic.Interface) PodUsageSource {
+	return &metricsAPIUsageSource{dynamicClient: dynamicClient}
+}
+
+func (s *metricsAPIUsageSource) GetContainerUsage(ctx context.Context, namespace string, selector string) (map[string][]corev1.ResourceList, error) {
+	podMetricsList, err := s.dynamicClient.Resource(PodMetricsResource).Namespace(namespace).List(ctx, metav1.ListOptions{LabelSelector: selector})
+	if err != nil {
+		return nil, err
+	}
+	usage := map[string][]corev1.ResourceList{}
+	for _, podMetrics := range podMetricsList.Items {
+		containers, _, err := unstructured.NestedSlice(podMetrics.Object, "containers")
+		if err != nil {
+			return nil, fmt.Errorf("invalid pod metrics %s: %w", podMetrics.GetName(), err)
+		}
+		for _, container := range containers {
+			containerMap, ok := container.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			name, _, _ := unstructured.NestedString(containerMap, "name")
+			containerUsage, _, _ := unstructured.NestedStringMap(containerMap, "usage")
+			resources := corev1.ResourceList{}
+			for resourceName, value := range containerUsage {
+				quantity, err := resource.ParseQuantity(value)
+				if err != nil {
+					return nil, fmt.Errorf("invalid %s usage of pod %s: %w", resourceName, podMetrics.GetName(), err)
+				}
+				resources[corev1.ResourceName(resourceName)] = quantity
+			}
+			usage[name] = append(usage[name], resources)
+		}
+	}
+	return usage, nil
+}
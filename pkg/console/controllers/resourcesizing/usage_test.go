@@ -0,0 +1,73 @@
+package resourcesizing
+
+import (
+	"context"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+
+	"github.com/openshift/console-operator/pkg/api"
+)
+
+func podMetrics(name string, component string, containerName string, cpu string, memory string) *unstructured.Unstructured {
+	return &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "metrics.k8s.io/v1beta1",
+		"kind":       "PodMetrics",
+		"metadata": map[string]interface{}{
+			"name":      name,
+			"namespace": api.OpenShiftConsoleNamespace,
+			"labels":    map[string]interface{}{"app": "console", "component": component},
+		},
+		"containers": []interface{}{
+			map[string]interface{}{
+				"name":  containerName,
+				"usage": map[string]interface{}{"cpu": cpu, "memory": memory},
+			},
+		},
+	}}
+}
+
+func TestRecommendContainerRequestsFromPodMetrics(t *testing.T) {
+	client := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), map[schema.GroupVersionResource]string{
+		PodMetricsResource: "PodMetricsList",
+	})
+	for _, metrics := range []*unstructured.Unstructured{
+		podMetrics("console-1", "ui", api.ConsoleContainerName, "120m", "600Mi"),
+		podMetrics("console-2", "ui", api.ConsoleContainerName, "80m", "1200Mi"),
+	} {
+		if _, err := client.Resource(PodMetricsResource).Namespace(api.OpenShiftConsoleNamespace).Create(context.TODO(), metrics, metav1.CreateOptions{}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	usage, err := NewMetricsAPIUsageSource(client).GetContainerUsage(context.TODO(), api.OpenShiftConsoleNamespace, consolePodsSelector)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(usage[api.ConsoleContainerName]) != 2 {
+		t.Fatalf("expected the usage of 2 console pods, got %v", usage)
+	}
+
+	current := map[string]corev1.ResourceList{
+		api.DownloadsContainerName: {corev1.ResourceMemory: resource.MustParse("80Mi")},
+	}
+	recommendations := RecommendContainerRequests(usage, current)
+	consoleMemory := recommendations[api.ConsoleContainerName][corev1.ResourceMemory]
+	if consoleMemory.Cmp(resource.MustParse("1500Mi")) != 0 {
+		t.Errorf("expected a 1500Mi console memory request, got %s", consoleMemory.String())
+	}
+	consoleCPU := recommendations[api.ConsoleContainerName][corev1.ResourceCPU]
+	if consoleCPU.Cmp(resource.MustParse("150m")) != 0 {
+		t.Errorf("expected a 150m console cpu request, got %s", consoleCPU.String())
+	}
+	downloadsMemory := recommendations[api.DownloadsContainerName][corev1.ResourceMemory]
+	if downloadsMemory.Cmp(resource.MustParse("80Mi")) != 0 {
+		t.Errorf("expected the downloads recommendation to be kept without usage, got %s", downloadsMemory.String())
+	}
+}
@@ -0,0 +1,97 @@
+package route
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	// k8s
+	corev1 "k8s.io/api/core/v1"
+
+	// openshift
+	operatorsv1 "github.com/openshift/api/operator/v1"
+	"github.com/openshift/library-go/pkg/route/routeapihelpers"
+
+	// console-operator
+	"github.com/openshift/console-operator/pkg/api"
+	routesub "github.com/openshift/console-operator/pkg/console/subresource/route"
+)
+
+// SyncHostnameAliases applies a route for every hostname alias set on the operator
+// config and removes the routes of the aliases that were dropped. A failing alias does
+// not prevent the others from being synced, the returned error lists the aliases that
+// failed or are not admitted yet.
+func (c *RouteSyncController) SyncHostnameAliases(ctx context.Context, operatorConfig *operatorsv1.Console, routeConfig *routesub.RouteConfig) (string, error) {
+	aliases, err := routesub.GetHostnameAliases(operatorConfig)
+	if err != nil {
+		return "InvalidHostnameAliases", err
+	}
+
+	aliasRouteNames := map[string]bool{}
+	aliasErrors := []string{}
+	for _, alias := range aliases {
+		aliasRouteNames[routesub.GetHostnameAliasRouteName(alias.Name)] = true
+		if err := c.syncHostnameAlias(ctx, routeConfig, alias); err != nil {
+			aliasErrors = append(aliasErrors, fmt.Sprintf("%s (%s): %v", alias.Name, alias.Hostname, err))
+		}
+	}
+
+	aliasRoutes, err := routesub.GetHostnameAliasRoutes(c.routeLister)
+	if err != nil {
+		return "FailedListAliasRoutes", err
+	}
+	for _, aliasRoute := range aliasRoutes {
+		if aliasRouteNames[aliasRoute.Name] {
+			continue
+		}
+		if err := c.removeRoute(ctx, aliasRoute.Name); err != nil {
+			return "FailedDeleteAliasRoute", err
+		}
+	}
+
+	if len(aliasErrors) != 0 {
+		return "HostnameAliasesNotAdmitted", fmt.Errorf("hostname aliases not served: %s", strings.Join(aliasErrors, "; "))
+	}
+	return "", nil
+}
+
+func (c *RouteSyncController) syncHostnameAlias(ctx context.Context, routeConfig *routesub.RouteConfig, alias routesub.HostnameAlias) error {
+	var customTLSSecret *corev1.Secret
+	if len(alias.ServingCertKeyPairSecret) != 0 {
+		secret, err := c.secretLister.Secrets(api.OpenShiftConfigNamespace).Get(alias.ServingCertKeyPairSecret)
+		if err != nil {
+			return fmt.Errorf("failed to GET TLS secret: %v", err)
+		}
+		customTLSSecret = secret
+	} else if !strings.HasSuffix(alias.Hostname, routeConfig.GetDomain()) {
+		return fmt.Errorf("secret reference for the TLS secret is not defined")
+	}
+
+	customTLSCert, err := ValidateCustomCertSecret(customTLSSecret)
+	if err != nil {
+		return err
+	}
+
+	aliasRoute, _, err := routesub.ApplyRoute(c.routeClient, routeConfig.AliasRoute(alias, customTLSCert))
+	if err != nil {
+		return err
+	}
+	if _, _, err := routeapihelpers.IngressURI(aliasRoute, aliasRoute.Spec.Host); err != nil {
+		return err
+	}
+	return nil
+}
+
+// removeHostnameAliases deletes all the alias routes.
+func (c *RouteSyncController) removeHostnameAliases(ctx context.Context) error {
+	aliasRoutes, err := routesub.GetHostnameAliasRoutes(c.routeLister)
+	if err != nil {
+		return err
+	}
+	for _, aliasRoute := range aliasRoutes {
+		if err := c.removeRoute(ctx, aliasRoute.Name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -11,6 +11,8 @@ import (
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	coreinformersv1 "k8s.io/client-go/informers/core/v1"
+	corev1client "k8s.io/client-go/kubernetes/typed/core/v1"
+	networkingclientv1 "k8s.io/client-go/kubernetes/typed/networking/v1"
 	corev1listers "k8s.io/client-go/listers/core/v1"
 	"k8s.io/klog/v2"
 
@@ -24,6 +26,7 @@ import (
 	operatorv1listers "github.com/openshift/client-go/operator/listers/operator/v1"
 	routeclientv1 "github.com/openshift/client-go/route/clientset/versioned/typed/route/v1"
 	routesinformersv1 "github.com/openshift/client-go/route/informers/externalversions/route/v1"
+	routev1listers "github.com/openshift/client-go/route/listers/route/v1"
 	"github.com/openshift/library-go/pkg/controller/factory"
 	"github.com/openshift/library-go/pkg/operator/events"
 	"github.com/openshift/library-go/pkg/operator/v1helpers"
@@ -32,7 +35,10 @@ import (
 	// console-operator
 	"github.com/openshift/console-operator/pkg/api"
 	"github.com/openshift/console-operator/pkg/console/controllers/util"
+	"github.com/openshift/console-operator/pkg/console/metrics"
 	"github.com/openshift/console-operator/pkg/console/status"
+	httproutesub "github.com/openshift/console-operator/pkg/console/subresource/httproute"
+	ingresssub "github.com/openshift/console-operator/pkg/console/subresource/ingress"
 	routesub "github.com/openshift/console-operator/pkg/console/subresource/route"
 )
 
@@ -42,11 +48,21 @@ type RouteSyncController struct {
 	// clients
 	operatorClient             v1helpers.OperatorClient
 	routeClient                routeclientv1.RoutesGetter
+	routeLister                routev1listers.RouteLister
 	operatorConfigLister       operatorv1listers.ConsoleLister
 	ingressConfigLister        configlistersv1.IngressLister
 	secretLister               corev1listers.SecretLister
+	configMapLister            corev1listers.ConfigMapLister
 	infrastructureConfigLister configlistersv1.InfrastructureLister
 	clusterVersionLister       configlistersv1.ClusterVersionLister
+	ingressClient              networkingclientv1.IngressesGetter
+	secretClient               corev1client.SecretsGetter
+	// ingressesRemoved records that the Ingresses were cleaned up since Ingress
+	// mode was last enabled, to spare the API calls on every resync.
+	ingressesRemoved bool
+	// routesRemoved records that the Routes were cleaned up since they were last
+	// replaced by HTTPRoutes or Ingresses, to spare the API calls on every resync.
+	routesRemoved bool
 }
 
 func NewRouteSyncController(
@@ -57,9 +73,12 @@ func NewRouteSyncController(
 	// clients
 	operatorClient v1helpers.OperatorClient,
 	routev1Client routeclientv1.RoutesGetter,
+	ingressClient networkingclientv1.IngressesGetter,
+	secretClient corev1client.SecretsGetter,
 	// informers
 	operatorConfigInformer v1.ConsoleInformer,
 	secretInformer coreinformersv1.SecretInformer,
+	configMapInformer coreinformersv1.ConfigMapInformer,
 	routeInformer routesinformersv1.RouteInformer,
 	// events
 	recorder events.Recorder,
@@ -71,12 +90,22 @@ func NewRouteSyncController(
 		operatorConfigLister:       operatorConfigInformer.Lister(),
 		ingressConfigLister:        configInformer.Config().V1().Ingresses().Lister(),
 		routeClient:                routev1Client,
+		routeLister:                routeInformer.Lister(),
+		ingressClient:              ingressClient,
+		secretClient:               secretClient,
 		secretLister:               secretInformer.Lister(),
+		configMapLister:            configMapInformer.Lister(),
 		infrastructureConfigLister: configInformer.Config().V1().Infrastructures().Lister(),
 		clusterVersionLister:       configInformer.Config().V1().ClusterVersions().Lister(),
 	}
 
 	configV1Informers := configInformer.Config().V1()
+	controllerName := fmt.Sprintf("%sRouteController", strings.Title(routeName))
+	// only the console is served on hostname aliases, their admission is synced too
+	aliasRoutePrefixes := []string{}
+	if routeName == api.OpenShiftConsoleRouteName {
+		aliasRoutePrefixes = append(aliasRoutePrefixes, routesub.HostnameAliasRouteNamePrefix)
+	}
 
 	return factory.New().
 		WithFilteredEventsInformers( // configs
@@ -86,11 +115,14 @@ func NewRouteSyncController(
 			configV1Informers.Ingresses().Informer(),
 		).WithInformers(
 		secretInformer.Informer(),
+	).WithFilteredEventsInformers( // trusted CA bundle
+		util.IncludeNamesFilter(api.TrustedCAConfigMapName),
+		configMapInformer.Informer(),
 	).WithFilteredEventsInformers( // route
-		util.IncludeNamesFilter(routeName, routesub.GetCustomRouteName(routeName)),
+		util.IncludeNamesOrPrefixesFilter([]string{routeName, routesub.GetCustomRouteName(routeName)}, aliasRoutePrefixes...),
 		routeInformer.Informer(),
-	).ResyncEvery(time.Minute).WithSync(ctrl.Sync).
-		ToController(fmt.Sprintf("%sRouteController", strings.Title(routeName)), recorder.WithComponentSuffix(fmt.Sprintf("%s-route-controller", routeName)))
+	).ResyncEvery(time.Minute).WithSync(metrics.InstrumentSync(controllerName, ctrl.Sync)).
+		ToController(controllerName, recorder.WithComponentSuffix(fmt.Sprintf("%s-route-controller", routeName)))
 }
 
 func (c *RouteSyncController) Sync(ctx context.Context, controllerContext factory.SyncContext) error {
@@ -108,10 +140,10 @@ func (c *RouteSyncController) Sync(ctx context.Context, controllerContext factor
 		return nil
 	case operatorsv1.Removed:
 		klog.V(4).Infof("console-operator is in a removed state: deleting %q route", c.routeName)
-		if err = c.removeRoute(ctx, routesub.GetCustomRouteName(c.routeName)); err != nil {
+		if err = c.removeIngresses(ctx); err != nil {
 			return err
 		}
-		return c.removeRoute(ctx, c.routeName)
+		return c.removeRoutes(ctx)
 	default:
 		return fmt.Errorf("unknown state: %v", updatedOperatorConfig.Spec.ManagementState)
 	}
@@ -130,6 +162,21 @@ func (c *RouteSyncController) Sync(ctx context.Context, controllerContext factor
 		}
 	}
 
+	// Routes are replaced by HTTPRoutes when the console is exposed through a Gateway.
+	if httproutesub.IsGatewayEnabled(operatorConfig) {
+		c.resetRouteConditions(&statusHandler)
+		statusHandler.AddConditions(status.HandleProgressingOrDegraded(fmt.Sprintf("%sIngressSync", strings.Title(c.routeName)), "", nil))
+		if !c.ingressesRemoved {
+			if err := c.removeIngresses(ctx); err != nil {
+				return statusHandler.FlushAndReturn(err)
+			}
+		}
+		if c.routesRemoved {
+			return statusHandler.FlushAndReturn(nil)
+		}
+		return statusHandler.FlushAndReturn(c.removeRoutes(ctx))
+	}
+
 	infrastructureConfig, err := c.infrastructureConfigLister.Get(api.ConfigResourceName)
 	if err != nil {
 		return statusHandler.FlushAndReturn(err)
@@ -153,11 +200,32 @@ func (c *RouteSyncController) Sync(ctx context.Context, controllerContext factor
 	}
 	routeConfig := routesub.NewRouteConfig(updatedOperatorConfig, ingressConfig, c.routeName)
 
-	typePrefix := fmt.Sprintf("%sCustomRouteSync", strings.Title(c.routeName))
+	// Routes are replaced by Ingresses when an IngressClass is set.
+	typePrefix := fmt.Sprintf("%sIngressSync", strings.Title(c.routeName))
+	if ingresssub.IsIngressEnabled(updatedOperatorConfig) {
+		c.ingressesRemoved = false
+		ingressErrReason, ingressErr := c.SyncIngresses(ctx, updatedOperatorConfig, routeConfig, controllerContext.Recorder())
+		statusHandler.AddConditions(status.HandleProgressingOrDegraded(typePrefix, ingressErrReason, ingressErr))
+		c.resetRouteConditions(&statusHandler)
+		// keep serving the Routes until the Ingresses took over their hosts
+		if ingressErr != nil || c.routesRemoved {
+			return statusHandler.FlushAndReturn(ingressErr)
+		}
+		return statusHandler.FlushAndReturn(c.removeRoutes(ctx))
+	}
+	statusHandler.AddConditions(status.HandleProgressingOrDegraded(typePrefix, "", nil))
+	if !c.ingressesRemoved {
+		if err := c.removeIngresses(ctx); err != nil {
+			return statusHandler.FlushAndReturn(err)
+		}
+	}
+	c.routesRemoved = false
+
+	typePrefix = fmt.Sprintf("%sCustomRouteSync", strings.Title(c.routeName))
 	// try to sync the custom route first. If the sync fails for any reason, error
 	// out the sync loop and inform about this fact instead of putting default
 	// route into inaccessible state.
-	_, customRouteErrReason, customRouteErr := c.SyncCustomRoute(ctx, routeConfig, controllerContext)
+	customRoute, customRouteErrReason, customRouteErr := c.SyncCustomRoute(ctx, routeConfig, controllerContext)
 	statusHandler.AddConditions(status.HandleProgressingOrDegraded(typePrefix, customRouteErrReason, customRouteErr))
 	statusHandler.AddCondition(status.HandleUpgradable(typePrefix, customRouteErrReason, customRouteErr))
 	if customRouteErr != nil {
@@ -165,10 +233,28 @@ func (c *RouteSyncController) Sync(ctx context.Context, controllerContext factor
 	}
 
 	typePrefix = fmt.Sprintf("%sDefaultRouteSync", strings.Title(c.routeName))
-	_, defaultRouteErrReason, defaultRouteErr := c.SyncDefaultRoute(ctx, routeConfig, ingressConfig, controllerContext)
+	defaultRoute, defaultRouteErrReason, defaultRouteErr := c.SyncDefaultRoute(ctx, routeConfig, ingressConfig, controllerContext)
 	statusHandler.AddConditions(status.HandleProgressingOrDegraded(typePrefix, defaultRouteErrReason, defaultRouteErr))
 	statusHandler.AddCondition(status.HandleUpgradable(typePrefix, defaultRouteErrReason, defaultRouteErr))
 
+	// only the console can be served on hostname aliases
+	if c.routeName == api.OpenShiftConsoleRouteName {
+		aliasErrReason, aliasErr := c.SyncHostnameAliases(ctx, updatedOperatorConfig, routeConfig)
+		statusHandler.AddConditions(status.HandleProgressingOrDegraded("ConsoleHostnameAliasSync", aliasErrReason, aliasErr))
+	}
+
+	// the custom certificate is served by the custom route when there is one
+	certificateRoute := defaultRoute
+	if customRoute != nil {
+		certificateRoute = customRoute
+	}
+	typePrefix = fmt.Sprintf("%sRouteCertificate", strings.Title(c.routeName))
+	certificateErrReason, certificateErr := c.SyncCertificateWarning(updatedOperatorConfig, certificateRoute)
+	statusHandler.AddCondition(status.HandleWarning(typePrefix, certificateErrReason, certificateErr))
+	if certificateErr != nil {
+		controllerContext.Recorder().Warningf(certificateErrReason, "%s route: %v", c.routeName, certificateErr)
+	}
+
 	// warn if deprecated configuration of custom domain for 'console' route is set on the console-operator config
 	if (len(operatorConfig.Spec.Route.Hostname) != 0 || len(operatorConfig.Spec.Route.Secret.Name) != 0) && c.routeName == api.OpenShiftConsoleRouteName {
 		klog.Warning(deprecationMessage(operatorConfig))
@@ -177,6 +263,36 @@ func (c *RouteSyncController) Sync(ctx context.Context, controllerContext factor
 	return statusHandler.FlushAndReturn(defaultRouteErr)
 }
 
+// removeRoutes deletes the default and custom routes, and the hostname aliases of the console.
+func (c *RouteSyncController) removeRoutes(ctx context.Context) error {
+	for _, routeName := range []string{routesub.GetCustomRouteName(c.routeName), c.routeName} {
+		if err := c.removeRoute(ctx, routeName); err != nil {
+			return err
+		}
+	}
+	if c.routeName == api.OpenShiftConsoleRouteName {
+		if err := c.removeHostnameAliases(ctx); err != nil {
+			return err
+		}
+	}
+	c.routesRemoved = true
+	return nil
+}
+
+// resetRouteConditions clears the conditions of the route sync, for when the routes are
+// replaced by other resources.
+func (c *RouteSyncController) resetRouteConditions(statusHandler *status.StatusHandler) {
+	for _, syncType := range []string{"CustomRouteSync", "DefaultRouteSync"} {
+		typePrefix := fmt.Sprintf("%s%s", strings.Title(c.routeName), syncType)
+		statusHandler.AddConditions(status.HandleProgressingOrDegraded(typePrefix, "", nil))
+		statusHandler.AddCondition(status.HandleUpgradable(typePrefix, "", nil))
+	}
+	statusHandler.AddCondition(status.HandleWarning(fmt.Sprintf("%sRouteCertificate", strings.Title(c.routeName)), "", nil))
+	if c.routeName == api.OpenShiftConsoleRouteName {
+		statusHandler.AddConditions(status.HandleProgressingOrDegraded("ConsoleHostnameAliasSync", "", nil))
+	}
+}
+
 func (c *RouteSyncController) removeRoute(ctx context.Context, routeName string) error {
 	err := c.routeClient.Routes(api.OpenShiftConsoleNamespace).Delete(ctx, routeName, metav1.DeleteOptions{})
 	if apierrors.IsNotFound(err) {
@@ -314,6 +430,54 @@ func ValidateCustomCertSecret(customCertSecret *corev1.Secret) (*routesub.Custom
 	return routesub.GetCustomTLS(customCertSecret)
 }
 
+// getTrustedCABundle returns the trusted CA bundle injected in the console namespace,
+// or an empty bundle to only rely on the system trust.
+func (c *RouteSyncController) getTrustedCABundle() (string, error) {
+	trustedCAConfigMap, err := c.configMapLister.ConfigMaps(api.OpenShiftConsoleNamespace).Get(api.TrustedCAConfigMapName)
+	if apierrors.IsNotFound(err) {
+		klog.V(4).Infof("%s config map not found, verifying the custom TLS certificate with the system trust", api.TrustedCAConfigMapName)
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to GET trusted CA bundle: %v", err)
+	}
+	return trustedCAConfigMap.Data[api.TrustedCABundleKey], nil
+}
+
+// SyncCertificateWarning reports when the custom certificate served by the route
+// expires, and warns once it gets within the configured number of days of it, or when
+// it does not chain up to the trusted CA bundle or cover the route hostname. These
+// problems do not block the route sync, the certificate is served as configured.
+func (c *RouteSyncController) SyncCertificateWarning(operatorConfig *operatorsv1.Console, route *routev1.Route) (string, error) {
+	certificate, err := routesub.GetRouteCertificate(route)
+	if err != nil {
+		metrics.HandleRouteCertificateExpiry(c.routeName, nil)
+		return "InvalidRouteCertificate", err
+	}
+	if certificate == nil {
+		metrics.HandleRouteCertificateExpiry(c.routeName, nil)
+		return "", nil
+	}
+	metrics.HandleRouteCertificateExpiry(c.routeName, &certificate.NotAfter)
+
+	warningDays, err := routesub.GetCertificateExpiryWarningDays(operatorConfig)
+	if err != nil {
+		klog.Warningf("using %d days for the certificate expiry warning: %v", warningDays, err)
+	}
+	if err := routesub.CertificateExpiryWarning(certificate, warningDays, time.Now()); err != nil {
+		return "CertificateExpiringSoon", err
+	}
+
+	trustedCABundle, err := c.getTrustedCABundle()
+	if err != nil {
+		return "FailedTrustedCABundleGet", err
+	}
+	if err := routesub.VerifyCustomTLSCert([]byte(route.Spec.TLS.Certificate), route.Spec.Host, []byte(trustedCABundle)); err != nil {
+		return "CertificateVerificationFailed", err
+	}
+	return "", nil
+}
+
 func deprecationMessage(operatorConfig *operatorsv1.Console) string {
 	msg := `Deprecated: custom domain is being configured on console-operator config for the 'console' route.
 Please remove that entry from console-operator config and instead configure ingress config spec with following custom domain entry for 'console' route:
@@ -10,8 +10,16 @@ import (
 
 	// k8s
 	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	corev1listers "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+
+	// openshift
+	operatorsv1 "github.com/openshift/api/operator/v1"
+	routev1 "github.com/openshift/api/route/v1"
 
 	// console-operator
+	"github.com/openshift/console-operator/pkg/api"
 	routesub "github.com/openshift/console-operator/pkg/console/subresource/route"
 )
 
@@ -200,3 +208,49 @@ func TestValidateCustomCertSecret(t *testing.T) {
 		})
 	}
 }
+
+func TestSyncCertificateWarning(t *testing.T) {
+	tests := []struct {
+		name       string
+		route      *routev1.Route
+		wantReason string
+	}{
+		{
+			name: "Test route without a custom certificate",
+			route: &routev1.Route{
+				Spec: routev1.RouteSpec{Host: "console.example.com", TLS: &routev1.TLSConfig{}},
+			},
+		},
+		{
+			name: "Test custom certificate not signed by a trusted CA nor covering the hostname",
+			route: &routev1.Route{
+				Spec: routev1.RouteSpec{
+					Host: "console.example.com",
+					TLS:  &routev1.TLSConfig{Certificate: validCertificate, Key: validKey},
+				},
+			},
+			wantReason: "CertificateVerificationFailed",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			configMapIndexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+			if err := configMapIndexer.Add(&corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{Name: api.TrustedCAConfigMapName, Namespace: api.OpenShiftConsoleNamespace},
+			}); err != nil {
+				t.Fatal(err)
+			}
+			c := &RouteSyncController{
+				routeName:       api.OpenShiftConsoleRouteName,
+				configMapLister: corev1listers.NewConfigMapLister(configMapIndexer),
+			}
+			reason, err := c.SyncCertificateWarning(&operatorsv1.Console{}, tt.route)
+			if reason != tt.wantReason {
+				t.Errorf("SyncCertificateWarning() reason = %q, want %q", reason, tt.wantReason)
+			}
+			if (err != nil) != (len(tt.wantReason) != 0) {
+				t.Errorf("SyncCertificateWarning() unexpected error %v", err)
+			}
+		})
+	}
+}
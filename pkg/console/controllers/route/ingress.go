@@ -0,0 +1,130 @@
+package route
+
+import (
+	"context"
+
+	// k8s
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	// openshift
+	operatorsv1 "github.com/openshift/api/operator/v1"
+	"github.com/openshift/library-go/pkg/operator/events"
+	"github.com/openshift/library-go/pkg/operator/resource/resourceapply"
+
+	// console-operator
+	"github.com/openshift/console-operator/pkg/api"
+	ingresssub "github.com/openshift/console-operator/pkg/console/subresource/ingress"
+	routesub "github.com/openshift/console-operator/pkg/console/subresource/route"
+	utilsub "github.com/openshift/console-operator/pkg/console/subresource/util"
+)
+
+// SyncIngresses is the Ingress counterpart of SyncCustomRoute and SyncDefaultRoute.
+// The custom TLS secrets are validated the same way, then copied into the console
+// namespace for the Ingresses to reference them. The default Ingress points to the
+// redirect service when the console has a custom hostname.
+func (c *RouteSyncController) SyncIngresses(ctx context.Context, operatorConfig *operatorsv1.Console, routeConfig *routesub.RouteConfig, recorder events.Recorder) (string, error) {
+	annotations, err := ingresssub.GetIngressAnnotations(operatorConfig)
+	if err != nil {
+		return "InvalidIngressAnnotations", err
+	}
+	className := ingresssub.GetIngressClassName(operatorConfig)
+
+	serviceName, servicePort := c.routeName, int32(80)
+	if c.routeName == api.OpenShiftConsoleRouteName {
+		serviceName, servicePort = api.OpenShiftConsoleServiceName, int32(api.ConsoleContainerPort)
+	}
+
+	customIngressName := routesub.GetCustomRouteName(c.routeName)
+	defaultServiceName, defaultServicePort := serviceName, servicePort
+	if routeConfig.IsCustomHostnameSet() {
+		if configErr := c.ValidateCustomRouteConfig(ctx, routeConfig); configErr != nil {
+			return "InvalidCustomRouteConfig", configErr
+		}
+		customTLSSecret, err := c.GetCustomRouteTLSSecret(ctx, routeConfig)
+		if err != nil {
+			return "FailedCustomTLSSecretGet", err
+		}
+		customTLSCert, err := ValidateCustomCertSecret(customTLSSecret)
+		if err != nil {
+			return "InvalidCustomTLSSecret", err
+		}
+		tlsSecretName, reason, err := c.syncIngressTLSSecret(ctx, operatorConfig, customIngressName, customTLSCert, recorder)
+		if err != nil {
+			return reason, err
+		}
+		customIngress := ingresssub.DefaultIngress(customIngressName, routeConfig.GetCustomRouteHostname(), serviceName, servicePort, className, annotations, tlsSecretName)
+		utilsub.AddOwnerRef(customIngress, utilsub.OwnerRefFrom(operatorConfig))
+		if _, _, err := ingresssub.ApplyIngress(ctx, c.ingressClient, customIngress); err != nil {
+			return "FailedCustomIngressApply", err
+		}
+		// the console redirects the default hostname to the custom one
+		if c.routeName == api.OpenShiftConsoleRouteName {
+			defaultServiceName, defaultServicePort = api.OpenshiftConsoleRedirectServiceName, int32(api.RedirectContainerPort)
+		}
+	} else if err := c.removeIngress(ctx, customIngressName); err != nil {
+		return "FailedDeleteCustomIngress", err
+	}
+
+	defaultTLSSecret, err := c.GetDefaultRouteTLSSecret(ctx, routeConfig)
+	if err != nil {
+		return "InvalidDefaultRouteConfig", err
+	}
+	defaultTLSCert, err := ValidateCustomCertSecret(defaultTLSSecret)
+	if err != nil {
+		return "InvalidCustomTLSSecret", err
+	}
+	tlsSecretName, reason, err := c.syncIngressTLSSecret(ctx, operatorConfig, c.routeName, defaultTLSCert, recorder)
+	if err != nil {
+		return reason, err
+	}
+	defaultIngress := ingresssub.DefaultIngress(c.routeName, routeConfig.GetDefaultRouteHostname(), defaultServiceName, defaultServicePort, className, annotations, tlsSecretName)
+	utilsub.AddOwnerRef(defaultIngress, utilsub.OwnerRefFrom(operatorConfig))
+	if _, _, err := ingresssub.ApplyIngress(ctx, c.ingressClient, defaultIngress); err != nil {
+		return "FailedDefaultIngressApply", err
+	}
+	return "", nil
+}
+
+// syncIngressTLSSecret returns the name of the secret holding the custom certificate
+// of an Ingress, or an empty name when the ingress controller default certificate is used.
+func (c *RouteSyncController) syncIngressTLSSecret(ctx context.Context, operatorConfig *operatorsv1.Console, ingressName string, customTLSCert *routesub.CustomTLSCert, recorder events.Recorder) (string, string, error) {
+	if customTLSCert == nil {
+		if err := c.removeIngressTLSSecret(ctx, ingressName); err != nil {
+			return "", "FailedDeleteIngressTLSSecret", err
+		}
+		return "", "", nil
+	}
+	tlsSecret := ingresssub.DefaultTLSSecret(ingressName, customTLSCert)
+	utilsub.AddOwnerRef(tlsSecret, utilsub.OwnerRefFrom(operatorConfig))
+	if _, _, err := resourceapply.ApplySecret(ctx, c.secretClient, recorder, tlsSecret); err != nil {
+		return "", "FailedIngressTLSSecretApply", err
+	}
+	return tlsSecret.Name, "", nil
+}
+
+func (c *RouteSyncController) removeIngresses(ctx context.Context) error {
+	for _, ingressName := range []string{routesub.GetCustomRouteName(c.routeName), c.routeName} {
+		if err := c.removeIngress(ctx, ingressName); err != nil {
+			return err
+		}
+	}
+	c.ingressesRemoved = true
+	return nil
+}
+
+func (c *RouteSyncController) removeIngress(ctx context.Context, ingressName string) error {
+	err := c.ingressClient.Ingresses(api.OpenShiftConsoleNamespace).Delete(ctx, ingressName, metav1.DeleteOptions{})
+	if err != nil && !apierrors.IsNotFound(err) {
+		return err
+	}
+	return c.removeIngressTLSSecret(ctx, ingressName)
+}
+
+func (c *RouteSyncController) removeIngressTLSSecret(ctx context.Context, ingressName string) error {
+	err := c.secretClient.Secrets(api.OpenShiftConsoleNamespace).Delete(ctx, ingresssub.GetTLSSecretName(ingressName), metav1.DeleteOptions{})
+	if apierrors.IsNotFound(err) {
+		return nil
+	}
+	return err
+}
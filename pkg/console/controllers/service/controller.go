@@ -21,6 +21,7 @@ import (
 	"github.com/openshift/console-operator/bindata"
 	"github.com/openshift/console-operator/pkg/api"
 	"github.com/openshift/console-operator/pkg/console/controllers/util"
+	"github.com/openshift/console-operator/pkg/console/metrics"
 	"github.com/openshift/console-operator/pkg/console/status"
 	routesub "github.com/openshift/console-operator/pkg/console/subresource/route"
 	"github.com/openshift/library-go/pkg/controller/factory"
@@ -81,7 +82,7 @@ func NewServiceSyncController(
 		).WithFilteredEventsInformers( // console resources
 		util.IncludeNamesFilter(serviceName, ctrl.getRedirectServiceName()),
 		serviceInformer.Informer(),
-	).ResyncEvery(time.Minute).WithSync(ctrl.Sync).
+	).ResyncEvery(time.Minute).WithSync(metrics.InstrumentSync("ConsoleServiceController", ctrl.Sync)).
 		ToController("ConsoleServiceController", recorder.WithComponentSuffix("console-service-controller"))
 }
 
@@ -129,7 +130,7 @@ func (c *ServiceSyncController) Sync(ctx context.Context, controllerContext fact
 	// Service name matches the Route's so it can be used as well, for creating RouteConfig
 	routeConfig := routesub.NewRouteConfig(updatedOperatorConfig, ingressConfig, c.serviceName)
 
-	requiredSvc := c.getDefaultService(ingressDisabled)
+	requiredSvc := DefaultService(c.serviceName, ingressDisabled)
 	_, _, svcErr := resourceapply.ApplyService(ctx, c.serviceClient, controllerContext.Recorder(), requiredSvc)
 	statusHandler.AddConditions(status.HandleProgressingOrDegraded("ServiceSync", "FailedApply", svcErr))
 	if svcErr != nil {
@@ -152,7 +153,7 @@ func (c *ServiceSyncController) SyncRedirectService(ctx context.Context, routeCo
 		}
 		return "", nil
 	}
-	requiredRedirectService := c.getRedirectService()
+	requiredRedirectService := RedirectService(c.serviceName)
 	_, _, redirectSvcErr := resourceapply.ApplyService(ctx, c.serviceClient, controllerContext.Recorder(), requiredRedirectService)
 	if redirectSvcErr != nil {
 		return "FailedApply", redirectSvcErr
@@ -168,19 +169,19 @@ func (c *ServiceSyncController) removeService(ctx context.Context, serviceName s
 	return err
 }
 
-func (c *ServiceSyncController) getDefaultService(ingressDisabled bool) *corev1.Service {
+func DefaultService(serviceName string, ingressDisabled bool) *corev1.Service {
 	var service *corev1.Service
 	if !ingressDisabled {
-		service = resourceread.ReadServiceV1OrDie(bindata.MustAsset(fmt.Sprintf("assets/services/%s-service.yaml", c.serviceName)))
+		service = resourceread.ReadServiceV1OrDie(bindata.MustAsset(fmt.Sprintf("assets/services/%s-service.yaml", serviceName)))
 	} else {
-		service = resourceread.ReadServiceV1OrDie(bindata.MustAsset(fmt.Sprintf("assets/services/%s-nodeport-service.yaml", c.serviceName)))
+		service = resourceread.ReadServiceV1OrDie(bindata.MustAsset(fmt.Sprintf("assets/services/%s-nodeport-service.yaml", serviceName)))
 	}
 
 	return service
 }
 
-func (c *ServiceSyncController) getRedirectService() *corev1.Service {
-	service := resourceread.ReadServiceV1OrDie(bindata.MustAsset(fmt.Sprintf("assets/services/%s-redirect-service.yaml", c.serviceName)))
+func RedirectService(serviceName string) *corev1.Service {
+	service := resourceread.ReadServiceV1OrDie(bindata.MustAsset(fmt.Sprintf("assets/services/%s-redirect-service.yaml", serviceName)))
 
 	return service
 }
@@ -19,6 +19,7 @@ import (
 	operatorv1listers "github.com/openshift/client-go/operator/listers/operator/v1"
 	"github.com/openshift/console-operator/pkg/api"
 	"github.com/openshift/console-operator/pkg/console/controllers/util"
+	"github.com/openshift/console-operator/pkg/console/metrics"
 	"github.com/openshift/console-operator/pkg/console/status"
 	"github.com/openshift/library-go/pkg/controller/factory"
 	"github.com/openshift/library-go/pkg/operator/events"
@@ -64,7 +65,7 @@ func NewUpgradeNotificationController(
 		WithFilteredEventsInformers( // configs
 			util.IncludeNamesFilter(api.VersionResourceName),
 			configV1Informers.ClusterVersions().Informer(),
-		).ResyncEvery(time.Minute).WithSync(ctrl.Sync).
+		).ResyncEvery(time.Minute).WithSync(metrics.InstrumentSync("ClusterUpgradeNotificationController", ctrl.Sync)).
 		ToController("ClusterUpgradeNotificationController", recorder.WithComponentSuffix("cluster-upgrade-notification-controller"))
 }
 
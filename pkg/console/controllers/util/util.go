@@ -3,6 +3,7 @@ package util
 import (
 	"context"
 	"fmt"
+	"strings"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/sets"
@@ -32,6 +33,37 @@ func IncludeNamesFilter(names ...string) factory.EventFilterFunc {
 	}
 }
 
+// Return func which returns true if obj name is in names or starts with one of prefixes
+func IncludeNamesOrPrefixesFilter(names []string, prefixes ...string) factory.EventFilterFunc {
+	includeNames := IncludeNamesFilter(names...)
+	return func(obj interface{}) bool {
+		if includeNames(obj) {
+			return true
+		}
+		if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+			obj = tombstone.Obj
+		}
+		metaObj, ok := obj.(metav1.Object)
+		if !ok {
+			return false
+		}
+		for _, prefix := range prefixes {
+			if strings.HasPrefix(metaObj.GetName(), prefix) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// Return func which returns true if obj name is in the names returned by getNames when
+// the event is received, for objects referenced by a name that can change
+func IncludeReferencedNamesFilter(getNames func() []string) factory.EventFilterFunc {
+	return func(obj interface{}) bool {
+		return IncludeNamesFilter(getNames()...)(obj)
+	}
+}
+
 // Inverse of IncludeNamesFilter
 func ExcludeNamesFilter(names ...string) factory.EventFilterFunc {
 	return func(obj interface{}) bool {
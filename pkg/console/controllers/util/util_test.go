@@ -67,6 +67,58 @@ func TestIncludeNamesFilter(t *testing.T) {
 	}
 }
 
+func TestIncludeNamesOrPrefixesFilter(t *testing.T) {
+	tests := []struct {
+		name     string
+		names    []string
+		prefixes []string
+		object   metav1.Object
+		want     bool
+	}{
+		{
+			name:   "Test IncludeNamesOrPrefixesFilter name match",
+			names:  []string{"foo"},
+			object: fooObject,
+			want:   true,
+		},
+		{
+			name:     "Test IncludeNamesOrPrefixesFilter prefix match",
+			names:    []string{"foo"},
+			prefixes: []string{"ba"},
+			object:   barObject,
+			want:     true,
+		},
+		{
+			name:     "Test IncludeNamesOrPrefixesFilter no match",
+			names:    []string{"foo"},
+			prefixes: []string{"baz"},
+			object:   barObject,
+			want:     false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if diff := deep.Equal(IncludeNamesOrPrefixesFilter(tt.names, tt.prefixes...)(tt.object), tt.want); diff != nil {
+				t.Error(diff)
+			}
+		})
+	}
+}
+
+func TestIncludeReferencedNamesFilter(t *testing.T) {
+	referenced := []string{"foo"}
+	filter := IncludeReferencedNamesFilter(func() []string { return referenced })
+	if !filter(fooObject) || filter(barObject) {
+		t.Errorf("expected only the referenced foo object to match")
+	}
+	// the reference is read again on every event
+	referenced = []string{"bar"}
+	if filter(fooObject) || !filter(barObject) {
+		t.Errorf("expected only the newly referenced bar object to match")
+	}
+}
+
 func TestExcludeNamesFilter(t *testing.T) {
 
 	type args struct {
@@ -0,0 +1,25 @@
+package errors
+
+// an unsupported config overrides error captures an operator config whose
+// spec.unsupportedConfigOverrides produce a console-config the console cannot load.
+// The sync loop keeps the previous console-config in place when it sees one.
+type UnsupportedConfigOverridesError struct {
+	message string
+}
+
+// implement the error interface
+func (e *UnsupportedConfigOverridesError) Error() string {
+	return e.message
+}
+
+func NewUnsupportedConfigOverridesError(msg string) *UnsupportedConfigOverridesError {
+	err := &UnsupportedConfigOverridesError{
+		message: msg,
+	}
+	return err
+}
+
+func IsUnsupportedConfigOverridesError(err error) bool {
+	_, ok := err.(*UnsupportedConfigOverridesError)
+	return ok
+}
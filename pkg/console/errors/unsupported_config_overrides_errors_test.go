@@ -0,0 +1,37 @@
+package errors
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/go-test/deep"
+)
+
+func TestIsUnsupportedConfigOverridesError(t *testing.T) {
+	tests := []struct {
+		name   string
+		input  error
+		output bool
+	}{
+		{
+			name:   "IsUnsupportedConfigOverridesError returns true if passed an UnsupportedConfigOverridesError",
+			input:  NewUnsupportedConfigOverridesError("session.foo: Unsupported value"),
+			output: true,
+		}, {
+			name:   "IsUnsupportedConfigOverridesError returns false if passed a regular Error",
+			input:  fmt.Errorf("A regular error"),
+			output: false,
+		}, {
+			name:   "IsUnsupportedConfigOverridesError returns false if passed nil",
+			input:  nil,
+			output: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if diff := deep.Equal(IsUnsupportedConfigOverridesError(tt.input), tt.output); diff != nil {
+				t.Error(diff)
+			}
+		})
+	}
+}
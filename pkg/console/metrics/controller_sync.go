@@ -0,0 +1,62 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/openshift/library-go/pkg/controller/factory"
+
+	customerrors "github.com/openshift/console-operator/pkg/console/errors"
+)
+
+const (
+	syncResultSuccess     = "success"
+	syncResultProgressing = "progressing"
+	syncResultError       = "error"
+
+	// the reason of errors no condition reported
+	unknownSyncReason = "Unknown"
+)
+
+// syncFailure is implemented by the errors returned along with the reason of the condition
+// that reported them.
+type syncFailure interface {
+	error
+	Reason() string
+}
+
+// InstrumentSync records the duration and the result of every sync of a controller, along
+// with the time of its last successful one.
+func InstrumentSync(controller string, sync factory.SyncFunc) factory.SyncFunc {
+	return func(ctx context.Context, syncCtx factory.SyncContext) error {
+		start := time.Now()
+		err := sync(ctx, syncCtx)
+		HandleControllerSync(controller, time.Since(start), err, time.Now())
+		return err
+	}
+}
+
+// HandleControllerSync reports a sync of a controller that took the given duration and
+// ended with the given error.
+func HandleControllerSync(controller string, duration time.Duration, err error, now time.Time) {
+	defer recoverMetricPanic()
+	controllerSyncDuration.WithLabelValues(controller).Observe(duration.Seconds())
+	if err == nil {
+		controllerSyncs.WithLabelValues(controller, syncResultSuccess, "").Inc()
+		controllerLastSuccess.WithLabelValues(controller).Set(float64(now.Unix()))
+		return
+	}
+
+	result := syncResultError
+	var progressingErr *customerrors.SyncProgressingError
+	if errors.As(err, &progressingErr) {
+		result = syncResultProgressing
+	}
+	reason := unknownSyncReason
+	var failure syncFailure
+	if errors.As(err, &failure) {
+		reason = failure.Reason()
+	}
+	controllerSyncs.WithLabelValues(controller, result, reason).Inc()
+}
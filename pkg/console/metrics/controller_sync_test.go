@@ -0,0 +1,112 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/openshift/library-go/pkg/controller/factory"
+	"github.com/openshift/library-go/pkg/operator/events"
+	"k8s.io/component-base/metrics/testutil"
+
+	customerrors "github.com/openshift/console-operator/pkg/console/errors"
+)
+
+// reasonError is an error reported along with the reason of its condition.
+type reasonError struct {
+	reason string
+}
+
+func (e *reasonError) Error() string {
+	return "failed with " + e.reason
+}
+
+func (e *reasonError) Reason() string {
+	return e.reason
+}
+
+func syncCount(t *testing.T, controller, result, reason string) float64 {
+	t.Helper()
+	count, err := testutil.GetCounterMetricValue(controllerSyncs.WithLabelValues(controller, result, reason))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return count
+}
+
+func TestHandleControllerSync(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name       string
+		err        error
+		wantResult string
+		wantReason string
+	}{
+		{
+			name:       "Success",
+			wantResult: syncResultSuccess,
+		},
+		{
+			name:       "Error no condition reported",
+			err:        errors.New("failed"),
+			wantResult: syncResultError,
+			wantReason: unknownSyncReason,
+		},
+		{
+			name:       "Error reported by a condition",
+			err:        fmt.Errorf("sync failed: %w", &reasonError{reason: "FailedApply"}),
+			wantResult: syncResultError,
+			wantReason: "FailedApply",
+		},
+		{
+			name:       "Progressing",
+			err:        customerrors.NewSyncError("waiting for the deployment"),
+			wantResult: syncResultProgressing,
+			wantReason: unknownSyncReason,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			controller := "Test" + tt.name
+			HandleControllerSync(controller, time.Second, tt.err, now)
+
+			if count := syncCount(t, controller, tt.wantResult, tt.wantReason); count != 1 {
+				t.Errorf("expected one %s sync with the reason %q, got %v", tt.wantResult, tt.wantReason, count)
+			}
+			syncs, err := testutil.GetHistogramMetricCount(controllerSyncDuration.WithLabelValues(controller))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if syncs != 1 {
+				t.Errorf("expected the duration of one sync, got %d", syncs)
+			}
+			lastSuccess, err := testutil.GetGaugeMetricValue(controllerLastSuccess.WithLabelValues(controller))
+			if err != nil {
+				t.Fatal(err)
+			}
+			wantLastSuccess := float64(0)
+			if tt.err == nil {
+				wantLastSuccess = float64(now.Unix())
+			}
+			if lastSuccess != wantLastSuccess {
+				t.Errorf("last success = %v, want %v", lastSuccess, wantLastSuccess)
+			}
+		})
+	}
+}
+
+func TestInstrumentSync(t *testing.T) {
+	syncErr := &reasonError{reason: "FailedGet"}
+	sync := InstrumentSync("TestInstrumentSync", func(ctx context.Context, syncCtx factory.SyncContext) error {
+		return syncErr
+	})
+	syncCtx := factory.NewSyncContext("test", events.NewInMemoryRecorder("test"))
+	if err := sync(context.TODO(), syncCtx); err != syncErr {
+		t.Errorf("expected the sync error to be returned as is, got %v", err)
+	}
+	if count := syncCount(t, "TestInstrumentSync", syncResultError, "FailedGet"); count != 1 {
+		t.Errorf("expected one failed sync, got %v", count)
+	}
+}
@@ -1,6 +1,9 @@
 package metrics
 
 import (
+	"sync"
+	"time"
+
 	k8smetrics "k8s.io/component-base/metrics"
 	"k8s.io/component-base/metrics/legacyregistry"
 	"k8s.io/klog/v2"
@@ -22,10 +25,77 @@ var (
 		},
 		[]string{"major", "minor", "gitCommit", "gitVersion"},
 	)
+
+	pluginState = k8smetrics.NewGaugeVec(
+		&k8smetrics.GaugeOpts{
+			Name: "console_operator_plugin_state",
+			Help: "State of each console plugin enabled in the operator config, a constant '1' value labeled by plugin name and state.",
+		},
+		[]string{"plugin", "state"},
+	)
+
+	routeCertificateExpiry = k8smetrics.NewGaugeVec(
+		&k8smetrics.GaugeOpts{
+			Name: "console_operator_route_certificate_expiry_seconds",
+			Help: "Expiration time of the custom TLS certificate served by a console route, in seconds since the epoch.",
+		},
+		[]string{"route"},
+	)
+
+	sessionKeysCreated = k8smetrics.NewGauge(
+		&k8smetrics.GaugeOpts{
+			Name: "console_operator_session_keys_created_timestamp_seconds",
+			Help: "Creation time of the current console session keys, in seconds since the epoch.",
+		},
+	)
+
+	sessionKeyRotations = k8smetrics.NewCounter(
+		&k8smetrics.CounterOpts{
+			Name: "console_operator_session_key_rotations_total",
+			Help: "Number of scheduled rotations of the console session keys.",
+		},
+	)
+
+	controllerSyncDuration = k8smetrics.NewHistogramVec(
+		&k8smetrics.HistogramOpts{
+			Name:    "console_operator_controller_sync_duration_seconds",
+			Help:    "Duration of the syncs of each console operator controller.",
+			Buckets: k8smetrics.ExponentialBuckets(0.01, 2, 12),
+		},
+		[]string{"controller"},
+	)
+
+	controllerSyncs = k8smetrics.NewCounterVec(
+		&k8smetrics.CounterOpts{
+			Name: "console_operator_controller_syncs_total",
+			Help: "Number of syncs of each console operator controller, by result and by the reason of the condition a failed sync reported.",
+		},
+		[]string{"controller", "result", "reason"},
+	)
+
+	controllerLastSuccess = k8smetrics.NewGaugeVec(
+		&k8smetrics.GaugeOpts{
+			Name: "console_operator_controller_last_success_timestamp_seconds",
+			Help: "Time of the last successful sync of each console operator controller, in seconds since the epoch.",
+		},
+		[]string{"controller"},
+	)
+
+	// pluginStates remembers the last reported state of each plugin so that
+	// stale series can be dropped when a plugin changes state or is disabled.
+	pluginStatesLock sync.Mutex
+	pluginStates     = map[string]string{}
 )
 
 func init() {
 	legacyregistry.MustRegister(consoleURL)
+	legacyregistry.MustRegister(pluginState)
+	legacyregistry.MustRegister(routeCertificateExpiry)
+	legacyregistry.MustRegister(sessionKeysCreated)
+	legacyregistry.MustRegister(sessionKeyRotations)
+	legacyregistry.MustRegister(controllerSyncDuration)
+	legacyregistry.MustRegister(controllerSyncs)
+	legacyregistry.MustRegister(controllerLastSuccess)
 }
 
 func HandleConsoleURL(oldURL, newURL string) {
@@ -72,6 +142,46 @@ func hostDied(old, new string) bool {
 	return len(old) != 0 && len(new) == 0
 }
 
+// HandlePluginStates reports the state of every enabled plugin, keyed by plugin name.
+// Plugins missing from states are no longer reported.
+func HandlePluginStates(states map[string]string) {
+	defer recoverMetricPanic()
+	pluginStatesLock.Lock()
+	defer pluginStatesLock.Unlock()
+
+	for plugin, oldState := range pluginStates {
+		if newState, ok := states[plugin]; !ok || newState != oldState {
+			pluginState.DeleteLabelValues(plugin, oldState)
+			delete(pluginStates, plugin)
+		}
+	}
+	for plugin, state := range states {
+		pluginState.WithLabelValues(plugin, state).Set(1)
+		pluginStates[plugin] = state
+	}
+}
+
+// HandleRouteCertificateExpiry reports when the custom certificate of a route expires,
+// a nil expiry drops the series once the route no longer uses a custom certificate.
+func HandleRouteCertificateExpiry(routeName string, expiry *time.Time) {
+	defer recoverMetricPanic()
+	if expiry == nil {
+		routeCertificateExpiry.DeleteLabelValues(routeName)
+		return
+	}
+	routeCertificateExpiry.WithLabelValues(routeName).Set(float64(expiry.Unix()))
+}
+
+// HandleSessionKeys reports when the current session keys were created, and counts a
+// rotation of the keys.
+func HandleSessionKeys(created time.Time, rotated bool) {
+	defer recoverMetricPanic()
+	sessionKeysCreated.Set(float64(created.Unix()))
+	if rotated {
+		sessionKeyRotations.Inc()
+	}
+}
+
 func RegisterVersion(major, minor, gitCommit, gitVersion string) {
 	defer recoverMetricPanic()
 	consoleBuildInfo.WithLabelValues(major, minor, gitCommit, gitVersion).Set(1)
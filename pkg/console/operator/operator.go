@@ -16,10 +16,14 @@ import (
 	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/dynamic/dynamicinformer"
 	corev1 "k8s.io/client-go/informers/core/v1"
+	networkinginformersv1 "k8s.io/client-go/informers/networking/v1"
+	schedulinginformersv1 "k8s.io/client-go/informers/scheduling/v1"
 	appsclientv1 "k8s.io/client-go/kubernetes/typed/apps/v1"
 	coreclientv1 "k8s.io/client-go/kubernetes/typed/core/v1"
 	appsv1listers "k8s.io/client-go/listers/apps/v1"
 	corev1listers "k8s.io/client-go/listers/core/v1"
+	networkingv1listers "k8s.io/client-go/listers/networking/v1"
+	schedulingv1listers "k8s.io/client-go/listers/scheduling/v1"
 	"k8s.io/klog/v2"
 
 	// openshift
@@ -39,6 +43,7 @@ import (
 	routev1listers "github.com/openshift/client-go/route/listers/route/v1"
 	"github.com/openshift/console-operator/pkg/api"
 	"github.com/openshift/console-operator/pkg/console/controllers/util"
+	"github.com/openshift/console-operator/pkg/console/metrics"
 	consolestatus "github.com/openshift/console-operator/pkg/console/status"
 	"github.com/openshift/library-go/pkg/controller/factory"
 	"github.com/openshift/library-go/pkg/operator/events"
@@ -67,6 +72,7 @@ type consoleOperator struct {
 	oauthConfigLister    configlistersv1.OAuthLister
 	authnConfigLister    configlistersv1.AuthenticationLister
 	clusterVersionLister configlistersv1.ClusterVersionLister
+	apiServerLister      configlistersv1.APIServerLister
 	dynamicClient        dynamic.Interface
 	// core kube
 	secretsClient            coreclientv1.SecretsGetter
@@ -76,7 +82,11 @@ type consoleOperator struct {
 	targetNSConfigMapLister  corev1listers.ConfigMapLister // for openshift-console namespace
 	managedNSConfigMapLister corev1listers.ConfigMapLister // for openshift-config-managed namespace
 	nodeLister               corev1listers.NodeLister
+	priorityClassLister      schedulingv1listers.PriorityClassLister
+	podLister                corev1listers.PodLister // for openshift-console namespace
+	serviceClient            coreclientv1.ServicesGetter
 	deploymentClient         appsclientv1.DeploymentsGetter
+	deploymentLister         appsv1listers.DeploymentLister
 	// openshift
 	operatorNSConfigMapLister corev1listers.ConfigMapLister //for openshift-console-operator namespace
 	configNSConfigMapLister   corev1listers.ConfigMapLister //for openshift-config namespace
@@ -84,6 +94,7 @@ type consoleOperator struct {
 	consoleOperatorLister     operatorlistersv1.ConsoleLister
 	routeClient               routeclientv1.RoutesGetter
 	routeLister               routev1listers.RouteLister
+	ingressLister             networkingv1listers.IngressLister
 	versionGetter             status.VersionGetter
 	// lister
 	consolePluginLister listerv1.ConsolePluginLister
@@ -117,6 +128,7 @@ func NewConsoleOperator(
 	// core resources
 	corev1Client coreclientv1.CoreV1Interface,
 	coreV1 corev1.Interface,
+	priorityClassInformer schedulinginformersv1.PriorityClassInformer,
 	// deployments
 	deploymentClient appsclientv1.DeploymentsGetter,
 	deploymentInformer appsinformersv1.DeploymentInformer,
@@ -125,6 +137,7 @@ func NewConsoleOperator(
 	oauthClientSwitchedInformer *util.InformerWithSwitch,
 	// routes
 	routeInformer routesinformersv1.RouteInformer,
+	ingressInformer networkinginformersv1.IngressInformer,
 	// plugins
 	consolePluginInformer consoleinformersv1.ConsolePluginInformer,
 	// openshift config
@@ -144,10 +157,11 @@ func NewConsoleOperator(
 	managedNSConfigMapInformer := managedCoreV1.ConfigMaps()
 	serviceInformer := coreV1.Services()
 	nodeInformer := coreV1.Nodes()
+	podInformer := coreV1.Pods()
 	configV1Informers := configInformer.Config().V1()
 	configNameFilter := util.IncludeNamesFilter(api.ConfigResourceName)
 
-	targetNameFilter := util.IncludeNamesFilter(api.OpenShiftConsoleName)
+	targetNameFilter := util.IncludeNamesFilter(api.OpenShiftConsoleName, api.ConsoleCanaryName)
 
 	c := &consoleOperator{
 		// configs
@@ -161,6 +175,7 @@ func NewConsoleOperator(
 		oauthConfigLister:     configInformer.Config().V1().OAuths().Lister(),
 		clusterVersionLister:  configInformer.Config().V1().ClusterVersions().Lister(),
 		authnConfigLister:     configV1Informers.Authentications().Lister(),
+		apiServerLister:       configV1Informers.APIServers().Lister(),
 		// console resources
 		// core kube
 		secretsClient:        corev1Client,
@@ -174,12 +189,17 @@ func NewConsoleOperator(
 		configNSConfigMapLister:   configNSConfigMapInformer.Lister(),
 		managedNSConfigMapLister:  managedNSConfigMapInformer.Lister(),
 
-		nodeLister:       nodeInformer.Lister(),
-		deploymentClient: deploymentClient,
-		dynamicClient:    dynamicClient,
+		nodeLister:          nodeInformer.Lister(),
+		priorityClassLister: priorityClassInformer.Lister(),
+		podLister:           podInformer.Lister(),
+		serviceClient:       corev1Client,
+		deploymentClient:    deploymentClient,
+		deploymentLister:    deploymentInformer.Lister(),
+		dynamicClient:       dynamicClient,
 		// openshift
 		oauthClientLister: oauthClientSwitchedInformer.Lister(),
 		routeLister:       routeInformer.Lister(),
+		ingressLister:     ingressInformer.Lister(),
 		versionGetter:     versionGetter,
 		// plugins
 		consolePluginLister: consolePluginInformer.Lister(),
@@ -196,6 +216,7 @@ func NewConsoleOperator(
 		configV1Informers.Proxies().Informer(),
 		configV1Informers.OAuths().Informer(),
 		configV1Informers.Authentications().Informer(),
+		configV1Informers.APIServers().Informer(),
 	}
 
 	olmGroupVersionResource := schema.GroupVersionResource{
@@ -221,15 +242,23 @@ func NewConsoleOperator(
 		targetNameFilter,
 		deploymentInformer.Informer(),
 		routeInformer.Informer(),
+		ingressInformer.Informer(),
 		serviceInformer.Informer(),
 	).WithInformers(
 		nodeInformer.Informer(),
+		priorityClassInformer.Informer(),
 		consolePluginInformer.Informer(),
 	).WithInformers(
 		targetNSConfigMapInformer.Informer(),
+		podInformer.Informer(),
 	).WithFilteredEventsInformers(
 		util.IncludeNamesFilter(api.OpenShiftConsoleConfigMapName, api.OpenShiftConsolePublicConfigMapName),
 		managedNSConfigMapInformer.Informer(),
+	).WithFilteredEventsInformers( // placement config map
+		util.IncludeReferencedNamesFilter(func() []string {
+			return deployment.GetPlacementConfigMapNames(c.consoleOperatorLister)
+		}),
+		configNSConfigMapInformer.Informer(),
 	).WithFilteredEventsInformers(
 		factory.NamesFilter(api.OAuthClientName),
 		oauthClientSwitchedInformer.Informer(),
@@ -242,7 +271,7 @@ func NewConsoleOperator(
 	).WithFilteredEventsInformers(
 		util.IncludeNamesFilter(telemetry.TelemeterClientDeploymentName),
 		monitoringDeploymentInformer.Informer(),
-	).ResyncEvery(time.Minute).WithSync(c.Sync).
+	).ResyncEvery(time.Minute).WithSync(metrics.InstrumentSync("ConsoleOperator", c.Sync)).
 		ToController("ConsoleOperator", recorder.WithComponentSuffix("console-operator"))
 }
 
@@ -384,13 +413,19 @@ func (c *consoleOperator) removeConsole(ctx context.Context, operatorConfig *ope
 	var errs []error
 	// configmaps
 	errs = append(errs, c.configMapClient.ConfigMaps(api.TargetNamespace).Delete(ctx, configmap.Stub().Name, metav1.DeleteOptions{}))
+	errs = append(errs, c.configMapClient.ConfigMaps(api.TargetNamespace).Delete(ctx, configmap.ConfigProvenanceStub().Name, metav1.DeleteOptions{}))
+	errs = append(errs, c.configMapClient.ConfigMaps(api.TargetNamespace).Delete(ctx, configmap.LastKnownGoodStub().Name, metav1.DeleteOptions{}))
+	errs = append(errs, c.configMapClient.ConfigMaps(api.TargetNamespace).Delete(ctx, configmap.PluginQuarantineStub().Name, metav1.DeleteOptions{}))
 	errs = append(errs, c.configMapClient.ConfigMaps(api.TargetNamespace).Delete(ctx, configmap.ServiceCAStub().Name, metav1.DeleteOptions{}))
+	errs = append(errs, c.configMapClient.ConfigMaps(api.TargetNamespace).Delete(ctx, configmap.CanaryStub().Name, metav1.DeleteOptions{}))
+	errs = append(errs, c.configMapClient.ConfigMaps(api.TargetNamespace).Delete(ctx, configmap.OIDCDiscoveryStub().Name, metav1.DeleteOptions{}))
 	// secret
 	errs = append(errs, c.secretsClient.Secrets(api.TargetNamespace).Delete(ctx, secret.Stub().Name, metav1.DeleteOptions{}))
 
 	// deployment
 	// NOTE: CVO controls the deployment for downloads, console-operator cannot delete it.
 	errs = append(errs, c.deploymentClient.Deployments(api.TargetNamespace).Delete(ctx, deployment.Stub().Name, metav1.DeleteOptions{}))
+	errs = append(errs, c.removeCanary(ctx))
 	// clear the console URL from the public config map in openshift-config-managed
 	_, _, updateConfigErr := resourceapply.ApplyConfigMap(ctx, c.configMapClient, recorder, configmap.EmptyPublicConfig())
 	errs = append(errs, updateConfigErr)
@@ -7,6 +7,7 @@ import (
 	"net/url"
 	"os"
 	"strings"
+	"time"
 
 	// kube
 	appsv1 "k8s.io/api/apps/v1"
@@ -16,6 +17,7 @@ import (
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
 	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/klog/v2"
 
@@ -32,11 +34,15 @@ import (
 
 	// operator
 	"github.com/openshift/console-operator/pkg/api"
+	"github.com/openshift/console-operator/pkg/console/controllers/healthcheck"
 	customerrors "github.com/openshift/console-operator/pkg/console/errors"
 	"github.com/openshift/console-operator/pkg/console/metrics"
 	"github.com/openshift/console-operator/pkg/console/status"
 	configmapsub "github.com/openshift/console-operator/pkg/console/subresource/configmap"
 	deploymentsub "github.com/openshift/console-operator/pkg/console/subresource/deployment"
+	hpasub "github.com/openshift/console-operator/pkg/console/subresource/hpa"
+	httproutesub "github.com/openshift/console-operator/pkg/console/subresource/httproute"
+	ingresssub "github.com/openshift/console-operator/pkg/console/subresource/ingress"
 	oauthsub "github.com/openshift/console-operator/pkg/console/subresource/oauthclient"
 	routesub "github.com/openshift/console-operator/pkg/console/subresource/route"
 	secretsub "github.com/openshift/console-operator/pkg/console/subresource/secret"
@@ -67,7 +73,22 @@ func (co *consoleOperator) sync_v400(ctx context.Context, controllerContext fact
 			routeName = api.OpenshiftConsoleCustomRouteName
 		}
 
-		route, url, routeReasonErr, routeErr := routesub.GetActiveRouteInfo(co.routeLister, routeName)
+		var (
+			route          *routev1.Route
+			url            *url.URL
+			routeReasonErr string
+			routeErr       error
+		)
+		if httproutesub.IsGatewayEnabled(set.Operator) {
+			_, url, routeReasonErr, routeErr = httproutesub.GetActiveHTTPRouteInfo(ctx, co.dynamicClient, routeName)
+			// console-config only looks at the route name, to serve the redirect to a custom hostname
+			route = &routev1.Route{ObjectMeta: metav1.ObjectMeta{Name: routeName, Namespace: api.OpenShiftConsoleNamespace}}
+		} else if ingresssub.IsIngressEnabled(set.Operator) {
+			_, url, routeReasonErr, routeErr = ingresssub.GetActiveIngressInfo(co.ingressLister, routeName)
+			route = &routev1.Route{ObjectMeta: metav1.ObjectMeta{Name: routeName, Namespace: api.OpenShiftConsoleNamespace}}
+		} else {
+			route, url, routeReasonErr, routeErr = routesub.GetActiveRouteInfo(co.routeLister, routeName)
+		}
 		// TODO: this controller is no longer responsible for syncing the route.
 		//   however, the route is essential for several of the components below.
 		//   - the loop should exit early and wait until the RouteSyncController creates the route.
@@ -94,6 +115,17 @@ func (co *consoleOperator) sync_v400(ctx context.Context, controllerContext fact
 		return statusHandler.FlushAndReturn(err)
 	}
 
+	// an invalid rotation leaves the session keys as they are
+	sessionSecretRotation, sessionSecretRotationErr := secretsub.GetSessionSecretRotation(set.Operator)
+	sessionSecretRotationReason := "InvalidSessionSecretRotation"
+	// the session secret is only used with OIDC, there are no keys to rotate otherwise
+	if sessionSecretRotation != nil && authnConfig.Spec.Type != configv1.AuthenticationTypeOIDC {
+		sessionSecretRotationReason = "UnsupportedAuthenticationType"
+		sessionSecretRotationErr = fmt.Errorf("the %s annotation only applies to OIDC authentication, it is ignored", secretsub.SessionSecretRotationPeriodAnnotation)
+		sessionSecretRotation = nil
+	}
+	statusHandler.AddCondition(status.HandleDegraded("SessionSecretRotation", sessionSecretRotationReason, sessionSecretRotationErr))
+
 	var (
 		authServerCAConfig *corev1.ConfigMap
 		sessionSecret      *corev1.Secret
@@ -101,32 +133,66 @@ func (co *consoleOperator) sync_v400(ctx context.Context, controllerContext fact
 	switch authnConfig.Spec.Type {
 	case configv1.AuthenticationTypeOIDC:
 		if len(authnConfig.Spec.OIDCProviders) > 0 {
-			oidcProvider := authnConfig.Spec.OIDCProviders[0]
-			authServerCAConfig, err = co.configNSConfigMapLister.ConfigMaps(api.OpenShiftConfigNamespace).Get(oidcProvider.Issuer.CertificateAuthority.Name)
-			if err != nil && !apierrors.IsNotFound(err) {
+			authServerCAConfig, err = co.getAuthServerCAConfig(authnConfig.Spec.OIDCProviders[0])
+			if err != nil {
 				return statusHandler.FlushAndReturn(err)
 			}
 		}
 
-		sessionSecret, err = co.syncSessionSecret(ctx, updatedOperatorConfig, controllerContext.Recorder())
+		sessionSecret, err = co.syncSessionSecret(ctx, updatedOperatorConfig, sessionSecretRotation, controllerContext.Recorder())
 		if err != nil {
 			return statusHandler.FlushAndReturn(err)
 		}
 	}
-
+	statusHandler.AddCondition(sessionSecretRotationStatus(sessionSecret, sessionSecretRotation))
+
+	availablePlugins := co.GetAvailablePlugins(set.Operator.Spec.Plugins)
+	pluginProxyCABundles := co.getPluginProxyCABundles(availablePlugins)
+	// an invalid console TLS profile falls back to the cluster one
+	apiServerConfig, apiServerErr := co.getAPIServerConfig()
+	if apiServerErr != nil {
+		return statusHandler.FlushAndReturn(apiServerErr)
+	}
+	tlsProfile, tlsProfileErr := configmapsub.GetTLSSecurityProfile(set.Operator, apiServerConfig)
+	statusHandler.AddCondition(status.HandleDegraded("TLSSecurityProfile", "InvalidTLSSecurityProfile", tlsProfileErr))
 	cm, cmChanged, cmErrReason, cmErr := co.SyncConfigMap(
 		ctx,
 		set.Operator,
 		set.Console,
 		set.Infrastructure,
 		set.OAuth,
-		authServerCAConfig,
+		sessionSecret,
 		authnConfig,
 		consoleRoute,
+		availablePlugins,
+		pluginProxyCABundles,
+		tlsProfile,
 		controllerContext.Recorder(),
 		consoleURL.Hostname(),
 	)
 	toUpdate = toUpdate || cmChanged
+	// invalid overrides are reported on their own condition, as long as the previous
+	// console-config is still around the sync carries on with it.
+	var overridesErr error
+	if customerrors.IsUnsupportedConfigOverridesError(cmErr) {
+		overridesErr = cmErr
+		if cm != nil {
+			cmErrReason, cmErr = "", nil
+		}
+	}
+	statusHandler.AddCondition(status.HandleDegraded("UnsupportedConfigOverridesValidation", "InvalidUnsupportedConfigOverrides", overridesErr))
+	// plugin CSP values that break the policy and invalid plugin proxies are left out
+	// of console-config, the plugins they came from are reported here.
+	cspErr := configmapsub.PluginCSPViolations(set.Operator, availablePlugins)
+	statusHandler.AddCondition(status.HandleDegraded("PluginCSPPolicy", "PluginCSPViolations", cspErr))
+	proxyErr := configmapsub.PluginProxyViolations(set.Operator, availablePlugins, pluginProxyCABundles)
+	statusHandler.AddCondition(status.HandleDegraded("PluginProxyValidation", "InvalidPluginProxies", proxyErr))
+	// an invalid OIDC inactivity timeout leaves console sessions without one
+	var inactivityTimeoutErr error
+	if authnConfig.Spec.Type == configv1.AuthenticationTypeOIDC {
+		_, inactivityTimeoutErr = configmapsub.GetOIDCInactivityTimeoutSeconds(set.Operator)
+	}
+	statusHandler.AddCondition(status.HandleDegraded("OIDCInactivityTimeout", "InvalidInactivityTimeout", inactivityTimeoutErr))
 	statusHandler.AddConditions(status.HandleProgressingOrDegraded("ConfigMapSync", cmErrReason, cmErr))
 	if cmErr != nil {
 		return statusHandler.FlushAndReturn(cmErr)
@@ -175,6 +241,24 @@ func (co *consoleOperator) sync_v400(ctx context.Context, controllerContext fact
 		return statusHandler.FlushAndReturn(secErr)
 	}
 
+	// invalid container resources fall back to the default ones
+	_, containerResourcesErr := deploymentsub.GetContainerResources(set.Operator)
+	statusHandler.AddCondition(status.HandleDegraded("ContainerResources", "InvalidContainerResources", containerResourcesErr))
+	resourceSizingConfigMap, resourceSizingErr := co.getResourceSizingConfigMap()
+	if resourceSizingErr != nil {
+		return statusHandler.FlushAndReturn(resourceSizingErr)
+	}
+	// an invalid zone spread falls back to the soft one
+	_, zoneSpreadErr := deploymentsub.GetZoneSpread(set.Operator)
+	statusHandler.AddCondition(status.HandleDegraded("TopologySpread", "InvalidZoneSpread", zoneSpreadErr))
+	nodes, nodesErr := co.nodeLister.List(labels.Everything())
+	if nodesErr != nil {
+		return statusHandler.FlushAndReturn(nodesErr)
+	}
+	// the console keeps its default placement until the custom one is valid and schedulable
+	placement, placementReason, placementErr := deploymentsub.GetSchedulablePlacement(set.Operator, co.configNSConfigMapLister, co.priorityClassLister, api.OpenShiftConsoleDeploymentName, set.Infrastructure, nodes)
+	statusHandler.AddCondition(status.HandleDegraded("ConsolePlacement", placementReason, placementErr))
+
 	actualDeployment, depChanged, depErrReason, depErr := co.SyncDeployment(
 		ctx,
 		set.Operator,
@@ -187,6 +271,9 @@ func (co *consoleOperator) sync_v400(ctx context.Context, controllerContext fact
 		sessionSecret,
 		set.Proxy,
 		set.Infrastructure,
+		resourceSizingConfigMap,
+		nodes,
+		placement,
 		customLogoCanMount,
 		controllerContext.Recorder(),
 	)
@@ -196,6 +283,12 @@ func (co *consoleOperator) sync_v400(ctx context.Context, controllerContext fact
 		return statusHandler.FlushAndReturn(depErr)
 	}
 
+	rollbackReason, rollbackErr := co.SyncConsoleConfigLastKnownGood(ctx, set.Operator, cm, actualDeployment, controllerContext.Recorder())
+	statusHandler.AddCondition(status.HandleDegraded("ConsoleConfigRolledBack", rollbackReason, rollbackErr))
+
+	canaryReason, canaryErr := co.SyncCanary(ctx, actualDeployment, controllerContext.Recorder())
+	statusHandler.AddConditions(status.HandleProgressingOrDegraded("CanaryRollout", canaryReason, canaryErr))
+
 	statusHandler.UpdateDeploymentGeneration(actualDeployment)
 	statusHandler.UpdateReadyReplicas(actualDeployment.Status.ReadyReplicas)
 	statusHandler.UpdateObservedGeneration(set.Operator.ObjectMeta.Generation)
@@ -291,6 +384,9 @@ func (co *consoleOperator) SyncDeployment(
 	sessionSecret *corev1.Secret,
 	proxyConfig *configv1.Proxy,
 	infrastructureConfig *configv1.Infrastructure,
+	resourceSizingConfigMap *corev1.ConfigMap,
+	nodes []*corev1.Node,
+	placement *deploymentsub.Placement,
 	canMountCustomLogo bool,
 	recorder events.Recorder,
 ) (consoleDeployment *appsv1.Deployment, changed bool, reason string, err error) {
@@ -306,8 +402,21 @@ func (co *consoleOperator) SyncDeployment(
 		sessionSecret,
 		proxyConfig,
 		infrastructureConfig,
+		resourceSizingConfigMap,
+		nodes,
+		placement,
 		canMountCustomLogo,
 	)
+	// the HPA owns the replicas of the autoscaled console
+	if hpasub.GetValidAutoscaling(operatorConfig) != nil {
+		existingDeployment, err := co.deploymentLister.Deployments(api.OpenShiftConsoleNamespace).Get(api.OpenShiftConsoleDeploymentName)
+		if err != nil && !apierrors.IsNotFound(err) {
+			return nil, false, "FailedGet", err
+		}
+		if existingDeployment != nil && existingDeployment.Spec.Replicas != nil {
+			requiredDeployment.Spec.Replicas = existingDeployment.Spec.Replicas
+		}
+	}
 	genChanged := operatorConfig.ObjectMeta.Generation != operatorConfig.Status.ObservedGeneration
 
 	if genChanged {
@@ -338,9 +447,12 @@ func (co *consoleOperator) SyncConfigMap(
 	consoleConfig *configv1.Console,
 	infrastructureConfig *configv1.Infrastructure,
 	oauthConfig *configv1.OAuth,
-	authServerCAConfig *corev1.ConfigMap,
+	sessionSecret *corev1.Secret,
 	authConfig *configv1.Authentication,
 	activeConsoleRoute *routev1.Route,
+	availablePlugins []*v1.ConsolePlugin,
+	pluginProxyCABundles map[string]string,
+	tlsProfile *configv1.TLSProfileSpec,
 	recorder events.Recorder,
 	consoleHost string,
 ) (consoleConfigMap *corev1.ConfigMap, changed bool, reason string, err error) {
@@ -358,7 +470,7 @@ func (co *consoleOperator) SyncConfigMap(
 	}
 	nodeArchitectures, nodeOperatingSystems := getNodeComputeEnvironments(nodeList)
 
-	// TODO: currently there's no way to get this for authentication type OIDC
+	// OIDC clusters have no OAuthClient nor OAuth config, their timeout is set on the operator config
 	inactivityTimeoutSeconds := 0
 	switch authConfig.Spec.Type {
 	case "", configv1.AuthenticationTypeIntegratedOAuth:
@@ -373,10 +485,10 @@ func (co *consoleOperator) SyncConfigMap(
 				inactivityTimeoutSeconds = int(oauthConfig.Spec.TokenConfig.AccessTokenInactivityTimeout.Seconds())
 			}
 		}
+	case configv1.AuthenticationTypeOIDC:
+		inactivityTimeoutSeconds, _ = configmapsub.GetOIDCInactivityTimeoutSeconds(operatorConfig)
 	}
 
-	availablePlugins := co.GetAvailablePlugins(operatorConfig.Spec.Plugins)
-
 	monitoringSharedConfig, mscErr := co.managedNSConfigMapLister.ConfigMaps(api.OpenShiftConfigManagedNamespace).Get(api.OpenShiftMonitoringConfigMapName)
 	if mscErr != nil {
 		if !apierrors.IsNotFound(mscErr) {
@@ -401,26 +513,54 @@ func (co *consoleOperator) SyncConfigMap(
 		}
 	}
 
-	defaultConfigmap, _, err := configmapsub.DefaultConfigMap(
-		operatorConfig,
-		consoleConfig,
-		authConfig,
-		authServerCAConfig,
-		managedConfig,
-		monitoringSharedConfig,
-		infrastructureConfig,
-		activeConsoleRoute,
-		inactivityTimeoutSeconds,
-		availablePlugins,
-		nodeArchitectures,
-		nodeOperatingSystems,
-		copiedCSVsDisabled,
-		telemetryConfig,
-		consoleHost,
-	)
+	defaultConfigmap, provenanceConfigMap, _, err := configmapsub.DefaultConfigMap(configmapsub.DefaultConfigMapOptions{
+		OperatorConfig:           operatorConfig,
+		ConsoleConfig:            consoleConfig,
+		AuthConfig:               authConfig,
+		SessionSecret:            sessionSecret,
+		ManagedConfig:            managedConfig,
+		MonitoringSharedConfig:   monitoringSharedConfig,
+		InfrastructureConfig:     infrastructureConfig,
+		TLSProfile:               tlsProfile,
+		ActiveConsoleRoute:       activeConsoleRoute,
+		InactivityTimeoutSeconds: inactivityTimeoutSeconds,
+		AvailablePlugins:         availablePlugins,
+		PluginProxyCABundles:     pluginProxyCABundles,
+		NodeArchitectures:        nodeArchitectures,
+		NodeOperatingSystems:     nodeOperatingSystems,
+		CopiedCSVsDisabled:       copiedCSVsDisabled,
+		TelemeterConfig:          telemetryConfig,
+		ConsoleHost:              consoleHost,
+	})
+	if customerrors.IsUnsupportedConfigOverridesError(err) {
+		// keep the console running on the config it already has
+		previousConfigMap, getErr := co.targetNSConfigMapLister.ConfigMaps(api.TargetNamespace).Get(api.OpenShiftConsoleConfigMapName)
+		if getErr != nil {
+			return nil, false, "InvalidUnsupportedConfigOverrides", err
+		}
+		return previousConfigMap, false, "InvalidUnsupportedConfigOverrides", err
+	}
 	if err != nil {
 		return nil, false, "FailedConsoleConfigBuilder", err
 	}
+	if reason, err := co.holdUnverifiedAuthChange(defaultConfigmap); err != nil {
+		return nil, false, reason, err
+	}
+	lastKnownGood, lkgErr := co.getConsoleConfigLastKnownGood()
+	if lkgErr != nil {
+		return nil, false, "FailedGetLastKnownGood", lkgErr
+	}
+	if configmapsub.WithLastKnownGood(defaultConfigmap, lastKnownGood) {
+		klog.V(4).Infoln("console-config was rejected before, keeping the last known good config")
+	}
+	heldConfigMap, canaryReason, canaryErr := co.syncCanaryConfigMap(ctx, operatorConfig, defaultConfigmap, recorder)
+	if canaryErr != nil {
+		return nil, false, canaryReason, canaryErr
+	}
+	if heldConfigMap != nil {
+		klog.V(4).Infoln("console-config change is rolling out to a canary, keeping the live config")
+		return heldConfigMap, false, "", nil
+	}
 	cm, cmChanged, cmErr := resourceapply.ApplyConfigMap(ctx, co.configMapClient, recorder, defaultConfigmap)
 	if cmErr != nil {
 		return nil, false, "FailedApply", cmErr
@@ -429,9 +569,288 @@ func (co *consoleOperator) SyncConfigMap(
 		klog.V(4).Infoln("new console config yaml:")
 		klog.V(4).Infof("%s", cm.Data)
 	}
+	if _, _, provenanceErr := resourceapply.ApplyConfigMap(ctx, co.configMapClient, recorder, provenanceConfigMap); provenanceErr != nil {
+		return nil, false, "FailedApplyProvenance", provenanceErr
+	}
 	return cm, cmChanged, "ConsoleConfigBuilder", cmErr
 }
 
+// SyncConsoleConfigLastKnownGood snapshots console-config once the console has rolled out
+// with it, and marks it as rejected when the console pods are still failing on it after the
+// rollback window. The next SyncConfigMap then restores the snapshot in place of the
+// rejected config.
+func (co *consoleOperator) SyncConsoleConfigLastKnownGood(
+	ctx context.Context,
+	operatorConfig *operatorv1.Console,
+	consoleConfigMap *corev1.ConfigMap,
+	consoleDeployment *appsv1.Deployment,
+	recorder events.Recorder,
+) (reason string, err error) {
+	lastKnownGood, err := co.getConsoleConfigLastKnownGood()
+	if err != nil {
+		return "FailedGetLastKnownGood", err
+	}
+	podSelector, err := metav1.LabelSelectorAsSelector(consoleDeployment.Spec.Selector)
+	if err != nil {
+		return "FailedPodSelector", err
+	}
+	consolePods, err := co.podLister.Pods(api.TargetNamespace).List(podSelector)
+	if err != nil {
+		return "FailedListPods", err
+	}
+
+	nextLastKnownGood, err := configmapsub.NextLastKnownGood(
+		operatorConfig,
+		lastKnownGood,
+		consoleConfigMap,
+		deploymentsub.IsConsoleConfigRolledOut(consoleDeployment, consoleConfigMap),
+		deploymentsub.IsConsoleConfigFailing(consolePods, consoleConfigMap),
+		configmapsub.ConfigRollbackWindow(operatorConfig),
+		time.Now(),
+	)
+	if err != nil {
+		return "FailedLastKnownGood", err
+	}
+	if nextLastKnownGood != nil {
+		if _, _, err := resourceapply.ApplyConfigMap(ctx, co.configMapClient, recorder, nextLastKnownGood); err != nil {
+			return "FailedApplyLastKnownGood", err
+		}
+	}
+
+	if err := configmapsub.RolledBackError(consoleConfigMap, lastKnownGood); err != nil {
+		return "RolloutNotProgressing", err
+	}
+	return "", nil
+}
+
+// getAuthServerCAConfig returns the copy of the OIDC provider's CA the console deployment
+// mounts. While the provider's issuer has not passed the discovery pre-flight, the deployment
+// keeps the CA it already mounts, as console-config keeps its auth and console-oauth-config
+// its client secret.
+func (co *consoleOperator) getAuthServerCAConfig(oidcProvider configv1.OIDCProvider) (*corev1.ConfigMap, error) {
+	discoveryConfigMap, err := co.targetNSConfigMapLister.ConfigMaps(api.TargetNamespace).Get(api.ConsoleOIDCDiscoveryName)
+	if err != nil && !apierrors.IsNotFound(err) {
+		return nil, err
+	}
+	if apierrors.IsNotFound(err) {
+		discoveryConfigMap = nil
+	}
+
+	caName := oidcProvider.Issuer.CertificateAuthority.Name
+	if !configmapsub.IsOIDCIssuerVerified(discoveryConfigMap, oidcProvider.Issuer.URL) {
+		existingDeployment, err := co.deploymentLister.Deployments(api.TargetNamespace).Get(api.OpenShiftConsoleDeploymentName)
+		if err != nil && !apierrors.IsNotFound(err) {
+			return nil, err
+		}
+		// nothing to keep on a fresh install
+		if err == nil {
+			klog.V(4).Infof("OIDC issuer %q has not passed the discovery, keeping the CA of the live deployment", oidcProvider.Issuer.URL)
+			caName = deploymentsub.GetAuthServerCAConfigMapName(existingDeployment)
+		}
+	}
+	if len(caName) == 0 {
+		return nil, nil
+	}
+
+	// the oidc-setup controller copies the CA to the target namespace once the issuer passed
+	// the discovery
+	authServerCAConfig, err := co.targetNSConfigMapLister.ConfigMaps(api.TargetNamespace).Get(caName)
+	if apierrors.IsNotFound(err) {
+		return nil, nil
+	}
+	return authServerCAConfig, err
+}
+
+// holdUnverifiedAuthChange keeps the auth of the live console-config in the generated one
+// when the generated auth changes to OIDC issuers that have not passed the discovery
+// pre-flight yet. The rest of the generated config is left as is.
+func (co *consoleOperator) holdUnverifiedAuthChange(generatedConfigMap *corev1.ConfigMap) (string, error) {
+	discoveryConfigMap, err := co.targetNSConfigMapLister.ConfigMaps(api.TargetNamespace).Get(api.ConsoleOIDCDiscoveryName)
+	if err != nil && !apierrors.IsNotFound(err) {
+		return "FailedGetOIDCDiscovery", err
+	}
+	if apierrors.IsNotFound(err) {
+		discoveryConfigMap = nil
+	}
+	unverifiedIssuers, err := configmapsub.UnverifiedOIDCIssuers(generatedConfigMap, discoveryConfigMap)
+	if err != nil {
+		return "FailedUnverifiedOIDCIssuers", err
+	}
+	if len(unverifiedIssuers) == 0 {
+		return "", nil
+	}
+
+	consoleConfigMap, err := co.targetNSConfigMapLister.ConfigMaps(api.TargetNamespace).Get(api.OpenShiftConsoleConfigMapName)
+	// nothing to keep on a fresh install
+	if apierrors.IsNotFound(err) {
+		return "", nil
+	}
+	if err != nil {
+		return "FailedGet", err
+	}
+	authChangedPaths, err := configmapsub.AuthChangedPaths(consoleConfigMap, generatedConfigMap)
+	if err != nil {
+		return "FailedAuthChangedPaths", err
+	}
+	if len(authChangedPaths) == 0 {
+		return "", nil
+	}
+	klog.V(4).Infof("OIDC issuers %v have not passed the discovery, keeping the auth of the live config", unverifiedIssuers)
+	if err := configmapsub.WithLiveAuth(generatedConfigMap, consoleConfigMap); err != nil {
+		return "FailedHoldAuthChange", err
+	}
+	return "", nil
+}
+
+// syncCanaryConfigMap holds risky console-config changes back in the canary config map
+// when the canary rollout is enabled, and returns the live console-config to keep using.
+// It returns nil once the generated config can be applied, removing the canary config map
+// left by a canary that is over.
+func (co *consoleOperator) syncCanaryConfigMap(
+	ctx context.Context,
+	operatorConfig *operatorv1.Console,
+	generatedConfigMap *corev1.ConfigMap,
+	recorder events.Recorder,
+) (*corev1.ConfigMap, string, error) {
+	canaryConfigMap, err := co.targetNSConfigMapLister.ConfigMaps(api.TargetNamespace).Get(api.ConsoleCanaryConfigMapName)
+	if err != nil && !apierrors.IsNotFound(err) {
+		return nil, "FailedGetCanary", err
+	}
+	if apierrors.IsNotFound(err) {
+		canaryConfigMap = nil
+	}
+
+	var candidate, consoleConfigMap *corev1.ConfigMap
+	if configmapsub.IsCanaryRolloutEnabled(operatorConfig) {
+		consoleConfigMap, err = co.targetNSConfigMapLister.ConfigMaps(api.TargetNamespace).Get(api.OpenShiftConsoleConfigMapName)
+		if err != nil && !apierrors.IsNotFound(err) {
+			return nil, "FailedGet", err
+		}
+		if apierrors.IsNotFound(err) {
+			consoleConfigMap = nil
+		}
+		candidate, err = configmapsub.CanaryCandidate(operatorConfig, generatedConfigMap, consoleConfigMap, canaryConfigMap, time.Now())
+		if err != nil {
+			return nil, "FailedCanaryCandidate", err
+		}
+	}
+
+	if candidate == nil {
+		if canaryConfigMap != nil {
+			err := co.configMapClient.ConfigMaps(api.TargetNamespace).Delete(ctx, api.ConsoleCanaryConfigMapName, metav1.DeleteOptions{})
+			if err != nil && !apierrors.IsNotFound(err) {
+				return nil, "FailedDeleteCanary", err
+			}
+		}
+		return nil, "", nil
+	}
+	if candidate != canaryConfigMap {
+		klog.V(2).Infof("rolling console-config change out to the %s canary", api.ConsoleCanaryName)
+		if _, _, err := resourceapply.ApplyConfigMap(ctx, co.configMapClient, recorder, candidate); err != nil {
+			return nil, "FailedApplyCanary", err
+		}
+	}
+	return consoleConfigMap, "", nil
+}
+
+// SyncCanary runs the console-config held back in the canary config map on a single pod
+// next to the console deployment. The change is promoted once the canary answers its
+// health check through its service, and aborted when it does not within CanaryTimeout.
+// The canary deployment and service are removed once the canary is over.
+func (co *consoleOperator) SyncCanary(
+	ctx context.Context,
+	consoleDeployment *appsv1.Deployment,
+	recorder events.Recorder,
+) (reason string, err error) {
+	canaryConfigMap, err := co.targetNSConfigMapLister.ConfigMaps(api.TargetNamespace).Get(api.ConsoleCanaryConfigMapName)
+	if err != nil && !apierrors.IsNotFound(err) {
+		return "FailedGetCanary", err
+	}
+	if apierrors.IsNotFound(err) || len(configmapsub.CanaryOutcome(canaryConfigMap)) != 0 {
+		if err := co.removeCanary(ctx); err != nil {
+			return "FailedRemoveCanary", err
+		}
+		if canaryConfigMap == nil || configmapsub.CanaryOutcome(canaryConfigMap) == configmapsub.CanaryOutcomePromoted {
+			return "", nil
+		}
+		return "CanaryAborted", configmapsub.CanaryError(canaryConfigMap)
+	}
+
+	if _, _, err := resourceapply.ApplyService(ctx, co.serviceClient, recorder, deploymentsub.CanaryService()); err != nil {
+		return "FailedApplyCanaryService", err
+	}
+	expectedGeneration := int64(-1)
+	existingCanary, err := co.deploymentLister.Deployments(api.TargetNamespace).Get(api.ConsoleCanaryName)
+	if err == nil {
+		expectedGeneration = existingCanary.Generation
+	}
+	canaryDeployment, _, err := resourceapply.ApplyDeployment(
+		ctx,
+		co.deploymentClient,
+		recorder,
+		deploymentsub.CanaryDeployment(consoleDeployment, canaryConfigMap),
+		expectedGeneration,
+	)
+	if err != nil {
+		return "FailedApplyCanaryDeployment", err
+	}
+
+	healthErr := fmt.Errorf("%s deployment is not available", api.ConsoleCanaryName)
+	if deploymentsub.IsConsoleConfigRolledOut(canaryDeployment, canaryConfigMap) {
+		serviceCAConfigMap, err := co.targetNSConfigMapLister.ConfigMaps(api.TargetNamespace).Get(api.ServiceCAConfigMapName)
+		if err != nil {
+			return "FailedGetServiceCA", err
+		}
+		_, healthErr = healthcheck.CheckCanaryHealth(serviceCAConfigMap)
+	}
+
+	nextCanaryConfigMap := configmapsub.NextCanary(canaryConfigMap, healthErr, configmapsub.CanaryTimeout, time.Now())
+	if nextCanaryConfigMap == nil {
+		return "CanaryInProgress", configmapsub.CanaryError(canaryConfigMap)
+	}
+	if _, _, err := resourceapply.ApplyConfigMap(ctx, co.configMapClient, recorder, nextCanaryConfigMap); err != nil {
+		return "FailedApplyCanary", err
+	}
+	if configmapsub.CanaryOutcome(nextCanaryConfigMap) == configmapsub.CanaryOutcomePromoted {
+		recorder.Eventf("CanaryPromoted", "console-config change passed its canary health check and is rolled out to the %s deployment", api.OpenShiftConsoleDeploymentName)
+		return "", nil
+	}
+	canaryErr := configmapsub.CanaryError(nextCanaryConfigMap)
+	recorder.Warningf("CanaryAborted", "%v", canaryErr)
+	return "CanaryAborted", canaryErr
+}
+
+func (co *consoleOperator) removeCanary(ctx context.Context) error {
+	errs := []error{
+		co.deploymentClient.Deployments(api.TargetNamespace).Delete(ctx, api.ConsoleCanaryName, metav1.DeleteOptions{}),
+		co.serviceClient.Services(api.TargetNamespace).Delete(ctx, api.ConsoleCanaryName, metav1.DeleteOptions{}),
+	}
+	return utilerrors.FilterOut(utilerrors.NewAggregate(errs), apierrors.IsNotFound)
+}
+
+// getPluginProxyCABundles returns the CA bundles the external plugin proxies reference,
+// missing config maps are left out and reported when the proxies are validated.
+func (co *consoleOperator) getPluginProxyCABundles(plugins []*v1.ConsolePlugin) map[string]string {
+	configMaps := []*corev1.ConfigMap{}
+	for _, name := range configmapsub.ExternalProxyCAConfigMapNames(plugins) {
+		configMap, err := co.configNSConfigMapLister.ConfigMaps(api.OpenShiftConfigNamespace).Get(name)
+		if err != nil {
+			klog.V(4).Infof("failed to get plugin proxy CA config map %q: %v", name, err)
+			continue
+		}
+		configMaps = append(configMaps, configMap)
+	}
+	return configmapsub.PluginProxyCABundles(configMaps)
+}
+
+func (co *consoleOperator) getConsoleConfigLastKnownGood() (*corev1.ConfigMap, error) {
+	lastKnownGood, err := co.targetNSConfigMapLister.ConfigMaps(api.TargetNamespace).Get(api.ConsoleConfigLastKnownGoodName)
+	if apierrors.IsNotFound(err) {
+		return nil, nil
+	}
+	return lastKnownGood, err
+}
+
 // Build telemetry configuration in following order:
 //  1. check if the telemetry client is available and set the "TELEMETER_CLIENT_DISABLED" annotation accordingly
 //  2. get telemetry annotation from console-operator config
@@ -634,7 +1053,19 @@ func (co *consoleOperator) ValidateCustomLogo(ctx context.Context, operatorConfi
 	return true, "", nil
 }
 
+// GetAvailablePlugins returns the enabled plugins that exist and were not quarantined
+// by the console plugins controller after their backend failed to respond.
 func (co *consoleOperator) GetAvailablePlugins(enabledPluginsNames []string) []*v1.ConsolePlugin {
+	quarantined := map[string]string{}
+	probed := sets.NewString()
+	quarantineConfigMap, err := co.targetNSConfigMapLister.ConfigMaps(api.TargetNamespace).Get(api.ConsolePluginQuarantineName)
+	if err == nil {
+		quarantined = quarantineConfigMap.Data
+		probed = configmapsub.GetProbedPlugins(quarantineConfigMap)
+	} else if !apierrors.IsNotFound(err) {
+		klog.Errorf("failed to get plugin quarantine: %v", err)
+	}
+
 	var availablePlugins []*v1.ConsolePlugin
 	for _, pluginName := range utilsub.RemoveDuplicateStr(enabledPluginsNames) {
 		plugin, err := co.consolePluginLister.Get(pluginName)
@@ -642,6 +1073,14 @@ func (co *consoleOperator) GetAvailablePlugins(enabledPluginsNames []string) []*
 			klog.Errorf("failed to get %q plugin: %v", pluginName, err)
 			continue
 		}
+		if reason, ok := quarantined[pluginName]; ok {
+			klog.Warningf("skipping quarantined %q plugin: %s", pluginName, reason)
+			continue
+		}
+		if plugin.Annotations[api.PluginBackendProbeAnnotation] == "true" && !probed.Has(pluginName) {
+			klog.V(4).Infof("skipping %q plugin until its backend passes a probe", pluginName)
+			continue
+		}
 		availablePlugins = append(availablePlugins, plugin)
 	}
 	return availablePlugins
@@ -684,6 +1123,7 @@ func (co *consoleOperator) isCopiedCSVsDisabled(ctx context.Context) (bool, erro
 func (co *consoleOperator) syncSessionSecret(
 	ctx context.Context,
 	operatorConfig *operatorv1.Console,
+	rotation *secretsub.SessionSecretRotation,
 	recorder events.Recorder,
 ) (*corev1.Secret, error) {
 
@@ -692,17 +1132,70 @@ func (co *consoleOperator) syncSessionSecret(
 		return nil, err
 	}
 
-	var required *corev1.Secret
+	var (
+		required *corev1.Secret
+		rotated  bool
+	)
 	if sessionSecret == nil {
 		required = secretsub.DefaultSessionSecret(operatorConfig)
 	} else {
+		now := time.Now()
 		required = sessionSecret.DeepCopy()
-		changed := secretsub.ResetSessionSecretKeysIfNeeded(required)
-		if !changed {
+		changed := secretsub.ResetSessionSecretKeysIfNeeded(required, now)
+		rotationChanged, keysRotated := secretsub.RotateSessionSecretKeysIfNeeded(required, rotation, now)
+		rotated = keysRotated
+		if !changed && !rotationChanged {
+			created, _ := secretsub.SessionKeysCreated(required)
+			metrics.HandleSessionKeys(created, false)
 			return required, nil
 		}
 	}
 
 	secret, _, err := resourceapply.ApplySecret(ctx, co.secretsClient, recorder, required)
-	return secret, err
+	if err != nil {
+		return nil, err
+	}
+	if rotated {
+		expire, _ := secretsub.PreviousSessionKeysExpire(secret)
+		recorder.Eventf("SessionSecretRotated", "console session keys rotated, the previous keys are accepted until %s", expire.Format(time.RFC3339))
+	}
+	created, _ := secretsub.SessionKeysCreated(secret)
+	metrics.HandleSessionKeys(created, rotated)
+	return secret, nil
+}
+
+// sessionSecretRotationStatus reports when the session keys were created and rotate next,
+// and until when the previous keys are accepted after a rotation. It is cleared when the
+// keys are not rotated.
+func sessionSecretRotationStatus(sessionSecret *corev1.Secret, rotation *secretsub.SessionSecretRotation) status.ConditionUpdate {
+	if sessionSecret == nil || rotation == nil {
+		return status.HandleActive("SessionSecretRotation", false, "", "")
+	}
+	created, _ := secretsub.SessionKeysCreated(sessionSecret)
+	message := fmt.Sprintf("session keys created at %s, next rotation at %s", created.Format(time.RFC3339), created.Add(rotation.Period).Format(time.RFC3339))
+	if expire, ok := secretsub.PreviousSessionKeysExpire(sessionSecret); ok {
+		message += fmt.Sprintf(", previous session keys accepted until %s", expire.Format(time.RFC3339))
+		return status.HandleActive("SessionSecretRotation", true, "PreviousKeysAccepted", message)
+	}
+	return status.HandleActive("SessionSecretRotation", true, "CurrentKeysOnly", message)
+}
+
+// getAPIServerConfig returns the cluster APIServer config, which carries the cluster
+// TLS security profile, or nil if there is none.
+func (co *consoleOperator) getAPIServerConfig() (*configv1.APIServer, error) {
+	apiServerConfig, err := co.apiServerLister.Get(api.ConfigResourceName)
+	if apierrors.IsNotFound(err) {
+		return nil, nil
+	}
+	return apiServerConfig, err
+}
+
+// getResourceSizingConfigMap returns the requests recommended by the auto-sizing, nil
+// until the auto-sizing stored any.
+func (co *consoleOperator) getResourceSizingConfigMap() (*corev1.ConfigMap, error) {
+	configMap, err := co.targetNSConfigMapLister.ConfigMaps(api.OpenShiftConsoleNamespace).Get(api.ResourceSizingConfigMapName)
+	if apierrors.IsNotFound(err) {
+		return nil, nil
+	}
+	return configMap, err
 }
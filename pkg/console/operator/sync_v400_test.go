@@ -1,12 +1,23 @@
 package operator
 
 import (
+	"fmt"
 	"testing"
+	"time"
 
 	"github.com/go-test/deep"
+	configv1 "github.com/openshift/api/config/v1"
+	operatorv1 "github.com/openshift/api/operator/v1"
 	"github.com/openshift/console-operator/pkg/api"
+	configmapsub "github.com/openshift/console-operator/pkg/console/subresource/configmap"
+	deploymentsub "github.com/openshift/console-operator/pkg/console/subresource/deployment"
+	secretsub "github.com/openshift/console-operator/pkg/console/subresource/secret"
 	v1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	appsv1listers "k8s.io/client-go/listers/apps/v1"
+	corev1listers "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
 )
 
 func TestGetNodeComputeEnvironments(t *testing.T) {
@@ -212,3 +223,151 @@ func TestGetNodeComputeEnvironments(t *testing.T) {
 		})
 	}
 }
+
+func TestGetAuthServerCAConfig(t *testing.T) {
+	operatorConfig := &operatorv1.Console{}
+	caConfigMap := func(name string) *v1.ConfigMap {
+		return &v1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: api.TargetNamespace},
+			Data:       map[string]string{"ca-bundle.crt": name},
+		}
+	}
+	oidcProvider := func(issuer, caName string) configv1.OIDCProvider {
+		return configv1.OIDCProvider{
+			Issuer: configv1.TokenIssuer{
+				URL:                  issuer,
+				CertificateAuthority: configv1.ConfigMapNameReference{Name: caName},
+			},
+		}
+	}
+	liveDeployment := deploymentsub.DefaultDeployment(operatorConfig, &v1.ConfigMap{}, &v1.ConfigMap{}, nil, caConfigMap("old-ca"), &v1.ConfigMap{}, &v1.Secret{}, nil, &configv1.Proxy{}, &configv1.Infrastructure{}, nil, nil, nil, false)
+
+	tests := []struct {
+		name            string
+		provider        configv1.OIDCProvider
+		verifiedIssuers sets.Set[string]
+		liveDeployment  bool
+		want            *v1.ConfigMap
+	}{
+		{
+			name:            "Issuer verified",
+			provider:        oidcProvider("https://new.example.com", "new-ca"),
+			verifiedIssuers: sets.New("https://new.example.com"),
+			liveDeployment:  true,
+			want:            caConfigMap("new-ca"),
+		},
+		{
+			name:            "Issuer change that fails discovery keeps the live CA",
+			provider:        oidcProvider("https://new.example.com", "new-ca"),
+			verifiedIssuers: sets.New("https://old.example.com"),
+			liveDeployment:  true,
+			want:            caConfigMap("old-ca"),
+		},
+		{
+			name:            "Issuer that fails discovery on a fresh install",
+			provider:        oidcProvider("https://new.example.com", "new-ca"),
+			verifiedIssuers: sets.New[string](),
+			want:            caConfigMap("new-ca"),
+		},
+		{
+			name:            "Issuer verified without a CA",
+			provider:        oidcProvider("https://new.example.com", ""),
+			verifiedIssuers: sets.New("https://new.example.com"),
+			liveDeployment:  true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			configMapIndexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
+			configMapIndexer.Add(caConfigMap("old-ca"))
+			configMapIndexer.Add(caConfigMap("new-ca"))
+			configMapIndexer.Add(configmapsub.DefaultOIDCDiscoveryConfigMap(operatorConfig, tt.verifiedIssuers))
+			deploymentIndexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
+			if tt.liveDeployment {
+				deploymentIndexer.Add(liveDeployment)
+			}
+			co := &consoleOperator{
+				targetNSConfigMapLister: corev1listers.NewConfigMapLister(configMapIndexer),
+				deploymentLister:        appsv1listers.NewDeploymentLister(deploymentIndexer),
+			}
+
+			got, err := co.getAuthServerCAConfig(tt.provider)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if diff := deep.Equal(tt.want, got); diff != nil {
+				t.Error(diff)
+			}
+		})
+	}
+}
+
+func TestSessionSecretRotationStatus(t *testing.T) {
+	operatorConfig := &operatorv1.Console{}
+	rotation := &secretsub.SessionSecretRotation{Period: 720 * time.Hour, Overlap: 24 * time.Hour}
+	sessionSecret := secretsub.DefaultSessionSecret(operatorConfig)
+	created, _ := secretsub.SessionKeysCreated(sessionSecret)
+	rotatedSecret := sessionSecret.DeepCopy()
+	secretsub.RotateSessionSecretKeysIfNeeded(rotatedSecret, rotation, created.Add(rotation.Period))
+	rotated, _ := secretsub.SessionKeysCreated(rotatedSecret)
+	expire, _ := secretsub.PreviousSessionKeysExpire(rotatedSecret)
+
+	tests := []struct {
+		name          string
+		sessionSecret *v1.Secret
+		rotation      *secretsub.SessionSecretRotation
+		want          operatorv1.OperatorCondition
+	}{
+		{
+			name:          "Rotation disabled",
+			sessionSecret: sessionSecret,
+			want: operatorv1.OperatorCondition{
+				Type:   "SessionSecretRotationActive",
+				Status: operatorv1.ConditionFalse,
+			},
+		},
+		{
+			name:     "No session secret",
+			rotation: rotation,
+			want: operatorv1.OperatorCondition{
+				Type:   "SessionSecretRotationActive",
+				Status: operatorv1.ConditionFalse,
+			},
+		},
+		{
+			name:          "Current keys only",
+			sessionSecret: sessionSecret,
+			rotation:      rotation,
+			want: operatorv1.OperatorCondition{
+				Type:    "SessionSecretRotationActive",
+				Status:  operatorv1.ConditionTrue,
+				Reason:  "CurrentKeysOnly",
+				Message: fmt.Sprintf("session keys created at %s, next rotation at %s", created.Format(time.RFC3339), created.Add(rotation.Period).Format(time.RFC3339)),
+			},
+		},
+		{
+			name:          "Previous keys accepted",
+			sessionSecret: rotatedSecret,
+			rotation:      rotation,
+			want: operatorv1.OperatorCondition{
+				Type:    "SessionSecretRotationActive",
+				Status:  operatorv1.ConditionTrue,
+				Reason:  "PreviousKeysAccepted",
+				Message: fmt.Sprintf("session keys created at %s, next rotation at %s, previous session keys accepted until %s", rotated.Format(time.RFC3339), rotated.Add(rotation.Period).Format(time.RFC3339), expire.Format(time.RFC3339)),
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			operatorStatus := &operatorv1.OperatorStatus{}
+			if err := sessionSecretRotationStatus(tt.sessionSecret, tt.rotation).StatusUpdateFn(operatorStatus); err != nil {
+				t.Fatal(err)
+			}
+			got := operatorStatus.Conditions[0]
+			got.LastTransitionTime = metav1.Time{}
+			if diff := deep.Equal(tt.want, got); diff != nil {
+				t.Error(diff)
+			}
+		})
+	}
+}
@@ -29,12 +29,16 @@ import (
 	"github.com/openshift/console-operator/pkg/console/clientwrapper"
 	"github.com/openshift/console-operator/pkg/console/controllers/clidownloads"
 	"github.com/openshift/console-operator/pkg/console/controllers/clioidcclientstatus"
+	"github.com/openshift/console-operator/pkg/console/controllers/consoleplugins"
 	"github.com/openshift/console-operator/pkg/console/controllers/downloadsdeployment"
 	"github.com/openshift/console-operator/pkg/console/controllers/healthcheck"
+	hpa "github.com/openshift/console-operator/pkg/console/controllers/horizontalpodautoscaler"
+	"github.com/openshift/console-operator/pkg/console/controllers/httproute"
 	"github.com/openshift/console-operator/pkg/console/controllers/oauthclients"
 	"github.com/openshift/console-operator/pkg/console/controllers/oauthclientsecret"
 	"github.com/openshift/console-operator/pkg/console/controllers/oidcsetup"
 	pdb "github.com/openshift/console-operator/pkg/console/controllers/poddisruptionbudget"
+	"github.com/openshift/console-operator/pkg/console/controllers/resourcesizing"
 	"github.com/openshift/console-operator/pkg/console/controllers/route"
 	"github.com/openshift/console-operator/pkg/console/controllers/service"
 	upgradenotification "github.com/openshift/console-operator/pkg/console/controllers/upgradenotification"
@@ -111,6 +115,9 @@ func RunOperator(ctx context.Context, controllerContext *controllercmd.Controlle
 
 	const resync = 10 * time.Minute
 
+	// plugin backend services and endpoint slices can live in any namespace
+	kubeInformers := informers.NewSharedInformerFactory(kubeClient, resync)
+
 	kubeInformersNamespaced := informers.NewSharedInformerFactoryWithOptions(
 		kubeClient,
 		resync,
@@ -233,8 +240,9 @@ func RunOperator(ctx context.Context, controllerContext *controllercmd.Controlle
 		operatorConfigClient.OperatorV1(),
 		operatorConfigInformers.Operator().V1().Consoles(), // OperatorConfig
 		// core resources
-		kubeClient.CoreV1(),                 // Secrets, ConfigMaps, Service
-		kubeInformersNamespaced.Core().V1(), // Secrets, ConfigMaps, Service
+		kubeClient.CoreV1(),                                         // Secrets, ConfigMaps, Service
+		kubeInformersNamespaced.Core().V1(),                         // Secrets, ConfigMaps, Service, Pods
+		kubeInformersNamespaced.Scheduling().V1().PriorityClasses(), // PriorityClasses, for the placement
 		// deployments
 		kubeClient.AppsV1(),
 		kubeInformersNamespaced.Apps().V1().Deployments(), // Deployments
@@ -242,7 +250,8 @@ func RunOperator(ctx context.Context, controllerContext *controllercmd.Controlle
 		// oauth
 		oauthClientsSwitchedInformer,
 		// routes
-		routesInformersNamespaced.Route().V1().Routes(), // Route
+		routesInformersNamespaced.Route().V1().Routes(),       // Route
+		kubeInformersNamespaced.Networking().V1().Ingresses(), // Ingress
 		// plugins
 		consoleInformers.Console().V1().ConsolePlugins(),
 		// openshift
@@ -266,9 +275,11 @@ func RunOperator(ctx context.Context, controllerContext *controllercmd.Controlle
 	oauthClientController := oauthclients.NewOAuthClientsController(
 		operatorClient,
 		oauthClient,
+		dynamicClient,
 		configInformers.Config().V1().Authentications(),
 		operatorConfigInformers.Operator().V1().Consoles(),
 		routesInformersNamespaced.Route().V1().Routes(),
+		kubeInformersNamespaced.Networking().V1().Ingresses(),
 		configInformers.Config().V1().Ingresses(),
 		kubeInformersNamespaced.Core().V1().Secrets(),
 		oauthClientsSwitchedInformer,
@@ -282,6 +293,9 @@ func RunOperator(ctx context.Context, controllerContext *controllercmd.Controlle
 		operatorConfigInformers.Operator().V1().Consoles(),
 		kubeInformersConfigNamespaced.Core().V1().Secrets(),
 		kubeInformersNamespaced.Core().V1().Secrets(),
+		kubeInformersNamespaced.Core().V1().ConfigMaps(),
+		kubeInformersNamespaced.Apps().V1().Deployments(),
+		oauthClientsSwitchedInformer,
 		recorder,
 	)
 
@@ -291,6 +305,7 @@ func RunOperator(ctx context.Context, controllerContext *controllercmd.Controlle
 		kubeClient.CoreV1(),
 		configInformers.Config().V1().Authentications(),
 		configClient.ConfigV1().Authentications(),
+		configInformers.Config().V1().Proxies(),
 		operatorConfigInformers.Operator().V1().Consoles(),
 		kubeInformersConfigNamespaced.Core().V1().ConfigMaps(),
 		kubeInformersNamespaced.Core().V1().Secrets(),
@@ -317,7 +332,11 @@ func RunOperator(ctx context.Context, controllerContext *controllercmd.Controlle
 		operatorConfigInformers.Operator().V1().Consoles(),
 
 		kubeClient.AppsV1(), // Deployments
-		kubeInformersNamespaced.Apps().V1().Deployments(), // Deployments
+		kubeInformersNamespaced.Apps().V1().Deployments(),           // Deployments
+		kubeInformersNamespaced.Core().V1().ConfigMaps(),            // `openshift-console` namespace informers
+		kubeInformersNamespaced.Core().V1().Nodes(),                 // Nodes
+		kubeInformersNamespaced.Scheduling().V1().PriorityClasses(), // PriorityClasses, for the placement
+		kubeInformersConfigNamespaced.Core().V1().ConfigMaps(),      // `openshift-config` namespace informers
 		recorder,
 	)
 
@@ -327,11 +346,13 @@ func RunOperator(ctx context.Context, controllerContext *controllercmd.Controlle
 		// clients
 		operatorClient,
 		consoleClient.ConsoleV1().ConsoleCLIDownloads(),
+		dynamicClient,
 		// informers
 		operatorConfigInformers.Operator().V1().Consoles(), // OperatorConfig
 		configInformers, // Config
 		consoleInformers.Console().V1().ConsoleCLIDownloads(), // ConsoleCliDownloads
 		routesInformersNamespaced.Route().V1().Routes(),       // Routes
+		kubeInformersNamespaced.Networking().V1().Ingresses(), // Ingresses
 		// events
 		recorder,
 	)
@@ -375,9 +396,12 @@ func RunOperator(ctx context.Context, controllerContext *controllercmd.Controlle
 		// clients
 		operatorClient,
 		routesClient.RouteV1(),
+		kubeClient.NetworkingV1(),
+		kubeClient.CoreV1(),
 		// route
 		operatorConfigInformers.Operator().V1().Consoles(),
 		kubeInformersConfigNamespaced.Core().V1().Secrets(), // `openshift-config` namespace informers
+		kubeInformersNamespaced.Core().V1().ConfigMaps(),    // `openshift-console` namespace informers
 		routesInformersNamespaced.Route().V1().Routes(),
 		// events
 		recorder,
@@ -392,24 +416,59 @@ func RunOperator(ctx context.Context, controllerContext *controllercmd.Controlle
 		// clients
 		operatorClient,
 		routesClient.RouteV1(),
+		kubeClient.NetworkingV1(),
+		kubeClient.CoreV1(),
 		// route
 		operatorConfigInformers.Operator().V1().Consoles(),
 		kubeInformersConfigNamespaced.Core().V1().Secrets(), // `openshift-config` namespace informers
+		kubeInformersNamespaced.Core().V1().ConfigMaps(),    // `openshift-console` namespace informers
 		routesInformersNamespaced.Route().V1().Routes(),
 		// events
 		recorder,
 	)
 
+	consoleHTTPRouteController := httproute.NewHTTPRouteSyncController(
+		api.OpenShiftConsoleRouteName,
+		// top level config
+		configInformers,
+		// clients
+		operatorClient,
+		dynamicClient,
+		kubeClient.CoreV1(),
+		// informers
+		operatorConfigInformers.Operator().V1().Consoles(),
+		kubeInformersNamespaced.Core().V1(), // `openshift-console` namespace informers
+		// events
+		recorder,
+	)
+
+	downloadsHTTPRouteController := httproute.NewHTTPRouteSyncController(
+		api.OpenShiftConsoleDownloadsRouteName,
+		// top level config
+		configInformers,
+		// clients
+		operatorClient,
+		dynamicClient,
+		kubeClient.CoreV1(),
+		// informers
+		operatorConfigInformers.Operator().V1().Consoles(),
+		kubeInformersNamespaced.Core().V1(), // `openshift-console` namespace informers
+		// events
+		recorder,
+	)
+
 	consoleRouteHealthCheckController := healthcheck.NewHealthCheckController(
 		// top level config
 		configClient.ConfigV1(),
 		// clients
 		operatorClient,
+		dynamicClient,
 		// route
 		operatorConfigInformers.Operator().V1().Consoles(),
 		configInformers,                     // Config
 		kubeInformersNamespaced.Core().V1(), // `openshift-console` namespace informers
 		routesInformersNamespaced.Route().V1().Routes(),
+		kubeInformersNamespaced.Networking().V1().Ingresses(),
 		// events
 		recorder,
 	)
@@ -425,6 +484,20 @@ func RunOperator(ctx context.Context, controllerContext *controllercmd.Controlle
 		recorder,
 	)
 
+	consolePluginsController := consoleplugins.NewConsolePluginsController(
+		// clients
+		operatorClient,
+		kubeClient.CoreV1(),
+		// informers
+		operatorConfigInformers.Operator().V1().Consoles(),
+		consoleInformers.Console().V1().ConsolePlugins(),
+		kubeInformersNamespaced.Core().V1(),
+		kubeInformers.Core().V1().Services(),
+		kubeInformers.Discovery().V1().EndpointSlices(),
+		// events
+		recorder,
+	)
+
 	versionRecorder := status.NewVersionGetter()
 	versionRecorder.SetVersion("operator", os.Getenv("OPERATOR_IMAGE_VERSION"))
 
@@ -527,6 +600,29 @@ func RunOperator(ctx context.Context, controllerContext *controllercmd.Controlle
 		recorder,
 	)
 
+	consoleHPAController := hpa.NewHorizontalPodAutoscalerController(
+		// clients
+		operatorClient,
+		operatorConfigInformers.Operator().V1().Consoles(),
+		kubeClient.AutoscalingV2(),
+		// informers
+		kubeInformersNamespaced.Autoscaling().V2().HorizontalPodAutoscalers(),
+		// events
+		recorder,
+	)
+
+	resourceSizingController := resourcesizing.NewResourceSizingController(
+		// clients
+		operatorClient,
+		kubeClient.CoreV1(),
+		resourcesizing.NewMetricsAPIUsageSource(dynamicClient),
+		// informers
+		operatorConfigInformers.Operator().V1().Consoles(),
+		kubeInformersNamespaced.Core().V1().ConfigMaps(), // `openshift-console` namespace informers
+		// events
+		recorder,
+	)
+
 	configUpgradeableController := unsupportedconfigoverridescontroller.NewUnsupportedConfigOverridesController(operatorClient, controllerContext.EventRecorder)
 	logLevelController := loglevel.NewClusterOperatorLoggingController(operatorClient, controllerContext.EventRecorder)
 	managementStateController := managementstatecontroller.NewOperatorManagementStateController(api.ClusterOperatorName, operatorClient, controllerContext.EventRecorder)
@@ -536,6 +632,7 @@ func RunOperator(ctx context.Context, controllerContext *controllercmd.Controlle
 	}{
 		apiextensionsInformers,
 		configInformers,
+		kubeInformers,
 		kubeInformersNamespaced,
 		kubeInformersConfigNamespaced,
 		kubeInformersManagedNamespaced,
@@ -561,19 +658,24 @@ func RunOperator(ctx context.Context, controllerContext *controllercmd.Controlle
 		configUpgradeableController,
 		consoleServiceController,
 		consoleRouteController,
+		consoleHTTPRouteController,
 		downloadsServiceController,
 		downloadsRouteController,
+		downloadsHTTPRouteController,
 		consoleOperator,
 		cliDownloadsController,
 		downloadsDeploymentController,
 		consoleRouteHealthCheckController,
 		consolePDBController,
 		downloadsPDBController,
+		consoleHPAController,
+		resourceSizingController,
 		oauthClientController,
 		oauthClientSecretController,
 		oidcSetupController,
 		cliOIDCClientStatusController,
 		upgradeNotificationController,
+		consolePluginsController,
 		staleConditionsController,
 	} {
 		go controller.Run(ctx, 1)
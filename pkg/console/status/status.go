@@ -13,6 +13,7 @@ import (
 	"github.com/openshift/console-operator/pkg/console/errors"
 	"github.com/openshift/library-go/pkg/operator/resource/resourcemerge"
 	"github.com/openshift/library-go/pkg/operator/v1helpers"
+	"golang.org/x/exp/slices"
 )
 
 // Operator status is set on the Operator Config, which is:
@@ -46,6 +47,8 @@ func HandleDegraded(typePrefix string, reason string, err error) ConditionUpdate
 	return ConditionUpdate{
 		ConditionType:  conditionType,
 		StatusUpdateFn: v1helpers.UpdateConditionFn(condition),
+		reason:         reason,
+		err:            err,
 	}
 }
 
@@ -55,6 +58,8 @@ func HandleProgressing(typePrefix string, reason string, err error) ConditionUpd
 	return ConditionUpdate{
 		ConditionType:  conditionType,
 		StatusUpdateFn: v1helpers.UpdateConditionFn(condition),
+		reason:         reason,
+		err:            err,
 	}
 }
 
@@ -64,12 +69,49 @@ func HandleAvailable(typePrefix string, reason string, err error) ConditionUpdat
 	return ConditionUpdate{
 		ConditionType:  conditionType,
 		StatusUpdateFn: v1helpers.UpdateConditionFn(condition),
+		reason:         reason,
+		err:            err,
 	}
 }
 
 func HandleUpgradable(typePrefix string, reason string, err error) ConditionUpdate {
 	conditionType := typePrefix + operatorsv1.OperatorStatusTypeUpgradeable
 	condition := handleCondition(conditionType, reason, err)
+	return ConditionUpdate{
+		ConditionType:  conditionType,
+		StatusUpdateFn: v1helpers.UpdateConditionFn(condition),
+		reason:         reason,
+		err:            err,
+	}
+}
+
+// HandleWarning reports a problem that does not break the console yet. The Warning
+// suffix is not aggregated onto the console ClusterOperator.
+func HandleWarning(typePrefix string, reason string, err error) ConditionUpdate {
+	conditionType := typePrefix + "Warning"
+	condition := handleCondition(conditionType, reason, err)
+	return ConditionUpdate{
+		ConditionType:  conditionType,
+		StatusUpdateFn: v1helpers.UpdateConditionFn(condition),
+		reason:         reason,
+		err:            err,
+	}
+}
+
+// HandleActive reports whether a feature is in use, along with a reason and a message on
+// its current state. The Active suffix is not aggregated onto the console ClusterOperator,
+// and an inactive feature clears the reason and the message.
+func HandleActive(typePrefix string, active bool, reason string, message string) ConditionUpdate {
+	conditionType := typePrefix + "Active"
+	condition := operatorsv1.OperatorCondition{
+		Type:   conditionType,
+		Status: operatorsv1.ConditionFalse,
+	}
+	if active {
+		condition.Status = operatorsv1.ConditionTrue
+		condition.Reason = reason
+		condition.Message = message
+	}
 	return ConditionUpdate{
 		ConditionType:  conditionType,
 		StatusUpdateFn: v1helpers.UpdateConditionFn(condition),
@@ -100,6 +142,16 @@ func (c *StatusHandler) ResetConditions(conditions []operatorsv1.OperatorConditi
 			updateStatusFuncs = append(updateStatusFuncs, HandleUpgradable(conditionPrefix, "", nil))
 			continue
 		}
+		if strings.HasSuffix(condition.Type, "Warning") {
+			conditionPrefix := strings.TrimSuffix(condition.Type, "Warning")
+			updateStatusFuncs = append(updateStatusFuncs, HandleWarning(conditionPrefix, "", nil))
+			continue
+		}
+		if strings.HasSuffix(condition.Type, "Active") {
+			conditionPrefix := strings.TrimSuffix(condition.Type, "Active")
+			updateStatusFuncs = append(updateStatusFuncs, HandleActive(conditionPrefix, false, "", ""))
+			continue
+		}
 		klog.V(2).Info("unable to reset condition: ", condition.Type)
 	}
 
@@ -159,6 +211,8 @@ type StatusHandler struct {
 	client v1helpers.OperatorClient
 	// conditionUpdates are keyed by condition type so that we always choose the latest as authoritative
 	conditionUpdates map[string]v1helpers.UpdateStatusFunc
+	// failures are the reasons and errors of the conditions reporting one, in the order they were added
+	failures []ConditionUpdate
 
 	statusFuncs []v1helpers.UpdateStatusFunc
 }
@@ -166,16 +220,24 @@ type StatusHandler struct {
 type ConditionUpdate struct {
 	ConditionType  string
 	StatusUpdateFn v1helpers.UpdateStatusFunc
+
+	reason string
+	err    error
 }
 
 func (c *StatusHandler) AddCondition(conditionUpdate ConditionUpdate) {
 	c.conditionUpdates[conditionUpdate.ConditionType] = conditionUpdate.StatusUpdateFn
+	c.failures = slices.DeleteFunc(c.failures, func(failure ConditionUpdate) bool {
+		return failure.ConditionType == conditionUpdate.ConditionType
+	})
+	if conditionUpdate.err != nil {
+		c.failures = append(c.failures, conditionUpdate)
+	}
 }
 
 func (c *StatusHandler) AddConditions(conditionUpdates []ConditionUpdate) {
 	for i := range conditionUpdates {
-		conditionUpdate := conditionUpdates[i]
-		c.conditionUpdates[conditionUpdate.ConditionType] = conditionUpdate.StatusUpdateFn
+		c.AddCondition(conditionUpdates[i])
 	}
 }
 
@@ -191,7 +253,7 @@ func (c *StatusHandler) FlushAndReturn(returnErr error) error {
 	if _, _, updateErr := v1helpers.UpdateStatus(context.TODO(), c.client, allStatusFns...); updateErr != nil {
 		return updateErr
 	}
-	return returnErr
+	return withSyncFailureReason(returnErr, c.failures)
 }
 
 func (c *StatusHandler) UpdateObservedGeneration(newObservedGeneration int64) {
@@ -0,0 +1,40 @@
+package status
+
+import (
+	"errors"
+)
+
+// syncFailure is the error a sync returns along with the reason of the condition that
+// reported it, for the sync metrics to tell failures apart.
+type syncFailure struct {
+	reason string
+	err    error
+}
+
+func (f *syncFailure) Error() string {
+	return f.err.Error()
+}
+
+func (f *syncFailure) Unwrap() error {
+	return f.err
+}
+
+// Reason returns the reason of the condition that reported the error.
+func (f *syncFailure) Reason() string {
+	return f.reason
+}
+
+// withSyncFailureReason attaches the reason of the failed condition the error was reported
+// with, the first one added when several did. The error is returned as is when no condition
+// reported it.
+func withSyncFailureReason(err error, failures []ConditionUpdate) error {
+	if err == nil {
+		return nil
+	}
+	for _, failure := range failures {
+		if len(failure.reason) > 0 && errors.Is(err, failure.err) {
+			return &syncFailure{reason: failure.reason, err: err}
+		}
+	}
+	return err
+}
@@ -0,0 +1,87 @@
+package status
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+	"github.com/openshift/library-go/pkg/operator/v1helpers"
+)
+
+func TestFlushAndReturnSyncFailureReason(t *testing.T) {
+	getErr := errors.New("failed to get")
+	applyErr := errors.New("failed to apply")
+
+	tests := []struct {
+		name       string
+		conditions []ConditionUpdate
+		returnErr  error
+		wantReason string
+	}{
+		{
+			name:       "No error",
+			conditions: []ConditionUpdate{HandleDegraded("Config", "FailedGet", getErr)},
+		},
+		{
+			name:      "Error no condition reported",
+			returnErr: getErr,
+		},
+		{
+			name:       "Error reported by a condition",
+			conditions: []ConditionUpdate{HandleDegraded("Config", "FailedGet", getErr), HandleDegraded("Deployment", "FailedApply", applyErr)},
+			returnErr:  fmt.Errorf("sync failed: %w", applyErr),
+			wantReason: "FailedApply",
+		},
+		{
+			name: "Error reported by several conditions",
+			conditions: []ConditionUpdate{
+				HandleDegraded("Service", "FailedGetService", getErr),
+				HandleDegraded("Route", "FailedGetRoute", getErr),
+				HandleDegraded("Config", "FailedGetConfig", getErr),
+			},
+			returnErr:  getErr,
+			wantReason: "FailedGetService",
+		},
+		{
+			name: "Error reported again after a reset",
+			conditions: []ConditionUpdate{
+				HandleDegraded("Service", "FailedGetService", getErr),
+				HandleDegraded("Route", "FailedGetRoute", getErr),
+				HandleDegraded("Service", "", nil),
+				HandleDegraded("Service", "FailedGetService", getErr),
+			},
+			returnErr:  getErr,
+			wantReason: "FailedGetRoute",
+		},
+		{
+			name: "Error reported by a condition that was reset",
+			conditions: []ConditionUpdate{
+				HandleDegraded("Config", "FailedGet", getErr),
+				HandleDegraded("Config", "", nil),
+			},
+			returnErr: getErr,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			statusHandler := NewStatusHandler(v1helpers.NewFakeOperatorClient(&operatorv1.OperatorSpec{}, &operatorv1.OperatorStatus{}, nil))
+			statusHandler.AddConditions(tt.conditions)
+
+			err := statusHandler.FlushAndReturn(tt.returnErr)
+			if !errors.Is(err, tt.returnErr) {
+				t.Fatalf("expected %v to be returned, got %v", tt.returnErr, err)
+			}
+			var failure *syncFailure
+			if !errors.As(err, &failure) {
+				if len(tt.wantReason) > 0 {
+					t.Errorf("expected the reason %q, got none", tt.wantReason)
+				}
+				return
+			}
+			if failure.Reason() != tt.wantReason {
+				t.Errorf("reason = %q, want %q", failure.Reason(), tt.wantReason)
+			}
+		})
+	}
+}
@@ -23,6 +23,9 @@ func GetOIDCOCLoginCommand(authConfig *configv1.Authentication, apiServerURL str
 	return fmt.Sprintf("oc login %s --issuer-url %s --exec-plugin oc-oidc --client-id %s%s", apiServerURL, provider.Issuer.URL, clientConfig.ClientID, extraScopes)
 }
 
+// GetOIDCClientConfig returns the component's client and the OIDC provider it is on. The
+// Authentication API allows a single OIDC provider (spec.oidcProviders has MaxItems=1), so
+// the console and its status handle that one provider only.
 func GetOIDCClientConfig(authnConfig *configv1.Authentication, componentNamespace, componentName string) (*configv1.OIDCProvider, *configv1.OIDCClientConfig) {
 	if len(authnConfig.Spec.OIDCProviders) == 0 {
 		return nil, nil
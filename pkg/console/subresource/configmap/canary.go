@@ -0,0 +1,183 @@
+package configmap
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+
+	"github.com/openshift/console-operator/pkg/api"
+	customerrors "github.com/openshift/console-operator/pkg/console/errors"
+	"github.com/openshift/console-operator/pkg/console/subresource/consoleserver"
+	"github.com/openshift/console-operator/pkg/console/subresource/util"
+	"golang.org/x/exp/slices"
+)
+
+const (
+	// CanaryRolloutAnnotation is set to "true" on the operator config to roll risky
+	// console-config changes out to a single canary pod before the console deployment.
+	CanaryRolloutAnnotation = "console.openshift.io/canary-rollout"
+	// CanaryTimeout is how long the canary has to answer its health check before the
+	// change is aborted.
+	CanaryTimeout = 10 * time.Minute
+
+	CanaryOutcomePromoted = "Promoted"
+	CanaryOutcomeAborted  = "Aborted"
+
+	// annotations on the canary config map, tracking the canary and its outcome.
+	canarySinceAnnotation        = "console.openshift.io/canary-since"
+	canaryChangedPathsAnnotation = "console.openshift.io/canary-changed-paths"
+	canaryOutcomeAnnotation      = "console.openshift.io/canary-outcome"
+	canaryAbortReasonAnnotation  = "console.openshift.io/canary-abort-reason"
+)
+
+// canaryConfigPaths are the console-config paths whose changes go through a canary.
+var canaryConfigPaths = []string{"auth", "contentSecurityPolicy", "plugins", "proxy", "session"}
+
+// IsCanaryRolloutEnabled returns true when risky console-config changes are rolled out
+// through a canary.
+func IsCanaryRolloutEnabled(operatorConfig *operatorv1.Console) bool {
+	return operatorConfig.Annotations[CanaryRolloutAnnotation] == "true"
+}
+
+// acceptedSessionKeysPath lists the previous session keys during a rotation. It has to
+// reach the console pods along with the rotated session secret, and does not go through a
+// canary.
+const acceptedSessionKeysPath = "session.acceptedCookieKeys"
+
+// CanaryChangedPaths returns the risky console-config paths changed between the live and
+// the generated config.
+func CanaryChangedPaths(consoleConfigMap *corev1.ConfigMap, generatedConfigMap *corev1.ConfigMap) ([]string, error) {
+	changedPaths, err := changedPathsUnder(consoleConfigMap, generatedConfigMap, canaryConfigPaths)
+	if err != nil {
+		return nil, err
+	}
+	return slices.DeleteFunc(changedPaths, func(path string) bool {
+		return path == acceptedSessionKeysPath
+	}), nil
+}
+
+// AuthChangedPaths returns the auth paths changed between the live and the generated
+// console-config.
+func AuthChangedPaths(consoleConfigMap *corev1.ConfigMap, generatedConfigMap *corev1.ConfigMap) ([]string, error) {
+	return changedPathsUnder(consoleConfigMap, generatedConfigMap, []string{"auth"})
+}
+
+func changedPathsUnder(consoleConfigMap *corev1.ConfigMap, generatedConfigMap *corev1.ConfigMap, underPaths []string) ([]string, error) {
+	changedPaths, err := consoleserver.ChangedPaths(
+		[]byte(consoleConfigMap.Data[consoleConfigYamlFile]),
+		[]byte(generatedConfigMap.Data[consoleConfigYamlFile]),
+	)
+	if err != nil {
+		return nil, err
+	}
+	paths := []string{}
+	for _, path := range changedPaths {
+		for _, underPath := range underPaths {
+			if path == underPath || strings.HasPrefix(path, underPath+".") || strings.HasPrefix(path, underPath+"[") {
+				paths = append(paths, path)
+				break
+			}
+		}
+	}
+	return paths, nil
+}
+
+// CanaryCandidate works out whether the generated console-config has to go through a
+// canary before replacing the live one. It returns nil when the generated config can be
+// applied, either because it carries no risky change or because its canary was promoted.
+// Otherwise it returns the canary config map to run the generated config with, and the
+// live console-config is left as is, aborted canaries included.
+func CanaryCandidate(
+	operatorConfig *operatorv1.Console,
+	generatedConfigMap *corev1.ConfigMap,
+	consoleConfigMap *corev1.ConfigMap,
+	canaryConfigMap *corev1.ConfigMap,
+	now time.Time,
+) (*corev1.ConfigMap, error) {
+	generatedHash := ConfigHash(generatedConfigMap)
+	// nothing to compare with on a fresh install
+	if consoleConfigMap == nil || ConfigHash(consoleConfigMap) == generatedHash {
+		return nil, nil
+	}
+	if canaryConfigMap != nil && ConfigHash(canaryConfigMap) == generatedHash {
+		if CanaryOutcome(canaryConfigMap) == CanaryOutcomePromoted {
+			return nil, nil
+		}
+		return canaryConfigMap, nil
+	}
+
+	changedPaths, err := CanaryChangedPaths(consoleConfigMap, generatedConfigMap)
+	if err != nil {
+		return nil, err
+	}
+	if len(changedPaths) == 0 {
+		return nil, nil
+	}
+	candidate := CanaryStub()
+	candidate.Annotations = map[string]string{
+		canarySinceAnnotation:        now.UTC().Format(time.RFC3339),
+		canaryChangedPathsAnnotation: strings.Join(changedPaths, ", "),
+		// drop the outcome of the canary this one replaces on apply
+		canaryOutcomeAnnotation + "-":     "",
+		canaryAbortReasonAnnotation + "-": "",
+	}
+	candidate.Data = map[string]string{}
+	for key, value := range generatedConfigMap.Data {
+		candidate.Data[key] = value
+	}
+	util.AddOwnerRef(candidate, util.OwnerRefFrom(operatorConfig))
+	return candidate, nil
+}
+
+// CanaryOutcome returns Promoted or Aborted once the canary is over, empty before.
+func CanaryOutcome(canaryConfigMap *corev1.ConfigMap) string {
+	return canaryConfigMap.Annotations[canaryOutcomeAnnotation]
+}
+
+// NextCanary records the outcome of a canary in flight from its last health check: the
+// change is promoted once the canary is healthy, and aborted when it is still unhealthy
+// after the timeout. It returns nil while the canary is in flight or already over.
+// Annotations are merged on apply, the returned config map only carries the ones to set.
+func NextCanary(canaryConfigMap *corev1.ConfigMap, healthErr error, timeout time.Duration, now time.Time) *corev1.ConfigMap {
+	if len(CanaryOutcome(canaryConfigMap)) != 0 {
+		return nil
+	}
+	next := canaryConfigMap.DeepCopy()
+	if healthErr == nil {
+		next.Annotations[canaryOutcomeAnnotation] = CanaryOutcomePromoted
+		return next
+	}
+	since, err := time.Parse(time.RFC3339, canaryConfigMap.Annotations[canarySinceAnnotation])
+	if err == nil && now.Sub(since) < timeout {
+		return nil
+	}
+	next.Annotations[canaryOutcomeAnnotation] = CanaryOutcomeAborted
+	next.Annotations[canaryAbortReasonAnnotation] = healthErr.Error()
+	return next
+}
+
+// CanaryError describes an aborted canary, or one in flight as a sync error, nil once it
+// is promoted.
+func CanaryError(canaryConfigMap *corev1.ConfigMap) error {
+	changedPaths := canaryConfigMap.Annotations[canaryChangedPathsAnnotation]
+	switch CanaryOutcome(canaryConfigMap) {
+	case CanaryOutcomePromoted:
+		return nil
+	case CanaryOutcomeAborted:
+		return fmt.Errorf("console-config change (%s) aborted, canary failed its health check: %s", changedPaths, canaryConfigMap.Annotations[canaryAbortReasonAnnotation])
+	default:
+		return customerrors.NewSyncError(fmt.Sprintf("console-config change (%s) is rolling out to a canary since %s", changedPaths, canaryConfigMap.Annotations[canarySinceAnnotation]))
+	}
+}
+
+func CanaryStub() *corev1.ConfigMap {
+	meta := util.SharedMeta()
+	meta.Name = api.ConsoleCanaryConfigMapName
+	return &corev1.ConfigMap{
+		ObjectMeta: meta,
+	}
+}
@@ -0,0 +1,173 @@
+package configmap
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+
+	customerrors "github.com/openshift/console-operator/pkg/console/errors"
+)
+
+const brandingConfig = `kind: ConsoleConfig
+customization:
+  branding: ocp
+`
+
+func canaryWith(config string, annotations map[string]string) *corev1.ConfigMap {
+	canary := CanaryStub()
+	canary.Data = map[string]string{consoleConfigYamlFile: config}
+	for key, value := range annotations {
+		canary.Annotations[key] = value
+	}
+	return canary
+}
+
+func TestCanaryCandidate(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	operatorConfig := &operatorv1.Console{}
+	liveConfig := consoleConfigMapWith(goodConfig, goodConfig)
+
+	tests := []struct {
+		name            string
+		generated       string
+		consoleConfig   *corev1.ConfigMap
+		canary          *corev1.ConfigMap
+		wantCandidate   bool
+		wantSameCanary  bool
+		wantChangedPath string
+	}{
+		{
+			name:      "Fresh install",
+			generated: badConfig,
+		},
+		{
+			name:          "Unchanged config",
+			generated:     goodConfig,
+			consoleConfig: liveConfig,
+		},
+		{
+			name:          "Change without risk",
+			generated:     brandingConfig,
+			consoleConfig: liveConfig,
+		},
+		{
+			name:            "Risky change starts a canary",
+			generated:       badConfig,
+			consoleConfig:   liveConfig,
+			wantCandidate:   true,
+			wantChangedPath: "auth.oidcIssuer",
+		},
+		{
+			name:            "Risky change replaces the canary of an older change",
+			generated:       badConfig,
+			consoleConfig:   liveConfig,
+			canary:          canaryWith(brandingConfig, map[string]string{canaryOutcomeAnnotation: CanaryOutcomeAborted}),
+			wantCandidate:   true,
+			wantChangedPath: "auth.oidcIssuer",
+		},
+		{
+			name:           "Canary in flight is kept",
+			generated:      badConfig,
+			consoleConfig:  liveConfig,
+			canary:         canaryWith(badConfig, nil),
+			wantCandidate:  true,
+			wantSameCanary: true,
+		},
+		{
+			name:           "Aborted canary holds the change back",
+			generated:      badConfig,
+			consoleConfig:  liveConfig,
+			canary:         canaryWith(badConfig, map[string]string{canaryOutcomeAnnotation: CanaryOutcomeAborted}),
+			wantCandidate:  true,
+			wantSameCanary: true,
+		},
+		{
+			name:          "Promoted canary lets the change through",
+			generated:     badConfig,
+			consoleConfig: liveConfig,
+			canary:        canaryWith(badConfig, map[string]string{canaryOutcomeAnnotation: CanaryOutcomePromoted}),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			generated := Stub()
+			generated.Data = map[string]string{consoleConfigYamlFile: tt.generated}
+			candidate, err := CanaryCandidate(operatorConfig, generated, tt.consoleConfig, tt.canary, now)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if (candidate != nil) != tt.wantCandidate {
+				t.Fatalf("candidate = %v, want a candidate %v", candidate, tt.wantCandidate)
+			}
+			if candidate == nil {
+				return
+			}
+			if (candidate == tt.canary) != tt.wantSameCanary {
+				t.Errorf("candidate is the existing canary = %v, want %v", candidate == tt.canary, tt.wantSameCanary)
+			}
+			if ConfigHash(candidate) != ConfigHash(generated) {
+				t.Errorf("candidate does not run the generated config")
+			}
+			if len(tt.wantChangedPath) != 0 && candidate.Annotations[canaryChangedPathsAnnotation] != tt.wantChangedPath {
+				t.Errorf("changed paths = %q, want %q", candidate.Annotations[canaryChangedPathsAnnotation], tt.wantChangedPath)
+			}
+		})
+	}
+}
+
+func TestNextCanary(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	inFlight := canaryWith(badConfig, map[string]string{canarySinceAnnotation: now.Add(-time.Minute).Format(time.RFC3339)})
+	timedOut := canaryWith(badConfig, map[string]string{canarySinceAnnotation: now.Add(-time.Hour).Format(time.RFC3339)})
+	unhealthy := errors.New("route not yet available")
+
+	tests := []struct {
+		name        string
+		canary      *corev1.ConfigMap
+		healthErr   error
+		wantOutcome string
+		wantNil     bool
+	}{
+		{
+			name:        "Healthy canary is promoted",
+			canary:      inFlight,
+			wantOutcome: CanaryOutcomePromoted,
+		},
+		{
+			name:      "Unhealthy canary within the timeout",
+			canary:    inFlight,
+			healthErr: unhealthy,
+			wantNil:   true,
+		},
+		{
+			name:        "Unhealthy canary past the timeout is aborted",
+			canary:      timedOut,
+			healthErr:   unhealthy,
+			wantOutcome: CanaryOutcomeAborted,
+		},
+		{
+			name:    "Canary already over",
+			canary:  canaryWith(badConfig, map[string]string{canaryOutcomeAnnotation: CanaryOutcomeAborted}),
+			wantNil: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			next := NextCanary(tt.canary, tt.healthErr, CanaryTimeout, now)
+			if (next == nil) != tt.wantNil {
+				t.Fatalf("next = %v, wantNil %v", next, tt.wantNil)
+			}
+			if next != nil && CanaryOutcome(next) != tt.wantOutcome {
+				t.Errorf("outcome = %q, want %q", CanaryOutcome(next), tt.wantOutcome)
+			}
+		})
+	}
+
+	if err := CanaryError(inFlight); !customerrors.IsSyncError(err) {
+		t.Errorf("expected a canary in flight to be reported as progressing, got %v", err)
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"net/url"
 	"sort"
+	"strings"
 
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/klog/v2"
@@ -14,6 +15,7 @@ import (
 	routev1 "github.com/openshift/api/route/v1"
 	"github.com/openshift/console-operator/bindata"
 	"github.com/openshift/console-operator/pkg/api"
+	customerrors "github.com/openshift/console-operator/pkg/console/errors"
 	"github.com/openshift/console-operator/pkg/console/subresource/consoleserver"
 	infrastructuresub "github.com/openshift/console-operator/pkg/console/subresource/infrastructure"
 	"github.com/openshift/console-operator/pkg/console/subresource/util"
@@ -33,60 +35,86 @@ func statusPageId(operatorConfig *operatorv1.Console) string {
 	return ""
 }
 
-func DefaultConfigMap(
-	operatorConfig *operatorv1.Console,
-	consoleConfig *configv1.Console,
-	authConfig *configv1.Authentication,
-	authServerCAConfig *corev1.ConfigMap,
-	managedConfig *corev1.ConfigMap,
-	monitoringSharedConfig *corev1.ConfigMap,
-	infrastructureConfig *configv1.Infrastructure,
-	activeConsoleRoute *routev1.Route,
-	inactivityTimeoutSeconds int,
-	availablePlugins []*v1.ConsolePlugin,
-	nodeArchitectures []string,
-	nodeOperatingSystems []string,
-	copiedCSVsDisabled bool,
-	telemeterConfig map[string]string,
-	consoleHost string,
-) (consoleConfigMap *corev1.ConfigMap, unsupportedOverridesHaveMerged bool, err error) {
+// DefaultConfigMapOptions are the operator and cluster resources console-config is
+// generated from. Resources left nil are treated as empty.
+type DefaultConfigMapOptions struct {
+	OperatorConfig           *operatorv1.Console
+	ConsoleConfig            *configv1.Console
+	AuthConfig               *configv1.Authentication
+	SessionSecret            *corev1.Secret
+	ManagedConfig            *corev1.ConfigMap
+	MonitoringSharedConfig   *corev1.ConfigMap
+	InfrastructureConfig     *configv1.Infrastructure
+	TLSProfile               *configv1.TLSProfileSpec
+	ActiveConsoleRoute       *routev1.Route
+	InactivityTimeoutSeconds int
+	AvailablePlugins         []*v1.ConsolePlugin
+	PluginProxyCABundles     map[string]string
+	NodeArchitectures        []string
+	NodeOperatingSystems     []string
+	CopiedCSVsDisabled       bool
+	TelemeterConfig          map[string]string
+	ConsoleHost              string
+}
+
+func DefaultConfigMap(opts DefaultConfigMapOptions) (consoleConfigMap *corev1.ConfigMap, provenanceConfigMap *corev1.ConfigMap, unsupportedOverridesHaveMerged bool, err error) {
+	operatorConfig := opts.OperatorConfig
+	consoleConfig := opts.ConsoleConfig
+	if consoleConfig == nil {
+		consoleConfig = &configv1.Console{}
+	}
+	infrastructureConfig := opts.InfrastructureConfig
+	if infrastructureConfig == nil {
+		infrastructureConfig = &configv1.Infrastructure{}
+	}
+	authConfig := opts.AuthConfig
+	if authConfig == nil {
+		authConfig = &configv1.Authentication{}
+	}
+	availablePlugins := opts.AvailablePlugins
 
 	apiServerURL := infrastructuresub.GetAPIServerURL(infrastructureConfig)
 
 	defaultBuilder := &consoleserver.ConsoleServerCLIConfigBuilder{}
-	defaultConfig, err := defaultBuilder.Host(consoleHost).
+	defaultConfig, err := defaultBuilder.Host(opts.ConsoleHost).
 		LogoutURL(defaultLogoutURL).
 		Brand(DEFAULT_BRAND).
 		DocURL(DEFAULT_DOC_URL).
 		APIServerURL(apiServerURL).
-		Monitoring(monitoringSharedConfig).
-		InactivityTimeout(inactivityTimeoutSeconds).
+		Monitoring(opts.MonitoringSharedConfig).
+		InactivityTimeout(opts.InactivityTimeoutSeconds).
 		ReleaseVersion().
-		NodeArchitectures(nodeArchitectures).
-		NodeOperatingSystems(nodeOperatingSystems).
-		CopiedCSVsDisabled(copiedCSVsDisabled).
+		NodeArchitectures(opts.NodeArchitectures).
+		NodeOperatingSystems(opts.NodeOperatingSystems).
+		CopiedCSVsDisabled(opts.CopiedCSVsDisabled).
+		AuthConfig(authConfig, apiServerURL).
+		AcceptedSessionKeys(opts.SessionSecret).
 		ConfigYAML()
 	if err != nil {
 		klog.Errorf("failed to generate default console-config config: %v", err)
-		return nil, false, err
+		return nil, nil, false, err
 	}
 
-	extractedManagedConfig := extractYAML(managedConfig)
+	pluginsProxyServices, _ := getPluginsProxyServices(operatorConfig, availablePlugins, opts.PluginProxyCABundles)
+
+	extractedManagedConfig := extractYAML(opts.ManagedConfig)
 	userDefinedBuilder := &consoleserver.ConsoleServerCLIConfigBuilder{}
-	if activeConsoleRoute != nil {
-		userDefinedBuilder = userDefinedBuilder.CustomHostnameRedirectPort(isCustomRoute(activeConsoleRoute))
+	if opts.ActiveConsoleRoute != nil {
+		userDefinedBuilder = userDefinedBuilder.CustomHostnameRedirectPort(isCustomRoute(opts.ActiveConsoleRoute))
 	}
-	userDefinedConfig, err := userDefinedBuilder.Host(consoleHost).
+	minTLSVersion, cipherSuites := servingTLSSettings(opts.TLSProfile)
+	userDefinedConfig, err := userDefinedBuilder.Host(opts.ConsoleHost).
+		TLSSecurityProfile(minTLSVersion, cipherSuites).
 		LogoutURL(consoleConfig.Spec.Authentication.LogoutRedirect).
 		Brand(operatorConfig.Spec.Customization.Brand).
 		DocURL(operatorConfig.Spec.Customization.DocumentationBaseURL).
 		APIServerURL(apiServerURL).
 		TopologyMode(infrastructureConfig.Status.ControlPlaneTopology).
-		Monitoring(monitoringSharedConfig).
+		Monitoring(opts.MonitoringSharedConfig).
 		Plugins(getPluginsEndpointMap(availablePlugins)).
 		I18nNamespaces(pluginsWithI18nNamespace(availablePlugins)).
-		ContentSecurityPolicies(aggregateCSPDirectives(availablePlugins)).
-		Proxy(getPluginsProxyServices(availablePlugins)).
+		ContentSecurityPolicies(aggregateCSPDirectives(availablePlugins, pluginCSPPolicyOrNil(operatorConfig))).
+		Proxy(pluginsProxyServices).
 		CustomLogoFile(operatorConfig.Spec.Customization.CustomLogoFile.Key).
 		CustomProductName(operatorConfig.Spec.Customization.CustomProductName).
 		CustomDeveloperCatalog(operatorConfig.Spec.Customization.DeveloperCatalog).
@@ -95,17 +123,18 @@ func DefaultConfigMap(
 		AddPage(operatorConfig.Spec.Customization.AddPage).
 		Perspectives(operatorConfig.Spec.Customization.Perspectives).
 		StatusPageID(statusPageId(operatorConfig)).
-		InactivityTimeout(inactivityTimeoutSeconds).
-		TelemetryConfiguration(telemeterConfig).
+		InactivityTimeout(opts.InactivityTimeoutSeconds).
+		TelemetryConfiguration(opts.TelemeterConfig).
 		ReleaseVersion().
-		NodeArchitectures(nodeArchitectures).
-		NodeOperatingSystems(nodeOperatingSystems).
+		NodeArchitectures(opts.NodeArchitectures).
+		NodeOperatingSystems(opts.NodeOperatingSystems).
 		AuthConfig(authConfig, apiServerURL).
+		AcceptedSessionKeys(opts.SessionSecret).
 		Capabilities(operatorConfig.Spec.Customization.Capabilities).
 		ConfigYAML()
 	if err != nil {
 		klog.Errorf("failed to generate user defined console-config config: %v", err)
-		return nil, false, err
+		return nil, nil, false, err
 	}
 
 	unsupportedConfigOverride := operatorConfig.Spec.UnsupportedConfigOverrides.Raw
@@ -115,14 +144,23 @@ func DefaultConfigMap(
 	}
 
 	merger := &consoleserver.ConsoleYAMLMerger{}
-	mergedConfig, err := merger.Merge(
-		defaultConfig,
-		extractedManagedConfig,
-		userDefinedConfig,
-		unsupportedConfigOverride)
+	mergedConfig, provenance, err := merger.MergeWithProvenance(
+		consoleserver.ConfigLayer{Name: consoleserver.ConfigLayerDefault, Config: defaultConfig},
+		consoleserver.ConfigLayer{Name: consoleserver.ConfigLayerManaged, Config: extractedManagedConfig},
+		consoleserver.ConfigLayer{Name: consoleserver.ConfigLayerUserDefined, Config: userDefinedConfig},
+		consoleserver.ConfigLayer{Name: consoleserver.ConfigLayerUnsupportedConfigOverrides, Config: unsupportedConfigOverride},
+	)
 	if err != nil {
 		klog.Errorf("failed to generate configmap: %v", err)
-		return nil, false, err
+		return nil, nil, false, err
+	}
+
+	// overrides are not validated by the API, make sure they still produce a config the console can load.
+	// Only the values the overrides set are checked, the other layers are not the admin's doing.
+	if willMergeConfigOverrides {
+		if validationErrs := consoleserver.ValidateConfigLayer(mergedConfig, provenance, consoleserver.ConfigLayerUnsupportedConfigOverrides); len(validationErrs) != 0 {
+			return nil, nil, false, customerrors.NewUnsupportedConfigOverridesError(fmt.Sprintf("invalid unsupportedConfigOverrides: %v", validationErrs.ToAggregate()))
+		}
 	}
 
 	configMap := Stub()
@@ -130,14 +168,23 @@ func DefaultConfigMap(
 	configMap.Data[consoleConfigYamlFile] = string(mergedConfig)
 	util.AddOwnerRef(configMap, util.OwnerRefFrom(operatorConfig))
 
-	return configMap, willMergeConfigOverrides, nil
+	provenanceConfigMap, err = DefaultConfigProvenanceConfigMap(operatorConfig, provenance)
+	if err != nil {
+		klog.Errorf("failed to generate console-config provenance: %v", err)
+		return nil, nil, false, err
+	}
+
+	return configMap, provenanceConfigMap, willMergeConfigOverrides, nil
 }
 
-func aggregateCSPDirectives(plugins []*v1.ConsolePlugin) map[v1.DirectiveType][]string {
+// aggregateCSPDirectives unions the CSP directives of the plugins, leaving out the
+// values that do not comply with the policy.
+func aggregateCSPDirectives(plugins []*v1.ConsolePlugin, policy *PluginCSPPolicy) map[v1.DirectiveType][]string {
 	aggregated := make(map[v1.DirectiveType]map[string]struct{}) // Use a map to ensure uniqueness
 
-	for _, plugin := range plugins {
-		for _, csp := range plugin.Spec.ContentSecurityPolicy {
+	allowed, _ := filterPluginCSP(policy, plugins)
+	for _, pluginCSPs := range allowed {
+		for _, csp := range pluginCSPs {
 			if aggregated[csp.Directive] == nil {
 				aggregated[csp.Directive] = make(map[string]struct{}) // Initialize if not already done
 			}
@@ -181,7 +228,7 @@ func getPluginsEndpointMap(availablePlugins []*v1.ConsolePlugin) map[string]stri
 	for _, plugin := range availablePlugins {
 		switch plugin.Spec.Backend.Type {
 		case v1.Service:
-			pluginsEndpointMap[plugin.Name] = getServiceURL(&plugin.Spec.Backend)
+			pluginsEndpointMap[plugin.Name] = PluginServiceURL(&plugin.Spec.Backend)
 		default:
 			klog.Errorf("unknown backend type for %q plugin: %q. Currently only %q backend type is supported.", plugin.Name, plugin.Spec.Backend.Type, v1.Service)
 		}
@@ -189,11 +236,19 @@ func getPluginsEndpointMap(availablePlugins []*v1.ConsolePlugin) map[string]stri
 	return pluginsEndpointMap
 }
 
-func getPluginsProxyServices(availablePlugins []*v1.ConsolePlugin) []consoleserver.ProxyService {
+// getPluginsProxyServices returns the proxies of the plugins that pass validation, along
+// with the reasons the other ones were dropped, keyed by plugin name. External proxies
+// are only accepted for the hosts allowed on the operator config, Service proxies are
+// validated by the API and rendered as they are.
+func getPluginsProxyServices(operatorConfig *operatorv1.Console, availablePlugins []*v1.ConsolePlugin, caBundles map[string]string) ([]consoleserver.ProxyService, map[string][]string) {
 	proxyServices := []consoleserver.ProxyService{}
+	violations := map[string][]string{}
+	consoleAPIPaths := map[string]bool{}
+
+	allowedHosts := pluginProxyAllowedHosts(operatorConfig)
 	for _, plugin := range availablePlugins {
 		for _, proxy := range plugin.Spec.Proxy {
-			// currently we only supprot 'Service' backend type for proxy
+			// external endpoints are declared with an annotation, the API only has 'Service'
 			switch proxy.Endpoint.Type {
 			case v1.ProxyTypeService:
 				proxyService := consoleserver.ProxyService{
@@ -202,13 +257,38 @@ func getPluginsProxyServices(availablePlugins []*v1.ConsolePlugin) []consoleserv
 					CACertificate:  proxy.CACertificate,
 					Authorize:      getProxyAuthorization(proxy.Authorization),
 				}
+				consoleAPIPaths[proxyService.ConsoleAPIPath] = true
 				proxyServices = append(proxyServices, proxyService)
 			default:
 				klog.Errorf("unknown proxy service type for %q plugin: %q. Currently only %q proxy endpoint type is supported.", plugin.Name, proxy.Endpoint.Type, v1.ProxyTypeService)
 			}
 		}
+
+		externalEndpoints, err := ExternalProxyEndpoints(plugin)
+		if err != nil {
+			violations[plugin.Name] = append(violations[plugin.Name], err.Error())
+			continue
+		}
+		for _, endpoint := range externalEndpoints {
+			proxyService, err := getExternalProxyService(plugin.Name, endpoint, allowedHosts, caBundles)
+			if err == nil {
+				err = validateProxyService(proxyService)
+			}
+			if err == nil && consoleAPIPaths[proxyService.ConsoleAPIPath] {
+				err = fmt.Errorf("duplicate console API path %q", proxyService.ConsoleAPIPath)
+			}
+			if err != nil {
+				violations[plugin.Name] = append(violations[plugin.Name], err.Error())
+				continue
+			}
+			consoleAPIPaths[proxyService.ConsoleAPIPath] = true
+			proxyServices = append(proxyServices, proxyService)
+		}
 	}
-	return proxyServices
+	for pluginName, pluginViolations := range violations {
+		klog.Errorf("dropping proxies of %q plugin: %s", pluginName, strings.Join(pluginViolations, ", "))
+	}
+	return proxyServices, violations
 }
 
 func getConsoleAPIPath(pluginName string, service *v1.ConsolePluginProxy) string {
@@ -230,7 +310,8 @@ func getProxyServiceURL(service *v1.ConsolePluginProxyServiceConfig) string {
 	return pluginURL.String()
 }
 
-func getServiceURL(pluginBackend *v1.ConsolePluginBackend) string {
+// PluginServiceURL returns the in-cluster URL the console loads the plugin assets from.
+func PluginServiceURL(pluginBackend *v1.ConsolePluginBackend) string {
 	pluginURL := &url.URL{
 		Scheme: "https",
 		Host:   fmt.Sprintf("%s.%s.svc.cluster.local:%d", pluginBackend.Service.Name, pluginBackend.Service.Namespace, pluginBackend.Service.Port),
@@ -273,6 +354,9 @@ func consoleBaseAddr(host string) string {
 
 // Helper function that pulls the yaml struct out of the data section of a configmap yaml
 func extractYAML(managedConfig *corev1.ConfigMap) []byte {
+	if managedConfig == nil {
+		return []byte{}
+	}
 	data := managedConfig.Data
 	for _, v := range data {
 		return []byte(v)
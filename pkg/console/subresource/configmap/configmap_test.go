@@ -56,8 +56,8 @@ func TestDefaultConfigMap(t *testing.T) {
 		consoleConfig            *configv1.Console
 		managedConfig            *corev1.ConfigMap
 		monitoringSharedConfig   *corev1.ConfigMap
-		authServerCAConfig       *corev1.ConfigMap
 		infrastructureConfig     *configv1.Infrastructure
+		tlsProfile               *configv1.TLSProfileSpec
 		rt                       *routev1.Route
 		inactivityTimeoutSeconds int
 		availablePlugins         []*consolev1.ConsolePlugin
@@ -132,6 +132,77 @@ servingInfo:
   certFile: /var/serving-cert/tls.crt
   keyFile: /var/serving-cert/tls.key
 providers: {}
+`,
+				},
+			},
+		},
+		{
+			name: "Test configmap with the cluster TLS security profile",
+			args: args{
+				authConfig:     &configv1.Authentication{},
+				operatorConfig: &operatorv1.Console{},
+				consoleConfig:  &configv1.Console{},
+				managedConfig:  &corev1.ConfigMap{},
+				infrastructureConfig: &configv1.Infrastructure{
+					Status: configv1.InfrastructureStatus{
+						APIServerURL:         mockAPIServer,
+						ControlPlaneTopology: configv1.HighlyAvailableTopologyMode,
+					},
+				},
+				tlsProfile: &configv1.TLSProfileSpec{
+					Ciphers:       []string{"ECDHE-RSA-AES128-GCM-SHA256", "ECDHE-ECDSA-AES256-GCM-SHA384"},
+					MinTLSVersion: configv1.VersionTLS12,
+				},
+				rt: &routev1.Route{
+					ObjectMeta: metav1.ObjectMeta{
+						Name: api.OpenShiftConsoleName,
+					},
+					Spec: routev1.RouteSpec{
+						Host: host,
+					},
+				},
+			},
+			want: &corev1.ConfigMap{
+				TypeMeta: metav1.TypeMeta{
+					Kind:       "ConfigMap",
+					APIVersion: "v1",
+				},
+				ObjectMeta: metav1.ObjectMeta{
+					Name:        api.OpenShiftConsoleConfigMapName,
+					Namespace:   api.OpenShiftConsoleNamespace,
+					Labels:      map[string]string{"app": api.OpenShiftConsoleName},
+					Annotations: map[string]string{},
+					OwnerReferences: []metav1.OwnerReference{{
+						APIVersion: "operator.openshift.io/v1",
+						Kind:       "Console",
+						Controller: ptr.To(true),
+					}},
+				},
+				Data: map[string]string{configKey: `kind: ConsoleConfig
+apiVersion: console.openshift.io/v1
+auth:
+  authType: openshift
+  clientID: console
+  clientSecretFile: /var/oauth-config/clientSecret
+  oauthEndpointCAFile: /var/oauth-serving-cert/ca-bundle.crt
+clusterInfo:
+  consoleBaseAddress: https://` + host + `
+  masterPublicURL: ` + mockAPIServer + `
+  controlPlaneTopology: HighlyAvailable
+  releaseVersion: ` + testReleaseVersion + `
+session: {}
+customization:
+  branding: ` + DEFAULT_BRAND + `
+  documentationBaseURL: ` + DEFAULT_DOC_URL + `
+servingInfo:
+  bindAddress: https://[::]:8443
+  certFile: /var/serving-cert/tls.crt
+  keyFile: /var/serving-cert/tls.key
+  minTLSVersion: VersionTLS12
+  cipherSuites:
+  - TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256
+  - TLS_ECDHE_ECDSA_WITH_AES_256_GCM_SHA384
+providers: {}
 `,
 				},
 			},
@@ -1055,23 +1126,23 @@ providers: {}
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			cm, _, _ := DefaultConfigMap(
-				tt.args.operatorConfig,
-				tt.args.consoleConfig,
-				tt.args.authConfig,
-				tt.args.authServerCAConfig,
-				tt.args.managedConfig,
-				tt.args.monitoringSharedConfig,
-				tt.args.infrastructureConfig,
-				tt.args.rt,
-				tt.args.inactivityTimeoutSeconds,
-				tt.args.availablePlugins,
-				tt.args.nodeArchitectures,
-				tt.args.nodeOperatingSystems,
-				tt.args.copiedCSVsDisabled,
-				tt.args.telemetryConfig,
-				tt.args.rt.Spec.Host,
-			)
+			cm, _, _, _ := DefaultConfigMap(DefaultConfigMapOptions{
+				OperatorConfig:           tt.args.operatorConfig,
+				ConsoleConfig:            tt.args.consoleConfig,
+				AuthConfig:               tt.args.authConfig,
+				ManagedConfig:            tt.args.managedConfig,
+				MonitoringSharedConfig:   tt.args.monitoringSharedConfig,
+				InfrastructureConfig:     tt.args.infrastructureConfig,
+				TLSProfile:               tt.args.tlsProfile,
+				ActiveConsoleRoute:       tt.args.rt,
+				InactivityTimeoutSeconds: tt.args.inactivityTimeoutSeconds,
+				AvailablePlugins:         tt.args.availablePlugins,
+				NodeArchitectures:        tt.args.nodeArchitectures,
+				NodeOperatingSystems:     tt.args.nodeOperatingSystems,
+				CopiedCSVsDisabled:       tt.args.copiedCSVsDisabled,
+				TelemeterConfig:          tt.args.telemetryConfig,
+				ConsoleHost:              tt.args.rt.Spec.Host,
+			})
 
 			// marshall the exampleYaml to map[string]interface{} so we can use it in diff below
 			var exampleConfig map[string]interface{}
@@ -1384,7 +1455,7 @@ func TestAggregateCSPDirectives(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			result := aggregateCSPDirectives(tt.input)
+			result := aggregateCSPDirectives(tt.input, &PluginCSPPolicy{})
 			sortDirectives(result)
 			if diff := deep.Equal(tt.output, result); diff != nil {
 				t.Error(diff)
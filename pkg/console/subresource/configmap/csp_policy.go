@@ -0,0 +1,139 @@
+package configmap
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"k8s.io/klog/v2"
+
+	v1 "github.com/openshift/api/console/v1"
+	operatorv1 "github.com/openshift/api/operator/v1"
+)
+
+// PluginCSPPolicyAnnotation is set on the operator config to a JSON PluginCSPPolicy
+// that every plugin contentSecurityPolicy value has to comply with.
+const PluginCSPPolicyAnnotation = "console.openshift.io/plugin-csp-policy"
+
+// bannedCSPSources are never accepted from a plugin, with or without a policy.
+var bannedCSPSources = map[string]bool{
+	"*":             true,
+	"'unsafe-eval'": true,
+	"unsafe-eval":   true,
+}
+
+// PluginCSPPolicy is the admin defined policy enforced on plugin CSP directives.
+type PluginCSPPolicy struct {
+	// AllowedSources, when set, lists the only sources plugins may add. An entry
+	// with a "*." host prefix, e.g. "https://*.example.com", matches any subdomain.
+	AllowedSources []string `json:"allowedSources,omitempty"`
+	// MaxSourcesPerDirective, when set, caps the number of sources a single plugin
+	// may add to a directive. Sources past the limit are dropped.
+	MaxSourcesPerDirective int `json:"maxSourcesPerDirective,omitempty"`
+}
+
+// GetPluginCSPPolicy reads the plugin CSP policy from the operator config. It returns
+// an empty policy when none is set, only the banned sources are enforced then.
+func GetPluginCSPPolicy(operatorConfig *operatorv1.Console) (*PluginCSPPolicy, error) {
+	policy := &PluginCSPPolicy{}
+	value, ok := operatorConfig.Annotations[PluginCSPPolicyAnnotation]
+	if !ok {
+		return policy, nil
+	}
+	if err := json.Unmarshal([]byte(value), policy); err != nil {
+		return nil, fmt.Errorf("invalid %s annotation: %w", PluginCSPPolicyAnnotation, err)
+	}
+	if policy.MaxSourcesPerDirective < 0 {
+		return nil, fmt.Errorf("invalid %s annotation: maxSourcesPerDirective must not be negative", PluginCSPPolicyAnnotation)
+	}
+	return policy, nil
+}
+
+// PluginCSPViolations returns an error listing, per plugin, the CSP values that the
+// policy set on the operator config drops from console-config.
+func PluginCSPViolations(operatorConfig *operatorv1.Console, plugins []*v1.ConsolePlugin) error {
+	policy, err := GetPluginCSPPolicy(operatorConfig)
+	if err != nil {
+		return err
+	}
+	_, violations := filterPluginCSP(policy, plugins)
+	if len(violations) == 0 {
+		return nil
+	}
+	pluginNames := make([]string, 0, len(violations))
+	for pluginName := range violations {
+		pluginNames = append(pluginNames, pluginName)
+	}
+	sort.Strings(pluginNames)
+	messages := make([]string, 0, len(pluginNames))
+	for _, pluginName := range pluginNames {
+		messages = append(messages, fmt.Sprintf("%s (%s)", pluginName, strings.Join(violations[pluginName], ", ")))
+	}
+	return fmt.Errorf("plugin CSP values dropped by policy: %s", strings.Join(messages, "; "))
+}
+
+// filterPluginCSP returns, for each plugin, the CSP directives that comply with the
+// policy, along with a description of every value that was dropped. A nil policy
+// means the policy could not be read, all values are dropped then.
+func filterPluginCSP(policy *PluginCSPPolicy, plugins []*v1.ConsolePlugin) (map[string][]v1.ConsolePluginCSP, map[string][]string) {
+	allowed := map[string][]v1.ConsolePluginCSP{}
+	violations := map[string][]string{}
+	for _, plugin := range plugins {
+		if policy == nil {
+			if len(plugin.Spec.ContentSecurityPolicy) > 0 {
+				violations[plugin.Name] = append(violations[plugin.Name], "invalid policy")
+			}
+			continue
+		}
+		for _, csp := range plugin.Spec.ContentSecurityPolicy {
+			values := []v1.CSPDirectiveValue{}
+			for _, value := range csp.Values {
+				source := string(value)
+				switch {
+				case bannedCSPSources[source]:
+					violations[plugin.Name] = append(violations[plugin.Name], fmt.Sprintf("%s: %s is not permitted", csp.Directive, source))
+				case !policy.allows(source):
+					violations[plugin.Name] = append(violations[plugin.Name], fmt.Sprintf("%s: %s is not in the allowed sources", csp.Directive, source))
+				case policy.MaxSourcesPerDirective > 0 && len(values) >= policy.MaxSourcesPerDirective:
+					violations[plugin.Name] = append(violations[plugin.Name], fmt.Sprintf("%s: %s exceeds %d sources", csp.Directive, source, policy.MaxSourcesPerDirective))
+				default:
+					values = append(values, value)
+				}
+			}
+			if len(values) > 0 {
+				allowed[plugin.Name] = append(allowed[plugin.Name], v1.ConsolePluginCSP{Directive: csp.Directive, Values: values})
+			}
+		}
+	}
+	return allowed, violations
+}
+
+func (p *PluginCSPPolicy) allows(source string) bool {
+	if len(p.AllowedSources) == 0 {
+		return true
+	}
+	for _, allowedSource := range p.AllowedSources {
+		if source == allowedSource {
+			return true
+		}
+		prefix, domain, isWildcard := strings.Cut(allowedSource, "*.")
+		if isWildcard && strings.HasPrefix(source, prefix) && strings.HasSuffix(source, "."+domain) {
+			// the wildcard only stands for subdomains of the host
+			subdomain := strings.TrimSuffix(strings.TrimPrefix(source, prefix), "."+domain)
+			if len(subdomain) > 0 && !strings.ContainsAny(subdomain, "/:") {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func pluginCSPPolicyOrNil(operatorConfig *operatorv1.Console) *PluginCSPPolicy {
+	policy, err := GetPluginCSPPolicy(operatorConfig)
+	if err != nil {
+		klog.Errorf("dropping all plugin CSP values: %v", err)
+		return nil
+	}
+	return policy
+}
@@ -0,0 +1,89 @@
+package configmap
+
+import (
+	"testing"
+
+	"github.com/go-test/deep"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	consolev1 "github.com/openshift/api/console/v1"
+	operatorv1 "github.com/openshift/api/operator/v1"
+)
+
+func cspPlugin(name string, directive consolev1.DirectiveType, values ...consolev1.CSPDirectiveValue) *consolev1.ConsolePlugin {
+	return &consolev1.ConsolePlugin{
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		Spec: consolev1.ConsolePluginSpec{
+			ContentSecurityPolicy: []consolev1.ConsolePluginCSP{{Directive: directive, Values: values}},
+		},
+	}
+}
+
+func TestAggregateCSPDirectivesWithPolicy(t *testing.T) {
+	plugins := []*consolev1.ConsolePlugin{
+		cspPlugin("plugin-a", consolev1.ScriptSrc, "https://cdn.example.com", "*", "'unsafe-eval'"),
+		cspPlugin("plugin-b", consolev1.ImgSrc, "https://img.example.com", "https://evil.com", "https://a.img.example.com", "https://b.img.example.com"),
+	}
+	tests := []struct {
+		name   string
+		policy *PluginCSPPolicy
+		output map[consolev1.DirectiveType][]string
+	}{
+		{
+			name:   "Banned sources are dropped without a policy",
+			policy: &PluginCSPPolicy{},
+			output: map[consolev1.DirectiveType][]string{
+				consolev1.ScriptSrc: {"https://cdn.example.com"},
+				consolev1.ImgSrc:    {"https://a.img.example.com", "https://b.img.example.com", "https://evil.com", "https://img.example.com"},
+			},
+		},
+		{
+			name: "Sources outside the allowlist and past the limit are dropped",
+			policy: &PluginCSPPolicy{
+				AllowedSources:         []string{"https://cdn.example.com", "https://*.example.com"},
+				MaxSourcesPerDirective: 2,
+			},
+			output: map[consolev1.DirectiveType][]string{
+				consolev1.ScriptSrc: {"https://cdn.example.com"},
+				consolev1.ImgSrc:    {"https://a.img.example.com", "https://img.example.com"},
+			},
+		},
+		{
+			name:   "Everything is dropped when the policy cannot be read",
+			policy: nil,
+			output: nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if diff := deep.Equal(aggregateCSPDirectives(plugins, tt.policy), tt.output); diff != nil {
+				t.Error(diff)
+			}
+		})
+	}
+}
+
+func TestPluginCSPViolations(t *testing.T) {
+	operatorConfig := &operatorv1.Console{
+		ObjectMeta: metav1.ObjectMeta{
+			Annotations: map[string]string{PluginCSPPolicyAnnotation: `{"allowedSources": ["https://*.example.com"]}`},
+		},
+	}
+	plugins := []*consolev1.ConsolePlugin{
+		cspPlugin("plugin-b", consolev1.ScriptSrc, "https://cdn.example.com", "https://example.com.evil.com"),
+		cspPlugin("plugin-a", consolev1.FontSrc, "*"),
+		cspPlugin("plugin-c", consolev1.FontSrc, "https://fonts.example.com"),
+	}
+	want := "plugin CSP values dropped by policy: plugin-a (FontSrc: * is not permitted); " +
+		"plugin-b (ScriptSrc: https://example.com.evil.com is not in the allowed sources)"
+	err := PluginCSPViolations(operatorConfig, plugins)
+	if err == nil || err.Error() != want {
+		t.Errorf("expected %q, got %v", want, err)
+	}
+
+	operatorConfig.Annotations[PluginCSPPolicyAnnotation] = "{"
+	if err := PluginCSPViolations(operatorConfig, plugins); err == nil {
+		t.Error("expected an error for an invalid policy")
+	}
+}
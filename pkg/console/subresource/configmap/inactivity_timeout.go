@@ -0,0 +1,33 @@
+package configmap
+
+import (
+	"fmt"
+	"time"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+)
+
+// OIDCInactivityTimeoutAnnotation is set on the operator config to how long a console
+// session can stay idle, such as "15m", when the cluster authenticates through an external
+// OIDC provider. With the integrated OAuth server the timeout comes from the OAuth config.
+const OIDCInactivityTimeoutAnnotation = "console.openshift.io/oidc-inactivity-timeout"
+
+const (
+	// the same lower bound as the access token inactivity timeout of the OAuth server
+	minOIDCInactivityTimeout = 5 * time.Minute
+	maxOIDCInactivityTimeout = 24 * time.Hour
+)
+
+// GetOIDCInactivityTimeoutSeconds returns the inactivity timeout of console sessions on an
+// OIDC cluster, zero when unset. An invalid timeout is not applied.
+func GetOIDCInactivityTimeoutSeconds(operatorConfig *operatorv1.Console) (int, error) {
+	value, ok := operatorConfig.Annotations[OIDCInactivityTimeoutAnnotation]
+	if !ok {
+		return 0, nil
+	}
+	timeout, err := time.ParseDuration(value)
+	if err != nil || timeout < minOIDCInactivityTimeout || timeout > maxOIDCInactivityTimeout {
+		return 0, fmt.Errorf("invalid %s annotation %q, must be a duration between %s and %s", OIDCInactivityTimeoutAnnotation, value, minOIDCInactivityTimeout, maxOIDCInactivityTimeout)
+	}
+	return int(timeout.Seconds()), nil
+}
@@ -0,0 +1,54 @@
+package configmap
+
+import (
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+)
+
+func TestGetOIDCInactivityTimeoutSeconds(t *testing.T) {
+	tests := []struct {
+		name        string
+		annotations map[string]string
+		want        int
+		wantErr     bool
+	}{
+		{
+			name: "No timeout",
+		},
+		{
+			name:        "Valid timeout",
+			annotations: map[string]string{OIDCInactivityTimeoutAnnotation: "15m"},
+			want:        900,
+		},
+		{
+			name:        "Timeout below the range",
+			annotations: map[string]string{OIDCInactivityTimeoutAnnotation: "30s"},
+			wantErr:     true,
+		},
+		{
+			name:        "Timeout above the range",
+			annotations: map[string]string{OIDCInactivityTimeoutAnnotation: "48h"},
+			wantErr:     true,
+		},
+		{
+			name:        "Timeout without a unit",
+			annotations: map[string]string{OIDCInactivityTimeoutAnnotation: "900"},
+			wantErr:     true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			operatorConfig := &operatorv1.Console{ObjectMeta: metav1.ObjectMeta{Annotations: tt.annotations}}
+			got, err := GetOIDCInactivityTimeoutSeconds(operatorConfig)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("timeout = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,228 @@
+package configmap
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/klog/v2"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+
+	"github.com/openshift/console-operator/pkg/api"
+	"github.com/openshift/console-operator/pkg/console/subresource/consoleserver"
+	"github.com/openshift/console-operator/pkg/console/subresource/util"
+)
+
+const (
+	// ConfigRollbackWindowAnnotation is set on the operator config to enable the rollback,
+	// with how long the console pods may fail on a new console-config before the last known
+	// good config is restored. The rollback is off without it, or with a zero duration.
+	ConfigRollbackWindowAnnotation = "console.openshift.io/config-rollback-window"
+	// ConfigRejectionTimeout is how long a rejected console-config stays rejected before it
+	// is tried again.
+	ConfigRejectionTimeout = time.Hour
+
+	// generatedConfigHashAnnotation is set on console-config to the hash of the config the
+	// operator generated, it only differs from the actual content after a rollback.
+	generatedConfigHashAnnotation = "console.openshift.io/generated-config-hash"
+
+	// annotations on the last known good snapshot that track the rollout in flight and the
+	// generated config that was rejected.
+	pendingConfigHashAnnotation   = "console.openshift.io/pending-config-hash"
+	pendingConfigSinceAnnotation  = "console.openshift.io/pending-config-since"
+	rejectedConfigHashAnnotation  = "console.openshift.io/rejected-config-hash"
+	rejectedConfigPathsAnnotation = "console.openshift.io/rejected-config-paths"
+	rejectedConfigSinceAnnotation = "console.openshift.io/rejected-config-since"
+)
+
+// ConfigRollbackWindow returns the rollback window requested on the operator config, zero
+// when the rollback is off.
+func ConfigRollbackWindow(operatorConfig *operatorv1.Console) time.Duration {
+	value, ok := operatorConfig.Annotations[ConfigRollbackWindowAnnotation]
+	if !ok {
+		return 0
+	}
+	window, err := time.ParseDuration(value)
+	if err != nil || window < 0 {
+		klog.Warningf("invalid %s annotation %q, the console-config rollback is off", ConfigRollbackWindowAnnotation, value)
+		return 0
+	}
+	return window
+}
+
+// ConfigHash returns a hash of the data of a config map.
+func ConfigHash(configMap *corev1.ConfigMap) string {
+	keys := make([]string, 0, len(configMap.Data))
+	for key := range configMap.Data {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	hash := sha256.New()
+	for _, key := range keys {
+		fmt.Fprintf(hash, "%s\x00%s\x00", key, configMap.Data[key])
+	}
+	return fmt.Sprintf("%x", hash.Sum(nil))
+}
+
+// WithLastKnownGood marks the generated console-config with its hash and, if that exact
+// config was rejected before, swaps its content for the last known good snapshot.
+// It returns true when the snapshot content was restored.
+func WithLastKnownGood(consoleConfigMap *corev1.ConfigMap, lastKnownGood *corev1.ConfigMap) bool {
+	generatedHash := ConfigHash(consoleConfigMap)
+	if consoleConfigMap.Annotations == nil {
+		consoleConfigMap.Annotations = map[string]string{}
+	}
+	consoleConfigMap.Annotations[generatedConfigHashAnnotation] = generatedHash
+
+	if lastKnownGood == nil || len(lastKnownGood.Data) == 0 || lastKnownGood.Annotations[rejectedConfigHashAnnotation] != generatedHash {
+		return false
+	}
+	consoleConfigMap.Data = map[string]string{}
+	for key, value := range lastKnownGood.Data {
+		consoleConfigMap.Data[key] = value
+	}
+	return true
+}
+
+// RolledBackError returns an error naming the rejected change when the live
+// console-config holds the last known good snapshot instead of the generated config.
+func RolledBackError(consoleConfigMap *corev1.ConfigMap, lastKnownGood *corev1.ConfigMap) error {
+	generatedHash, ok := consoleConfigMap.Annotations[generatedConfigHashAnnotation]
+	if !ok || generatedHash == ConfigHash(consoleConfigMap) {
+		return nil
+	}
+	changedPaths := ""
+	if lastKnownGood != nil {
+		changedPaths = lastKnownGood.Annotations[rejectedConfigPathsAnnotation]
+	}
+	return fmt.Errorf("console-config change (%s) did not roll out, restored the last known good config", changedPaths)
+}
+
+// NextLastKnownGood works out how the last known good snapshot should be updated for the
+// live console-config. The snapshot follows console-config whenever it has rolled out.
+// Otherwise the time of the first attempt is recorded, and once the window has passed with
+// the console pods failing on the config, the generated config is marked as rejected so
+// that WithLastKnownGood restores the snapshot. Pods that are not scheduled or still pull
+// their image are not failing on the config, the rollout is waited for then.
+// A rejection is cleared by the rollout of another generated config, after the rejection
+// timeout, and when the rollback is off.
+// It returns nil when the snapshot does not need to change. Annotations are merged on
+// apply, the returned snapshot only carries the ones to set and the ones to remove.
+func NextLastKnownGood(
+	operatorConfig *operatorv1.Console,
+	lastKnownGood *corev1.ConfigMap,
+	consoleConfigMap *corev1.ConfigMap,
+	rolledOut bool,
+	configFailing bool,
+	window time.Duration,
+	now time.Time,
+) (*corev1.ConfigMap, error) {
+	currentHash := ConfigHash(consoleConfigMap)
+	generatedHash, ok := consoleConfigMap.Annotations[generatedConfigHashAnnotation]
+	rolledBack := ok && generatedHash != currentHash
+
+	if lastKnownGood != nil && isRejected(lastKnownGood) && (window == 0 || isRejectionExpired(lastKnownGood, now)) {
+		klog.V(4).Infoln("console-config rejection is over, the generated config is tried again")
+		next := lastKnownGoodFrom(operatorConfig, lastKnownGood)
+		clearRejected(next)
+		return next, nil
+	}
+
+	if rolledOut {
+		// the restored snapshot rolling out does not clear the rejection of the generated config
+		if lastKnownGood != nil && ConfigHash(lastKnownGood) == currentHash && !isPending(lastKnownGood) && (rolledBack || !isRejected(lastKnownGood)) {
+			return nil, nil
+		}
+		next := lastKnownGoodFrom(operatorConfig, consoleConfigMap)
+		clearPending(next)
+		if !rolledBack {
+			clearRejected(next)
+		}
+		return next, nil
+	}
+
+	// nothing to go back to, or the snapshot itself is what is being rolled out
+	if lastKnownGood == nil || window == 0 || ConfigHash(lastKnownGood) == currentHash {
+		return nil, nil
+	}
+	// a rollback is already in place
+	if rolledBack {
+		return nil, nil
+	}
+
+	next := lastKnownGoodFrom(operatorConfig, lastKnownGood)
+	since, err := time.Parse(time.RFC3339, lastKnownGood.Annotations[pendingConfigSinceAnnotation])
+	if lastKnownGood.Annotations[pendingConfigHashAnnotation] != currentHash || err != nil {
+		next.Annotations[pendingConfigHashAnnotation] = currentHash
+		next.Annotations[pendingConfigSinceAnnotation] = now.UTC().Format(time.RFC3339)
+		return next, nil
+	}
+	if now.Sub(since) < window || !configFailing {
+		return nil, nil
+	}
+
+	changedPaths, err := consoleserver.ChangedPaths(
+		[]byte(lastKnownGood.Data[consoleConfigYamlFile]),
+		[]byte(consoleConfigMap.Data[consoleConfigYamlFile]),
+	)
+	if err != nil {
+		return nil, err
+	}
+	klog.Warningf("console pods are failing on the console-config change after %v, restoring the last known good config", window)
+	next.Annotations[rejectedConfigHashAnnotation] = currentHash
+	next.Annotations[rejectedConfigPathsAnnotation] = strings.Join(changedPaths, ", ")
+	next.Annotations[rejectedConfigSinceAnnotation] = now.UTC().Format(time.RFC3339)
+	clearPending(next)
+	return next, nil
+}
+
+func lastKnownGoodFrom(operatorConfig *operatorv1.Console, source *corev1.ConfigMap) *corev1.ConfigMap {
+	lastKnownGood := LastKnownGoodStub()
+	lastKnownGood.Data = map[string]string{}
+	for key, value := range source.Data {
+		lastKnownGood.Data[key] = value
+	}
+	util.AddOwnerRef(lastKnownGood, util.OwnerRefFrom(operatorConfig))
+	return lastKnownGood
+}
+
+func isPending(lastKnownGood *corev1.ConfigMap) bool {
+	_, ok := lastKnownGood.Annotations[pendingConfigHashAnnotation]
+	return ok
+}
+
+func isRejected(lastKnownGood *corev1.ConfigMap) bool {
+	_, ok := lastKnownGood.Annotations[rejectedConfigHashAnnotation]
+	return ok
+}
+
+// isRejectionExpired returns true once the rejection timeout has passed, or when the time
+// of the rejection is unknown.
+func isRejectionExpired(lastKnownGood *corev1.ConfigMap, now time.Time) bool {
+	since, err := time.Parse(time.RFC3339, lastKnownGood.Annotations[rejectedConfigSinceAnnotation])
+	return err != nil || now.Sub(since) >= ConfigRejectionTimeout
+}
+
+func clearRejected(lastKnownGood *corev1.ConfigMap) {
+	lastKnownGood.Annotations[rejectedConfigHashAnnotation+"-"] = ""
+	lastKnownGood.Annotations[rejectedConfigPathsAnnotation+"-"] = ""
+	lastKnownGood.Annotations[rejectedConfigSinceAnnotation+"-"] = ""
+}
+
+// clearPending uses the "-" suffix understood by resourcemerge to drop the annotations on apply.
+func clearPending(lastKnownGood *corev1.ConfigMap) {
+	lastKnownGood.Annotations[pendingConfigHashAnnotation+"-"] = ""
+	lastKnownGood.Annotations[pendingConfigSinceAnnotation+"-"] = ""
+}
+
+func LastKnownGoodStub() *corev1.ConfigMap {
+	meta := util.SharedMeta()
+	meta.Name = api.ConsoleConfigLastKnownGoodName
+	return &corev1.ConfigMap{
+		ObjectMeta: meta,
+	}
+}
@@ -0,0 +1,307 @@
+package configmap
+
+import (
+	"testing"
+	"time"
+
+	"github.com/go-test/deep"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+)
+
+const (
+	goodConfig = `kind: ConsoleConfig
+customization:
+  branding: okd
+`
+	badConfig = `kind: ConsoleConfig
+customization:
+  branding: okd
+auth:
+  oidcIssuer: https://issuer.example.com
+`
+)
+
+func consoleConfigMapWith(config string, generated string) *corev1.ConfigMap {
+	configMap := Stub()
+	configMap.Data = map[string]string{consoleConfigYamlFile: config}
+	generatedConfigMap := Stub()
+	generatedConfigMap.Data = map[string]string{consoleConfigYamlFile: generated}
+	configMap.Annotations[generatedConfigHashAnnotation] = ConfigHash(generatedConfigMap)
+	return configMap
+}
+
+func lastKnownGoodWith(config string, annotations map[string]string) *corev1.ConfigMap {
+	lastKnownGood := LastKnownGoodStub()
+	lastKnownGood.Data = map[string]string{consoleConfigYamlFile: config}
+	for key, value := range annotations {
+		lastKnownGood.Annotations[key] = value
+	}
+	return lastKnownGood
+}
+
+func TestNextLastKnownGood(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	badHash := ConfigHash(consoleConfigMapWith(badConfig, badConfig))
+
+	tests := []struct {
+		name          string
+		lastKnownGood *corev1.ConfigMap
+		consoleConfig *corev1.ConfigMap
+		rolledOut     bool
+		configFailing bool
+		window        time.Duration
+		want          map[string]string
+		wantNil       bool
+	}{
+		{
+			name:          "Rolled out config becomes the last known good",
+			consoleConfig: consoleConfigMapWith(goodConfig, goodConfig),
+			rolledOut:     true,
+			window:        time.Minute,
+			want: map[string]string{
+				pendingConfigHashAnnotation + "-":   "",
+				pendingConfigSinceAnnotation + "-":  "",
+				rejectedConfigHashAnnotation + "-":  "",
+				rejectedConfigPathsAnnotation + "-": "",
+				rejectedConfigSinceAnnotation + "-": "",
+			},
+		},
+		{
+			name:          "Unchanged last known good is left alone",
+			lastKnownGood: lastKnownGoodWith(goodConfig, nil),
+			consoleConfig: consoleConfigMapWith(goodConfig, goodConfig),
+			rolledOut:     true,
+			window:        time.Minute,
+			wantNil:       true,
+		},
+		{
+			name:          "First attempt of a new config is recorded as pending",
+			lastKnownGood: lastKnownGoodWith(goodConfig, nil),
+			consoleConfig: consoleConfigMapWith(badConfig, badConfig),
+			window:        time.Minute,
+			want: map[string]string{
+				pendingConfigHashAnnotation:  badHash,
+				pendingConfigSinceAnnotation: now.Format(time.RFC3339),
+			},
+		},
+		{
+			name: "Pending config within the window is left alone",
+			lastKnownGood: lastKnownGoodWith(goodConfig, map[string]string{
+				pendingConfigHashAnnotation:  badHash,
+				pendingConfigSinceAnnotation: now.Add(-30 * time.Second).Format(time.RFC3339),
+			}),
+			consoleConfig: consoleConfigMapWith(badConfig, badConfig),
+			window:        time.Minute,
+			wantNil:       true,
+		},
+		{
+			name: "Pending config failing past the window is rejected",
+			lastKnownGood: lastKnownGoodWith(goodConfig, map[string]string{
+				pendingConfigHashAnnotation:  badHash,
+				pendingConfigSinceAnnotation: now.Add(-2 * time.Minute).Format(time.RFC3339),
+			}),
+			consoleConfig: consoleConfigMapWith(badConfig, badConfig),
+			configFailing: true,
+			window:        time.Minute,
+			want: map[string]string{
+				rejectedConfigHashAnnotation:       badHash,
+				rejectedConfigPathsAnnotation:      "auth.oidcIssuer",
+				rejectedConfigSinceAnnotation:      now.Format(time.RFC3339),
+				pendingConfigHashAnnotation + "-":  "",
+				pendingConfigSinceAnnotation + "-": "",
+			},
+		},
+		{
+			name: "Pending config past the window is not rejected when the pods are not failing on it",
+			lastKnownGood: lastKnownGoodWith(goodConfig, map[string]string{
+				pendingConfigHashAnnotation:  badHash,
+				pendingConfigSinceAnnotation: now.Add(-2 * time.Minute).Format(time.RFC3339),
+			}),
+			consoleConfig: consoleConfigMapWith(badConfig, badConfig),
+			window:        time.Minute,
+			wantNil:       true,
+		},
+		{
+			name: "Rejection stays while the snapshot is restored",
+			lastKnownGood: lastKnownGoodWith(goodConfig, map[string]string{
+				rejectedConfigHashAnnotation:  badHash,
+				rejectedConfigSinceAnnotation: now.Add(-time.Minute).Format(time.RFC3339),
+			}),
+			consoleConfig: consoleConfigMapWith(goodConfig, badConfig),
+			rolledOut:     true,
+			window:        time.Minute,
+			wantNil:       true,
+		},
+		{
+			name: "Rejection is cleared by the rollout of another generated config",
+			lastKnownGood: lastKnownGoodWith(goodConfig, map[string]string{
+				rejectedConfigHashAnnotation:  badHash,
+				rejectedConfigSinceAnnotation: now.Add(-time.Minute).Format(time.RFC3339),
+			}),
+			consoleConfig: consoleConfigMapWith(goodConfig, goodConfig),
+			rolledOut:     true,
+			window:        time.Minute,
+			want: map[string]string{
+				pendingConfigHashAnnotation + "-":   "",
+				pendingConfigSinceAnnotation + "-":  "",
+				rejectedConfigHashAnnotation + "-":  "",
+				rejectedConfigPathsAnnotation + "-": "",
+				rejectedConfigSinceAnnotation + "-": "",
+			},
+		},
+		{
+			name: "Rejection is cleared after the timeout",
+			lastKnownGood: lastKnownGoodWith(goodConfig, map[string]string{
+				rejectedConfigHashAnnotation:  badHash,
+				rejectedConfigSinceAnnotation: now.Add(-ConfigRejectionTimeout).Format(time.RFC3339),
+			}),
+			consoleConfig: consoleConfigMapWith(goodConfig, badConfig),
+			rolledOut:     true,
+			window:        time.Minute,
+			want: map[string]string{
+				rejectedConfigHashAnnotation + "-":  "",
+				rejectedConfigPathsAnnotation + "-": "",
+				rejectedConfigSinceAnnotation + "-": "",
+			},
+		},
+		{
+			name: "Rejection is cleared when the rollback is turned off",
+			lastKnownGood: lastKnownGoodWith(goodConfig, map[string]string{
+				rejectedConfigHashAnnotation:  badHash,
+				rejectedConfigSinceAnnotation: now.Add(-time.Minute).Format(time.RFC3339),
+			}),
+			consoleConfig: consoleConfigMapWith(goodConfig, badConfig),
+			rolledOut:     true,
+			want: map[string]string{
+				rejectedConfigHashAnnotation + "-":  "",
+				rejectedConfigPathsAnnotation + "-": "",
+				rejectedConfigSinceAnnotation + "-": "",
+			},
+		},
+		{
+			name: "Zero window disables the rollback",
+			lastKnownGood: lastKnownGoodWith(goodConfig, map[string]string{
+				pendingConfigHashAnnotation:  badHash,
+				pendingConfigSinceAnnotation: now.Add(-2 * time.Minute).Format(time.RFC3339),
+			}),
+			consoleConfig: consoleConfigMapWith(badConfig, badConfig),
+			configFailing: true,
+			window:        0,
+			wantNil:       true,
+		},
+		{
+			name: "Restored config is not tracked again",
+			lastKnownGood: lastKnownGoodWith(goodConfig, map[string]string{
+				rejectedConfigHashAnnotation:  badHash,
+				rejectedConfigSinceAnnotation: now.Add(-time.Minute).Format(time.RFC3339),
+			}),
+			consoleConfig: consoleConfigMapWith(goodConfig, badConfig),
+			window:        time.Minute,
+			wantNil:       true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			next, err := NextLastKnownGood(&operatorv1.Console{}, tt.lastKnownGood, tt.consoleConfig, tt.rolledOut, tt.configFailing, tt.window, now)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tt.wantNil {
+				if next != nil {
+					t.Errorf("expected no update, got %v", next.Annotations)
+				}
+				return
+			}
+			if next == nil {
+				t.Fatal("expected an update")
+			}
+			if diff := deep.Equal(next.Annotations, tt.want); diff != nil {
+				t.Error(diff)
+			}
+		})
+	}
+}
+
+func TestConfigRollbackWindow(t *testing.T) {
+	tests := []struct {
+		name        string
+		annotations map[string]string
+		want        time.Duration
+	}{
+		{
+			name: "Rollback is off by default",
+			want: 0,
+		},
+		{
+			name:        "Rollback window is set",
+			annotations: map[string]string{ConfigRollbackWindowAnnotation: "15m"},
+			want:        15 * time.Minute,
+		},
+		{
+			name:        "Invalid rollback window turns the rollback off",
+			annotations: map[string]string{ConfigRollbackWindowAnnotation: "-1m"},
+			want:        0,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			operatorConfig := &operatorv1.Console{ObjectMeta: metav1.ObjectMeta{Annotations: tt.annotations}}
+			if got := ConfigRollbackWindow(operatorConfig); got != tt.want {
+				t.Errorf("ConfigRollbackWindow() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWithLastKnownGood(t *testing.T) {
+	badHash := ConfigHash(consoleConfigMapWith(badConfig, badConfig))
+	tests := []struct {
+		name           string
+		lastKnownGood  *corev1.ConfigMap
+		wantRestored   bool
+		wantConfig     string
+		wantRolledBack bool
+	}{
+		{
+			name:       "Config is applied when there is no last known good",
+			wantConfig: badConfig,
+		},
+		{
+			name:          "Config is applied when it was not rejected",
+			lastKnownGood: lastKnownGoodWith(goodConfig, nil),
+			wantConfig:    badConfig,
+		},
+		{
+			name: "Rejected config is replaced by the last known good",
+			lastKnownGood: lastKnownGoodWith(goodConfig, map[string]string{
+				rejectedConfigHashAnnotation:  badHash,
+				rejectedConfigPathsAnnotation: "auth.oidcIssuer",
+			}),
+			wantRestored:   true,
+			wantConfig:     goodConfig,
+			wantRolledBack: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			consoleConfig := &corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{Name: "console-config"},
+				Data:       map[string]string{consoleConfigYamlFile: badConfig},
+			}
+			if diff := deep.Equal(WithLastKnownGood(consoleConfig, tt.lastKnownGood), tt.wantRestored); diff != nil {
+				t.Error(diff)
+			}
+			if diff := deep.Equal(consoleConfig.Data[consoleConfigYamlFile], tt.wantConfig); diff != nil {
+				t.Error(diff)
+			}
+			rolledBackErr := RolledBackError(consoleConfig, tt.lastKnownGood)
+			if diff := deep.Equal(rolledBackErr != nil, tt.wantRolledBack); diff != nil {
+				t.Error(diff)
+			}
+		})
+	}
+}
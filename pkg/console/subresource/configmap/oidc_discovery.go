@@ -0,0 +1,119 @@
+package configmap
+
+import (
+	"encoding/json"
+	"strings"
+
+	yaml2 "github.com/ghodss/yaml"
+	"gopkg.in/yaml.v2"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+
+	"github.com/openshift/console-operator/pkg/api"
+	"github.com/openshift/console-operator/pkg/console/subresource/consoleserver"
+	"github.com/openshift/console-operator/pkg/console/subresource/util"
+)
+
+// verifiedIssuersKey lists the OIDC issuers that passed the discovery pre-flight, one per line.
+const verifiedIssuersKey = "verifiedIssuers"
+
+// DefaultOIDCDiscoveryConfigMap creates the config map listing the OIDC issuers that
+// passed the discovery pre-flight. Auth changes to an issuer missing from it are held
+// back from console-config.
+func DefaultOIDCDiscoveryConfigMap(cr *operatorv1.Console, verifiedIssuers sets.Set[string]) *corev1.ConfigMap {
+	configMap := OIDCDiscoveryStub()
+	configMap.Data = map[string]string{
+		verifiedIssuersKey: strings.Join(sets.List(verifiedIssuers), "\n"),
+	}
+	util.AddOwnerRef(configMap, util.OwnerRefFrom(cr))
+	return configMap
+}
+
+// UnverifiedOIDCIssuers returns the OIDC issuers of the generated console-config that are
+// not listed as verified in the discovery config map, which may be nil.
+func UnverifiedOIDCIssuers(generatedConfigMap *corev1.ConfigMap, discoveryConfigMap *corev1.ConfigMap) ([]string, error) {
+	config := &consoleserver.Config{}
+	if err := yaml.Unmarshal([]byte(generatedConfigMap.Data[consoleConfigYamlFile]), config); err != nil {
+		return nil, err
+	}
+	issuers := sets.New[string]()
+	if len(config.Auth.OIDCIssuer) > 0 {
+		issuers.Insert(config.Auth.OIDCIssuer)
+	}
+
+	return sets.List(issuers.Difference(verifiedOIDCIssuers(discoveryConfigMap))), nil
+}
+
+// IsOIDCIssuerVerified returns whether the issuer is listed as verified in the discovery
+// config map, which may be nil.
+func IsOIDCIssuerVerified(discoveryConfigMap *corev1.ConfigMap, issuer string) bool {
+	return verifiedOIDCIssuers(discoveryConfigMap).Has(issuer)
+}
+
+func verifiedOIDCIssuers(discoveryConfigMap *corev1.ConfigMap) sets.Set[string] {
+	verified := sets.New[string]()
+	if discoveryConfigMap == nil {
+		return verified
+	}
+	for _, issuer := range strings.Split(discoveryConfigMap.Data[verifiedIssuersKey], "\n") {
+		if len(issuer) > 0 {
+			verified.Insert(issuer)
+		}
+	}
+	return verified
+}
+
+// WithLiveAuth replaces the auth section of the generated console-config with the one of
+// the live console-config, leaving the rest of the generated config as is.
+func WithLiveAuth(generatedConfigMap *corev1.ConfigMap, consoleConfigMap *corev1.ConfigMap) error {
+	generated, err := configFields(generatedConfigMap)
+	if err != nil {
+		return err
+	}
+	live, err := configFields(consoleConfigMap)
+	if err != nil {
+		return err
+	}
+	if liveAuth, ok := live["auth"]; ok {
+		generated["auth"] = liveAuth
+	} else {
+		delete(generated, "auth")
+	}
+
+	configJSON, err := json.Marshal(generated)
+	if err != nil {
+		return err
+	}
+	configYAML, err := yaml2.JSONToYAML(configJSON)
+	if err != nil {
+		return err
+	}
+	generatedConfigMap.Data[consoleConfigYamlFile] = string(configYAML)
+	return nil
+}
+
+func configFields(configMap *corev1.ConfigMap) (map[string]json.RawMessage, error) {
+	fields := map[string]json.RawMessage{}
+	configJSON, err := yaml2.YAMLToJSON([]byte(configMap.Data[consoleConfigYamlFile]))
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(configJSON, &fields); err != nil {
+		return nil, err
+	}
+	// an empty config decodes to a nil map
+	if fields == nil {
+		fields = map[string]json.RawMessage{}
+	}
+	return fields, nil
+}
+
+func OIDCDiscoveryStub() *corev1.ConfigMap {
+	meta := util.SharedMeta()
+	meta.Name = api.ConsoleOIDCDiscoveryName
+	return &corev1.ConfigMap{
+		ObjectMeta: meta,
+	}
+}
@@ -0,0 +1,116 @@
+package configmap
+
+import (
+	"testing"
+
+	"github.com/go-test/deep"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+
+	"github.com/openshift/console-operator/pkg/console/subresource/consoleserver"
+)
+
+const oidcConfig = `kind: ConsoleConfig
+auth:
+  authType: oidc
+  oidcIssuer: https://sso.example.com
+customization:
+  branding: ocp
+`
+
+func configMapWith(config string) *corev1.ConfigMap {
+	return &corev1.ConfigMap{Data: map[string]string{consoleConfigYamlFile: config}}
+}
+
+func TestUnverifiedOIDCIssuers(t *testing.T) {
+	operatorConfig := &operatorv1.Console{}
+	tests := []struct {
+		name      string
+		generated string
+		discovery *corev1.ConfigMap
+		want      []string
+	}{
+		{
+			name:      "No OIDC issuer",
+			generated: brandingConfig,
+			want:      []string{},
+		},
+		{
+			name:      "No discovery yet",
+			generated: oidcConfig,
+			want:      []string{"https://sso.example.com"},
+		},
+		{
+			name:      "Another issuer verified",
+			generated: oidcConfig,
+			discovery: DefaultOIDCDiscoveryConfigMap(operatorConfig, sets.New("https://old.example.com")),
+			want:      []string{"https://sso.example.com"},
+		},
+		{
+			name:      "Issuer verified",
+			generated: oidcConfig,
+			discovery: DefaultOIDCDiscoveryConfigMap(operatorConfig, sets.New("https://sso.example.com", "https://old.example.com")),
+			want:      []string{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := UnverifiedOIDCIssuers(configMapWith(tt.generated), tt.discovery)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if diff := deep.Equal(tt.want, got); diff != nil {
+				t.Error(diff)
+			}
+		})
+	}
+}
+
+func TestWithLiveAuth(t *testing.T) {
+	tests := []struct {
+		name      string
+		generated string
+		live      string
+		want      string
+	}{
+		{
+			name:      "Auth change is held back, the rest goes through",
+			generated: oidcConfig,
+			live: `kind: ConsoleConfig
+auth:
+  authType: openshift
+customization:
+  branding: okd
+`,
+			want: `auth:
+  authType: openshift
+customization:
+  branding: ocp
+kind: ConsoleConfig
+`,
+		},
+		{
+			name:      "No auth in the live config",
+			generated: oidcConfig,
+			live:      brandingConfig,
+			want:      brandingConfig,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			generated := configMapWith(tt.generated)
+			if err := WithLiveAuth(generated, configMapWith(tt.live)); err != nil {
+				t.Fatal(err)
+			}
+			changedPaths, err := consoleserver.ChangedPaths([]byte(tt.want), []byte(generated.Data[consoleConfigYamlFile]))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(changedPaths) > 0 {
+				t.Errorf("unexpected config, changed paths %v:\n%s", changedPaths, generated.Data[consoleConfigYamlFile])
+			}
+		})
+	}
+}
@@ -0,0 +1,198 @@
+package configmap
+
+import (
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+
+	v1 "github.com/openshift/api/console/v1"
+	operatorv1 "github.com/openshift/api/operator/v1"
+
+	"github.com/openshift/console-operator/pkg/console/subresource/consoleserver"
+)
+
+const (
+	// PluginExternalProxyAnnotation is set on a ConsolePlugin to a JSON list of
+	// ExternalProxyEndpoint, proxies to backends that do not run as a cluster Service.
+	PluginExternalProxyAnnotation = "console.openshift.io/external-proxy-endpoints"
+	// PluginProxyAllowedHostsAnnotation is set on the operator config to a comma separated
+	// list of the hosts external plugin proxies may point to. A "*." prefix matches any
+	// subdomain. No external proxy is rendered while it is unset.
+	PluginProxyAllowedHostsAnnotation = "console.openshift.io/plugin-proxy-allowed-hosts"
+
+	// pluginProxyCABundleKey is the key of the CA bundle in the referenced config maps.
+	pluginProxyCABundleKey = "ca-bundle.crt"
+)
+
+var proxyAliasRegexp = regexp.MustCompile(`^[A-Za-z0-9-_]+$`)
+
+// ExternalProxyEndpoint is a plugin proxy to a backend reached by URL.
+type ExternalProxyEndpoint struct {
+	Alias string `json:"alias"`
+	URL   string `json:"url"`
+	// CAConfigMap names a config map in openshift-config whose ca-bundle.crt is used
+	// to verify the backend, the system trust is used when it is not set.
+	CAConfigMap   string               `json:"caConfigMap,omitempty"`
+	Authorization v1.AuthorizationType `json:"authorization,omitempty"`
+}
+
+// ExternalProxyEndpoints reads the external proxy endpoints declared on a plugin.
+func ExternalProxyEndpoints(plugin *v1.ConsolePlugin) ([]ExternalProxyEndpoint, error) {
+	value, ok := plugin.Annotations[PluginExternalProxyAnnotation]
+	if !ok {
+		return nil, nil
+	}
+	endpoints := []ExternalProxyEndpoint{}
+	if err := json.Unmarshal([]byte(value), &endpoints); err != nil {
+		return nil, fmt.Errorf("invalid %s annotation: %w", PluginExternalProxyAnnotation, err)
+	}
+	return endpoints, nil
+}
+
+// ExternalProxyCAConfigMapNames returns the names of the CA config maps referenced by
+// the external proxy endpoints of the plugins.
+func ExternalProxyCAConfigMapNames(plugins []*v1.ConsolePlugin) []string {
+	names := map[string]bool{}
+	for _, plugin := range plugins {
+		endpoints, _ := ExternalProxyEndpoints(plugin)
+		for _, endpoint := range endpoints {
+			if len(endpoint.CAConfigMap) != 0 {
+				names[endpoint.CAConfigMap] = true
+			}
+		}
+	}
+	sortedNames := make([]string, 0, len(names))
+	for name := range names {
+		sortedNames = append(sortedNames, name)
+	}
+	sort.Strings(sortedNames)
+	return sortedNames
+}
+
+// PluginProxyCABundles extracts the CA bundles of the config maps referenced by
+// external proxy endpoints, keyed by config map name.
+func PluginProxyCABundles(configMaps []*corev1.ConfigMap) map[string]string {
+	caBundles := map[string]string{}
+	for _, configMap := range configMaps {
+		if caBundle, ok := configMap.Data[pluginProxyCABundleKey]; ok {
+			caBundles[configMap.Name] = caBundle
+		}
+	}
+	return caBundles
+}
+
+// PluginProxyViolations returns an error listing, per plugin, the proxies that are
+// left out of console-config because they failed validation.
+func PluginProxyViolations(operatorConfig *operatorv1.Console, plugins []*v1.ConsolePlugin, caBundles map[string]string) error {
+	_, violations := getPluginsProxyServices(operatorConfig, plugins, caBundles)
+	if len(violations) == 0 {
+		return nil
+	}
+	pluginNames := make([]string, 0, len(violations))
+	for pluginName := range violations {
+		pluginNames = append(pluginNames, pluginName)
+	}
+	sort.Strings(pluginNames)
+	messages := make([]string, 0, len(pluginNames))
+	for _, pluginName := range pluginNames {
+		messages = append(messages, fmt.Sprintf("%s (%s)", pluginName, strings.Join(violations[pluginName], ", ")))
+	}
+	return fmt.Errorf("invalid plugin proxies dropped: %s", strings.Join(messages, "; "))
+}
+
+func getExternalProxyService(pluginName string, endpoint ExternalProxyEndpoint, allowedHosts []string, caBundles map[string]string) (consoleserver.ProxyService, error) {
+	if !proxyAliasRegexp.MatchString(endpoint.Alias) {
+		return consoleserver.ProxyService{}, fmt.Errorf("invalid alias %q", endpoint.Alias)
+	}
+	endpointURL, err := url.Parse(endpoint.URL)
+	if err != nil {
+		return consoleserver.ProxyService{}, fmt.Errorf("%s: invalid url: %v", endpoint.Alias, err)
+	}
+	if !hostAllowed(endpointURL.Hostname(), allowedHosts) {
+		return consoleserver.ProxyService{}, fmt.Errorf("%s: host %q is not allowed", endpoint.Alias, endpointURL.Hostname())
+	}
+	caCertificate := ""
+	if len(endpoint.CAConfigMap) != 0 {
+		caBundle, ok := caBundles[endpoint.CAConfigMap]
+		if !ok {
+			return consoleserver.ProxyService{}, fmt.Errorf("%s: CA config map %q not found", endpoint.Alias, endpoint.CAConfigMap)
+		}
+		caCertificate = caBundle
+	}
+	return consoleserver.ProxyService{
+		ConsoleAPIPath: fmt.Sprintf("%s%s/%s/", pluginProxyEndpoint, pluginName, endpoint.Alias),
+		Endpoint:       endpointURL.String(),
+		CACertificate:  caCertificate,
+		Authorize:      getProxyAuthorization(endpoint.Authorization),
+	}, nil
+}
+
+// validateProxyService checks an external proxy entry before it is rendered into console-config.
+func validateProxyService(proxyService consoleserver.ProxyService) error {
+	endpointURL, err := url.Parse(proxyService.Endpoint)
+	if err != nil {
+		return fmt.Errorf("invalid endpoint: %v", err)
+	}
+	if endpointURL.Scheme != "https" || len(endpointURL.Host) == 0 || endpointURL.User != nil {
+		return fmt.Errorf("endpoint %q must be an https URL without credentials", proxyService.Endpoint)
+	}
+	if !strings.HasPrefix(proxyService.ConsoleAPIPath, pluginProxyEndpoint) || !strings.HasSuffix(proxyService.ConsoleAPIPath, "/") {
+		return fmt.Errorf("invalid console API path %q", proxyService.ConsoleAPIPath)
+	}
+	if len(proxyService.CACertificate) != 0 && !isCertificateBundle(proxyService.CACertificate) {
+		return fmt.Errorf("CA certificate for %q is not a PEM encoded certificate bundle", proxyService.Endpoint)
+	}
+	return nil
+}
+
+func isCertificateBundle(caBundle string) bool {
+	rest := []byte(caBundle)
+	found := false
+	for {
+		var block *pem.Block
+		block, rest = pem.Decode(rest)
+		if block == nil {
+			return found
+		}
+		if block.Type != "CERTIFICATE" {
+			return false
+		}
+		if _, err := x509.ParseCertificate(block.Bytes); err != nil {
+			return false
+		}
+		found = true
+	}
+}
+
+func pluginProxyAllowedHosts(operatorConfig *operatorv1.Console) []string {
+	allowedHosts := []string{}
+	for _, host := range strings.Split(operatorConfig.Annotations[PluginProxyAllowedHostsAnnotation], ",") {
+		if host = strings.TrimSpace(host); len(host) != 0 {
+			allowedHosts = append(allowedHosts, strings.ToLower(host))
+		}
+	}
+	return allowedHosts
+}
+
+func hostAllowed(host string, allowedHosts []string) bool {
+	host = strings.ToLower(host)
+	if len(host) == 0 {
+		return false
+	}
+	for _, allowedHost := range allowedHosts {
+		if host == allowedHost {
+			return true
+		}
+		if domain, isWildcard := strings.CutPrefix(allowedHost, "*."); isWildcard && strings.HasSuffix(host, "."+domain) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,120 @@
+package configmap
+
+import (
+	"testing"
+
+	"github.com/go-test/deep"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	consolev1 "github.com/openshift/api/console/v1"
+	operatorv1 "github.com/openshift/api/operator/v1"
+
+	"github.com/openshift/console-operator/pkg/console/subresource/consoleserver"
+)
+
+func TestGetPluginsProxyServicesExternal(t *testing.T) {
+	operatorConfig := &operatorv1.Console{
+		ObjectMeta: metav1.ObjectMeta{
+			Annotations: map[string]string{PluginProxyAllowedHostsAnnotation: "gateway.corp.example.com, *.api.example.com"},
+		},
+	}
+	plugin := &consolev1.ConsolePlugin{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "plugin1",
+			Annotations: map[string]string{PluginExternalProxyAnnotation: `[
+				{"alias": "gateway", "url": "https://gateway.corp.example.com/plugin", "caConfigMap": "corp-ca", "authorization": "UserToken"},
+				{"alias": "search", "url": "https://search.api.example.com"},
+				{"alias": "evil", "url": "https://evil.com"},
+				{"alias": "plain", "url": "http://gateway.corp.example.com"},
+				{"alias": "missing-ca", "url": "https://gateway.corp.example.com", "caConfigMap": "missing"},
+				{"alias": "gateway", "url": "https://gateway.corp.example.com/other"}
+			]`},
+		},
+	}
+	caBundles := map[string]string{"corp-ca": validCertificate}
+
+	proxyServices, violations := getPluginsProxyServices(operatorConfig, []*consolev1.ConsolePlugin{plugin}, caBundles)
+	wantProxyServices := []consoleserver.ProxyService{
+		{
+			ConsoleAPIPath: "/api/proxy/plugin/plugin1/gateway/",
+			Endpoint:       "https://gateway.corp.example.com/plugin",
+			CACertificate:  validCertificate,
+			Authorize:      true,
+		},
+		{
+			ConsoleAPIPath: "/api/proxy/plugin/plugin1/search/",
+			Endpoint:       "https://search.api.example.com",
+		},
+	}
+	if diff := deep.Equal(proxyServices, wantProxyServices); diff != nil {
+		t.Error(diff)
+	}
+	wantViolations := map[string][]string{
+		"plugin1": {
+			`evil: host "evil.com" is not allowed`,
+			`endpoint "http://gateway.corp.example.com" must be an https URL without credentials`,
+			`missing-ca: CA config map "missing" not found`,
+			`duplicate console API path "/api/proxy/plugin/plugin1/gateway/"`,
+		},
+	}
+	if diff := deep.Equal(violations, wantViolations); diff != nil {
+		t.Error(diff)
+	}
+}
+
+func TestGetPluginsProxyServicesWithoutAllowedHosts(t *testing.T) {
+	plugin := &consolev1.ConsolePlugin{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "plugin1",
+			Annotations: map[string]string{PluginExternalProxyAnnotation: `[{"alias": "gateway", "url": "https://gateway.corp.example.com"}]`},
+		},
+	}
+	proxyServices, _ := getPluginsProxyServices(&operatorv1.Console{}, []*consolev1.ConsolePlugin{plugin}, nil)
+	if len(proxyServices) != 0 {
+		t.Errorf("expected no proxy services, got %v", proxyServices)
+	}
+	if err := PluginProxyViolations(&operatorv1.Console{}, []*consolev1.ConsolePlugin{plugin}, nil); err == nil {
+		t.Error("expected the external proxy to be reported")
+	}
+}
+
+func TestGetPluginsProxyServicesKeepsServiceProxies(t *testing.T) {
+	operatorConfig := &operatorv1.Console{
+		ObjectMeta: metav1.ObjectMeta{
+			Annotations: map[string]string{PluginProxyAllowedHostsAnnotation: "gateway.corp.example.com"},
+		},
+	}
+	plugin := &consolev1.ConsolePlugin{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "plugin1",
+			Annotations: map[string]string{PluginExternalProxyAnnotation: `[{"alias": "backend", "url": "https://gateway.corp.example.com"}]`},
+		},
+		Spec: consolev1.ConsolePluginSpec{
+			Proxy: []consolev1.ConsolePluginProxy{{
+				Alias:         "backend",
+				CACertificate: "not validated by the operator",
+				Endpoint: consolev1.ConsolePluginProxyEndpoint{
+					Type:    consolev1.ProxyTypeService,
+					Service: &consolev1.ConsolePluginProxyServiceConfig{Name: "backend", Namespace: "plugin1", Port: 8443},
+				},
+			}},
+		},
+	}
+
+	proxyServices, violations := getPluginsProxyServices(operatorConfig, []*consolev1.ConsolePlugin{plugin}, nil)
+	wantProxyServices := []consoleserver.ProxyService{{
+		ConsoleAPIPath: "/api/proxy/plugin/plugin1/backend/",
+		Endpoint:       "https://backend.plugin1.svc.cluster.local:8443",
+		CACertificate:  "not validated by the operator",
+	}}
+	if diff := deep.Equal(proxyServices, wantProxyServices); diff != nil {
+		t.Error(diff)
+	}
+	wantViolations := map[string][]string{
+		"plugin1": {`duplicate console API path "/api/proxy/plugin/plugin1/backend/"`},
+	}
+	if diff := deep.Equal(violations, wantViolations); diff != nil {
+		t.Error(diff)
+	}
+}
@@ -0,0 +1,50 @@
+package configmap
+
+import (
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+
+	"github.com/openshift/console-operator/pkg/api"
+	"github.com/openshift/console-operator/pkg/console/subresource/util"
+)
+
+// probedPluginsAnnotation lists the probed plugins that passed a probe at least once.
+const probedPluginsAnnotation = "console.openshift.io/probed-plugins"
+
+// DefaultPluginQuarantineConfigMap creates the config map listing the plugins whose
+// backend failed its probes, keyed by plugin name with the last probe error as value.
+// Quarantined plugins are left out of console-config until their backend recovers, and
+// probed plugins until their first successful probe.
+func DefaultPluginQuarantineConfigMap(cr *operatorv1.Console, quarantined map[string]string, probed sets.String) *corev1.ConfigMap {
+	configMap := PluginQuarantineStub()
+	configMap.Data = map[string]string{}
+	for pluginName, reason := range quarantined {
+		configMap.Data[pluginName] = reason
+	}
+	configMap.Annotations = map[string]string{probedPluginsAnnotation: strings.Join(probed.List(), ",")}
+	util.AddOwnerRef(configMap, util.OwnerRefFrom(cr))
+	return configMap
+}
+
+// GetProbedPlugins returns the probed plugins that passed a probe at least once.
+func GetProbedPlugins(quarantineConfigMap *corev1.ConfigMap) sets.String {
+	probed := sets.NewString()
+	for _, pluginName := range strings.Split(quarantineConfigMap.Annotations[probedPluginsAnnotation], ",") {
+		if len(pluginName) > 0 {
+			probed.Insert(pluginName)
+		}
+	}
+	return probed
+}
+
+func PluginQuarantineStub() *corev1.ConfigMap {
+	meta := util.SharedMeta()
+	meta.Name = api.ConsolePluginQuarantineName
+	return &corev1.ConfigMap{
+		ObjectMeta: meta,
+	}
+}
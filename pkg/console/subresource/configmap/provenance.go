@@ -0,0 +1,38 @@
+package configmap
+
+import (
+	"github.com/ghodss/yaml"
+	corev1 "k8s.io/api/core/v1"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+
+	"github.com/openshift/console-operator/pkg/api"
+	"github.com/openshift/console-operator/pkg/console/subresource/util"
+)
+
+const consoleConfigProvenanceYamlFile = "provenance.yaml"
+
+// DefaultConfigProvenanceConfigMap creates a companion config map to console-config
+// that lists, for each leaf path of the merged console-config, the layer it came from:
+// default, managed, userDefined or unsupportedConfigOverrides.
+// It is kept out of console-config itself so that it does not trigger console rollouts.
+func DefaultConfigProvenanceConfigMap(cr *operatorv1.Console, provenance map[string]string) (*corev1.ConfigMap, error) {
+	provenanceYAML, err := yaml.Marshal(provenance)
+	if err != nil {
+		return nil, err
+	}
+	configMap := ConfigProvenanceStub()
+	configMap.Data = map[string]string{
+		consoleConfigProvenanceYamlFile: string(provenanceYAML),
+	}
+	util.AddOwnerRef(configMap, util.OwnerRefFrom(cr))
+	return configMap, nil
+}
+
+func ConfigProvenanceStub() *corev1.ConfigMap {
+	meta := util.SharedMeta()
+	meta.Name = api.ConsoleConfigProvenanceName
+	return &corev1.ConfigMap{
+		ObjectMeta: meta,
+	}
+}
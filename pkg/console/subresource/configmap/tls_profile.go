@@ -0,0 +1,69 @@
+package configmap
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"k8s.io/klog/v2"
+
+	configv1 "github.com/openshift/api/config/v1"
+	operatorv1 "github.com/openshift/api/operator/v1"
+	"github.com/openshift/library-go/pkg/crypto"
+)
+
+// TLSSecurityProfileAnnotation is set on the operator config to a JSON TLSSecurityProfile
+// that the console serves with instead of the cluster APIServer tlsSecurityProfile.
+const TLSSecurityProfileAnnotation = "console.openshift.io/tls-security-profile"
+
+// GetTLSSecurityProfile returns the TLS profile the console serves with: the console
+// override when set, the cluster APIServer profile otherwise. An unset APIServer
+// profile means the Intermediate profile. A nil APIServer config leaves the console
+// on its own defaults. An invalid override is returned as an error along with the
+// cluster profile the console falls back to.
+func GetTLSSecurityProfile(operatorConfig *operatorv1.Console, apiServerConfig *configv1.APIServer) (*configv1.TLSProfileSpec, error) {
+	var overrideErr error
+	if value, ok := operatorConfig.Annotations[TLSSecurityProfileAnnotation]; ok {
+		profile := &configv1.TLSSecurityProfile{}
+		if err := json.Unmarshal([]byte(value), profile); err != nil {
+			overrideErr = fmt.Errorf("invalid %s annotation: %w", TLSSecurityProfileAnnotation, err)
+		} else if spec, err := tlsProfileSpec(profile); err != nil {
+			overrideErr = fmt.Errorf("invalid %s annotation: %w", TLSSecurityProfileAnnotation, err)
+		} else {
+			return spec, nil
+		}
+	}
+	if apiServerConfig == nil {
+		return nil, overrideErr
+	}
+	spec, err := tlsProfileSpec(apiServerConfig.Spec.TLSSecurityProfile)
+	if err != nil {
+		klog.Errorf("using the console default TLS settings: %v", err)
+	}
+	return spec, overrideErr
+}
+
+func tlsProfileSpec(profile *configv1.TLSSecurityProfile) (*configv1.TLSProfileSpec, error) {
+	if profile == nil || len(profile.Type) == 0 {
+		return configv1.TLSProfiles[configv1.TLSProfileIntermediateType], nil
+	}
+	if profile.Type == configv1.TLSProfileCustomType {
+		if profile.Custom == nil {
+			return nil, fmt.Errorf("custom TLS profile is missing its settings")
+		}
+		return &profile.Custom.TLSProfileSpec, nil
+	}
+	spec, ok := configv1.TLSProfiles[profile.Type]
+	if !ok {
+		return nil, fmt.Errorf("unknown TLS profile type %q", profile.Type)
+	}
+	return spec, nil
+}
+
+// servingTLSSettings returns the minimum TLS version and the IANA cipher suites of the
+// console TLS profile, a nil profile leaves the console on its own defaults.
+func servingTLSSettings(spec *configv1.TLSProfileSpec) (string, []string) {
+	if spec == nil {
+		return "", nil
+	}
+	return string(spec.MinTLSVersion), crypto.OpenSSLToIANACipherSuites(spec.Ciphers)
+}
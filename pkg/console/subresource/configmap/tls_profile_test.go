@@ -0,0 +1,67 @@
+package configmap
+
+import (
+	"testing"
+
+	"github.com/go-test/deep"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	configv1 "github.com/openshift/api/config/v1"
+	operatorv1 "github.com/openshift/api/operator/v1"
+)
+
+func TestGetTLSSecurityProfile(t *testing.T) {
+	oldProfile := &configv1.APIServer{
+		Spec: configv1.APIServerSpec{
+			TLSSecurityProfile: &configv1.TLSSecurityProfile{Type: configv1.TLSProfileOldType},
+		},
+	}
+	tests := []struct {
+		name              string
+		annotations       map[string]string
+		apiServerConfig   *configv1.APIServer
+		wantMinTLSVersion string
+		wantErr           bool
+	}{
+		{
+			name: "No APIServer config",
+		},
+		{
+			name:              "Unset cluster profile is Intermediate",
+			apiServerConfig:   &configv1.APIServer{},
+			wantMinTLSVersion: string(configv1.VersionTLS12),
+		},
+		{
+			name:              "Cluster profile",
+			apiServerConfig:   oldProfile,
+			wantMinTLSVersion: string(configv1.VersionTLS10),
+		},
+		{
+			name:              "Console override",
+			annotations:       map[string]string{TLSSecurityProfileAnnotation: `{"type":"Modern","modern":{}}`},
+			apiServerConfig:   oldProfile,
+			wantMinTLSVersion: string(configv1.VersionTLS13),
+		},
+		{
+			name:              "Invalid console override falls back to the cluster profile",
+			annotations:       map[string]string{TLSSecurityProfileAnnotation: `{"type":"Custom"}`},
+			apiServerConfig:   oldProfile,
+			wantMinTLSVersion: string(configv1.VersionTLS10),
+			wantErr:           true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			operatorConfig := &operatorv1.Console{ObjectMeta: metav1.ObjectMeta{Annotations: tt.annotations}}
+			spec, err := GetTLSSecurityProfile(operatorConfig, tt.apiServerConfig)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("GetTLSSecurityProfile() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			minTLSVersion, _ := servingTLSSettings(spec)
+			if diff := deep.Equal(minTLSVersion, tt.wantMinTLSVersion); diff != nil {
+				t.Error(diff)
+			}
+		})
+	}
+}
@@ -59,6 +59,8 @@ type ConsoleServerCLIConfigBuilder struct {
 	CAFile                     string
 	monitoring                 map[string]string
 	customHostnameRedirectPort int
+	minTLSVersion              string
+	cipherSuites               []string
 	inactivityTimeoutSeconds   int
 	pluginsList                map[string]string
 	i18nNamespaceList          []string
@@ -75,6 +77,7 @@ type ConsoleServerCLIConfigBuilder struct {
 	authType                   string
 	sessionEncryptionFile      string
 	sessionAuthenticationFile  string
+	acceptedSessionKeys        []CookieKeyFiles
 	capabilities               []operatorv1.Capability
 	contentSecurityPolicyList  map[v1.DirectiveType][]string
 }
@@ -83,6 +86,13 @@ func (b *ConsoleServerCLIConfigBuilder) Host(host string) *ConsoleServerCLIConfi
 	b.host = host
 	return b
 }
+
+// TLSSecurityProfile sets the minimum TLS version and the IANA cipher suites the console serves with.
+func (b *ConsoleServerCLIConfigBuilder) TLSSecurityProfile(minTLSVersion string, cipherSuites []string) *ConsoleServerCLIConfigBuilder {
+	b.minTLSVersion = minTLSVersion
+	b.cipherSuites = cipherSuites
+	return b
+}
 func (b *ConsoleServerCLIConfigBuilder) LogoutURL(logoutRedirectURL string) *ConsoleServerCLIConfigBuilder {
 	b.logoutRedirectURL = logoutRedirectURL
 	return b
@@ -178,8 +188,8 @@ func (b *ConsoleServerCLIConfigBuilder) AuthConfig(authnConfig *configv1.Authent
 		b.oauthClientID = oidcConfig.ClientID
 		b.oidcExtraScopes = oidcConfig.ExtraScopes
 		b.oidcOCLoginCommand = authconfigsub.GetOIDCOCLoginCommand(authnConfig, apiServerURL)
-		b.sessionAuthenticationFile = "/var/session-secret/sessionAuthenticationKey"
-		b.sessionEncryptionFile = "/var/session-secret/sessionEncryptionKey"
+		b.sessionAuthenticationFile = path.Join(api.SessionSecretMountDir, api.SessionAuthenticationKey)
+		b.sessionEncryptionFile = path.Join(api.SessionSecretMountDir, api.SessionEncryptionKey)
 
 		if len(oidcProvider.Issuer.CertificateAuthority.Name) > 0 {
 			b.CAFile = path.Join(api.AuthServerCAMountDir, api.AuthServerCAFileName)
@@ -189,6 +199,23 @@ func (b *ConsoleServerCLIConfigBuilder) AuthConfig(authnConfig *configv1.Authent
 	return b
 }
 
+// AcceptedSessionKeys lists the previous session keys of the session secret, while a
+// rotation overlaps, for the sessions they wrote to stay valid.
+func (b *ConsoleServerCLIConfigBuilder) AcceptedSessionKeys(sessionSecret *corev1.Secret) *ConsoleServerCLIConfigBuilder {
+	if sessionSecret == nil {
+		return b
+	}
+	_, hasEncryptionKey := sessionSecret.Data[api.PreviousSessionEncryptionKey]
+	_, hasAuthenticationKey := sessionSecret.Data[api.PreviousSessionAuthenticationKey]
+	if hasEncryptionKey && hasAuthenticationKey {
+		b.acceptedSessionKeys = []CookieKeyFiles{{
+			CookieEncryptionKeyFile:     path.Join(api.SessionSecretMountDir, api.PreviousSessionEncryptionKey),
+			CookieAuthenticationKeyFile: path.Join(api.SessionSecretMountDir, api.PreviousSessionAuthenticationKey),
+		}}
+	}
+	return b
+}
+
 func (b *ConsoleServerCLIConfigBuilder) Monitoring(monitoringConfig *corev1.ConfigMap) *ConsoleServerCLIConfigBuilder {
 	if monitoringConfig != nil {
 		b.monitoring = monitoringConfig.Data
@@ -286,6 +313,13 @@ func (b *ConsoleServerCLIConfigBuilder) servingInfo() ServingInfo {
 		conf.RedirectPort = b.customHostnameRedirectPort
 	}
 
+	if len(b.minTLSVersion) != 0 {
+		conf.MinTLSVersion = b.minTLSVersion
+	}
+	if len(b.cipherSuites) != 0 {
+		conf.CipherSuites = b.cipherSuites
+	}
+
 	return conf
 }
 
@@ -370,6 +404,7 @@ func (b *ConsoleServerCLIConfigBuilder) session() Session {
 	conf := Session{
 		CookieAuthenticationKeyFile: b.sessionAuthenticationFile,
 		CookieEncryptionKeyFile:     b.sessionEncryptionFile,
+		AcceptedCookieKeys:          b.acceptedSessionKeys,
 	}
 	return conf
 }
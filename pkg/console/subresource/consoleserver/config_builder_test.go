@@ -822,6 +822,45 @@ func TestConsoleServerCLIConfigBuilder(t *testing.T) {
 				},
 			},
 		},
+		{
+			name: "Config builder should accept the previous session keys during a rotation",
+			input: func() Config {
+				b := &ConsoleServerCLIConfigBuilder{}
+				b.AcceptedSessionKeys(&corev1.Secret{
+					Data: map[string][]byte{
+						api.SessionEncryptionKey:             []byte("current"),
+						api.SessionAuthenticationKey:         []byte("current"),
+						api.PreviousSessionEncryptionKey:     []byte("previous"),
+						api.PreviousSessionAuthenticationKey: []byte("previous"),
+					},
+				})
+				return b.Config()
+			},
+			output: Config{
+				Kind:       "ConsoleConfig",
+				APIVersion: "console.openshift.io/v1",
+				ServingInfo: ServingInfo{
+					BindAddress: "https://[::]:8443",
+					CertFile:    certFilePath,
+					KeyFile:     keyFilePath,
+				},
+				ClusterInfo: ClusterInfo{
+					ConsoleBasePath: "",
+				},
+				Auth: Auth{
+					ClientID:         api.OpenShiftConsoleName,
+					ClientSecretFile: clientSecretFilePath,
+				},
+				Session: Session{
+					AcceptedCookieKeys: []CookieKeyFiles{{
+						CookieEncryptionKeyFile:     "/var/session-secret/previousSessionEncryptionKey",
+						CookieAuthenticationKeyFile: "/var/session-secret/previousSessionAuthenticationKey",
+					}},
+				},
+				Customization: Customization{},
+				Providers:     Providers{},
+			},
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
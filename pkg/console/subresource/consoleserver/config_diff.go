@@ -0,0 +1,64 @@
+package consoleserver
+
+import (
+	"encoding/json"
+	"reflect"
+	"sort"
+
+	yaml2 "github.com/ghodss/yaml"
+)
+
+// ChangedPaths returns the sorted leaf paths whose value differs between two
+// console-config documents, including paths present in only one of them.
+func ChangedPaths(previousYAML, currentYAML []byte) ([]string, error) {
+	previous, err := flattenConfig(previousYAML)
+	if err != nil {
+		return nil, err
+	}
+	current, err := flattenConfig(currentYAML)
+	if err != nil {
+		return nil, err
+	}
+
+	changed := []string{}
+	for path, value := range current {
+		if previousValue, ok := previous[path]; !ok || !reflect.DeepEqual(previousValue, value) {
+			changed = append(changed, path)
+		}
+	}
+	for path := range previous {
+		if _, ok := current[path]; !ok {
+			changed = append(changed, path)
+		}
+	}
+	sort.Strings(changed)
+	return changed, nil
+}
+
+func flattenConfig(configYAML []byte) (map[string]interface{}, error) {
+	flattened := map[string]interface{}{}
+	if len(configYAML) == 0 {
+		return flattened, nil
+	}
+	configJSON, err := yaml2.YAMLToJSON(configYAML)
+	if err != nil {
+		return nil, err
+	}
+	config := map[string]interface{}{}
+	if err := json.Unmarshal(configJSON, &config); err != nil {
+		return nil, err
+	}
+	flattenValues(config, "", flattened)
+	return flattened, nil
+}
+
+func flattenValues(config map[string]interface{}, prefix string, flattened map[string]interface{}) {
+	for key, value := range config {
+		path := joinConfigPath(prefix, key)
+		if nested, ok := value.(map[string]interface{}); ok && len(nested) != 0 {
+			flattenValues(nested, path, flattened)
+			continue
+		}
+		flattened[path] = value
+	}
+}
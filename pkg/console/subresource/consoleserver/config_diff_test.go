@@ -0,0 +1,40 @@
+package consoleserver
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestChangedPaths(t *testing.T) {
+	tests := []struct {
+		name     string
+		previous string
+		current  string
+		output   []string
+	}{
+		{
+			name:     "Identical configs have no changed paths",
+			previous: "customization:\n  branding: okd\n",
+			current:  "customization:\n  branding: okd\n",
+			output:   []string{},
+		},
+		{
+			name:     "Changed, added and removed leaves are reported",
+			previous: "customization:\n  branding: okd\nproviders:\n  statuspageID: status-12345\n",
+			current:  "customization:\n  branding: ocp\nplugins:\n  my.plugin: https://my-plugin.svc:9443/\n",
+			output:   []string{"customization.branding", `plugins["my.plugin"]`, "providers.statuspageID"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			changed, err := ChangedPaths([]byte(tt.previous), []byte(tt.current))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if diff := cmp.Diff(tt.output, changed); len(diff) > 0 {
+				t.Error(diff)
+			}
+		})
+	}
+}
@@ -1,10 +1,29 @@
 package consoleserver
 
 import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+
 	yaml2 "github.com/ghodss/yaml"
 	"github.com/openshift/library-go/pkg/operator/resource/resourcemerge"
 )
 
+// Names of the layers merged into console-config, in merge order.
+const (
+	ConfigLayerDefault                    = "default"
+	ConfigLayerManaged                    = "managed"
+	ConfigLayerUserDefined                = "userDefined"
+	ConfigLayerUnsupportedConfigOverrides = "unsupportedConfigOverrides"
+)
+
+// ConfigLayer is a single named input of a console-config merge.
+type ConfigLayer struct {
+	Name   string
+	Config []byte
+}
+
 type ConsoleYAMLMerger struct{}
 
 func (b *ConsoleYAMLMerger) Merge(configYAMLs ...[]byte) (converted []byte, conversionErr error) {
@@ -15,6 +34,40 @@ func (b *ConsoleYAMLMerger) Merge(configYAMLs ...[]byte) (converted []byte, conv
 	return yaml2.JSONToYAML(mergedConfig)
 }
 
+// MergeWithProvenance merges the layers the same way Merge does and also returns,
+// for every leaf path of the merged config, the name of the first layer that set it
+// to its merged value. A layer repeating the value of an earlier one does not own it.
+func (b *ConsoleYAMLMerger) MergeWithProvenance(layers ...ConfigLayer) (converted []byte, provenance map[string]string, conversionErr error) {
+	configYAMLs := make([][]byte, 0, len(layers))
+	for _, layer := range layers {
+		configYAMLs = append(configYAMLs, layer.Config)
+	}
+	converted, err := b.Merge(configYAMLs...)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	root := map[string]*provenanceNode{}
+	for _, layer := range layers {
+		if len(layer.Config) == 0 {
+			continue
+		}
+		layerJSON, err := yaml2.YAMLToJSON(layer.Config)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to read %s config layer: %w", layer.Name, err)
+		}
+		layerConfig := map[string]interface{}{}
+		if err := json.Unmarshal(layerJSON, &layerConfig); err != nil {
+			return nil, nil, fmt.Errorf("failed to read %s config layer: %w", layer.Name, err)
+		}
+		mergeProvenance(root, layerConfig, layer.Name)
+	}
+
+	provenance = map[string]string{}
+	flattenProvenance(root, "", provenance)
+	return converted, provenance, nil
+}
+
 func (b *ConsoleYAMLMerger) combine(configYAMLs ...[]byte) (mergedConfig []byte, mergeError error) {
 	mergedConfig, err := resourcemerge.MergeProcessConfig(nil, configYAMLs...)
 	if err != nil {
@@ -22,3 +75,56 @@ func (b *ConsoleYAMLMerger) combine(configYAMLs ...[]byte) (mergedConfig []byte,
 	}
 	return mergedConfig, nil
 }
+
+// provenanceNode mirrors a node of the merged config. Scalars and lists have no
+// children and keep their value, maps keep the layer that created them so empty maps
+// are still reported.
+type provenanceNode struct {
+	layer    string
+	value    interface{}
+	children map[string]*provenanceNode
+}
+
+// mergeProvenance follows the rules of resourcemerge.MergeProcessConfig: maps are
+// merged key by key, any other value replaces whatever was there before.
+func mergeProvenance(current map[string]*provenanceNode, additional map[string]interface{}, layer string) {
+	for key, value := range additional {
+		additionalMap, isMap := value.(map[string]interface{})
+		if !isMap {
+			if node, ok := current[key]; ok && node.children == nil && reflect.DeepEqual(node.value, value) {
+				continue
+			}
+			current[key] = &provenanceNode{layer: layer, value: value}
+			continue
+		}
+		node, ok := current[key]
+		if !ok || node.children == nil {
+			node = &provenanceNode{layer: layer, children: map[string]*provenanceNode{}}
+			current[key] = node
+		}
+		mergeProvenance(node.children, additionalMap, layer)
+	}
+}
+
+func flattenProvenance(nodes map[string]*provenanceNode, prefix string, provenance map[string]string) {
+	for key, node := range nodes {
+		path := joinConfigPath(prefix, key)
+		if len(node.children) == 0 {
+			provenance[path] = node.layer
+			continue
+		}
+		flattenProvenance(node.children, path, provenance)
+	}
+}
+
+// joinConfigPath joins path segments with dots, keys that contain a dot
+// themselves (e.g. plugin names) are quoted in brackets.
+func joinConfigPath(prefix, key string) string {
+	if strings.Contains(key, ".") {
+		return fmt.Sprintf("%s[%q]", prefix, key)
+	}
+	if len(prefix) == 0 {
+		return key
+	}
+	return prefix + "." + key
+}
@@ -71,3 +71,133 @@ session: {}
 		})
 	}
 }
+
+func TestConfigMergerProvenance(t *testing.T) {
+	tests := []struct {
+		name   string
+		layers []ConfigLayer
+		output map[string]string
+	}{
+		{
+			name: "Provenance should record the last layer that set each leaf",
+			layers: []ConfigLayer{
+				{
+					Name: ConfigLayerDefault,
+					Config: []byte(`apiVersion: console.openshift.io/v1
+kind: ConsoleConfig
+customization:
+  branding: okd
+  documentationBaseURL: https://docs.okd.io/
+session: {}
+`),
+				},
+				{
+					Name: ConfigLayerManaged,
+					Config: []byte(`customization:
+  documentationBaseURL: https://managed.io/docs
+`),
+				},
+				{
+					Name: ConfigLayerUserDefined,
+					Config: []byte(`customization:
+  branding: ocp
+plugins:
+  my.plugin: https://my-plugin.svc:9443/
+`),
+				},
+				{
+					Name: ConfigLayerUnsupportedConfigOverrides,
+					Config: []byte(`session:
+  cookieEncryptionKeyFile: /var/session-secret/key
+`),
+				},
+			},
+			output: map[string]string{
+				"apiVersion":                         ConfigLayerDefault,
+				"kind":                               ConfigLayerDefault,
+				"customization.branding":             ConfigLayerUserDefined,
+				"customization.documentationBaseURL": ConfigLayerManaged,
+				`plugins["my.plugin"]`:               ConfigLayerUserDefined,
+				"session.cookieEncryptionKeyFile":    ConfigLayerUnsupportedConfigOverrides,
+			},
+		},
+		{
+			name: "Provenance should keep the layer that first set a value repeated by a later one",
+			layers: []ConfigLayer{
+				{
+					Name: ConfigLayerDefault,
+					Config: []byte(`apiVersion: console.openshift.io/v1
+auth:
+  authType: oidc
+  oidcExtraScopes:
+  - email
+`),
+				},
+				{
+					Name: ConfigLayerUserDefined,
+					Config: []byte(`apiVersion: console.openshift.io/v1
+auth:
+  authType: oidc
+  logoutRedirect: https://sso.example.com/logout
+  oidcExtraScopes:
+  - email
+  - groups
+`),
+				},
+			},
+			output: map[string]string{
+				"apiVersion":           ConfigLayerDefault,
+				"auth.authType":        ConfigLayerDefault,
+				"auth.logoutRedirect":  ConfigLayerUserDefined,
+				"auth.oidcExtraScopes": ConfigLayerUserDefined,
+			},
+		},
+		{
+			name: "Provenance should drop the children of a map replaced by a scalar",
+			layers: []ConfigLayer{
+				{
+					Name: ConfigLayerDefault,
+					Config: []byte(`providers:
+  statuspageID: status-12345
+`),
+				},
+				{
+					Name:   ConfigLayerManaged,
+					Config: []byte{},
+				},
+				{
+					Name:   ConfigLayerUnsupportedConfigOverrides,
+					Config: []byte(`providers: foo`),
+				},
+			},
+			output: map[string]string{
+				"providers": ConfigLayerUnsupportedConfigOverrides,
+			},
+		},
+		{
+			name: "Provenance should report empty maps",
+			layers: []ConfigLayer{
+				{
+					Name:   ConfigLayerDefault,
+					Config: []byte(`providers: {}`),
+				},
+			},
+			output: map[string]string{
+				"providers": ConfigLayerDefault,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			merger := ConsoleYAMLMerger{}
+			_, provenance, err := merger.MergeWithProvenance(tt.layers...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if diff := cmp.Diff(tt.output, provenance); len(diff) > 0 {
+				t.Error(diff)
+			}
+		})
+	}
+}
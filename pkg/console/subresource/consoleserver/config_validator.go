@@ -0,0 +1,193 @@
+package consoleserver
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"sort"
+	"strings"
+
+	yaml2 "github.com/ghodss/yaml"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+// ValidateConfig strictly checks a console-config document against Config.
+// Every key must map to a known field and every value must match the type of
+// that field, the returned errors carry the path of each offending value.
+func ValidateConfig(configYAML []byte) field.ErrorList {
+	return validateConfig(configYAML, func(string) bool { return true })
+}
+
+// ValidateConfigLayer checks a merged console-config document like ValidateConfig, but
+// only returns the errors on the values the given layer set, according to the provenance
+// returned by MergeWithProvenance.
+func ValidateConfigLayer(configYAML []byte, provenance map[string]string, layer string) field.ErrorList {
+	return validateConfig(configYAML, func(configPath string) bool {
+		return isOwnedBy(provenance, configPath, layer)
+	})
+}
+
+// configError is a validation error along with the path of the offending value in the
+// format of the merge provenance. The path of a value in a list goes through the list
+// without its index, lists being leaves of the provenance.
+type configError struct {
+	configPath string
+	err        *field.Error
+}
+
+func validateConfig(configYAML []byte, include func(configPath string) bool) field.ErrorList {
+	var config interface{}
+	if err := yaml2.Unmarshal(configYAML, &config); err != nil {
+		return field.ErrorList{field.Invalid(field.NewPath(""), "", err.Error())}
+	}
+	errs := field.ErrorList{}
+	for _, configErr := range validateValue(nil, "", config, reflect.TypeOf(Config{})) {
+		if include(configErr.configPath) {
+			errs = append(errs, configErr.err)
+		}
+	}
+	return errs
+}
+
+// isOwnedBy returns true when the layer set the value at the path, any value under it, or
+// the list it is in.
+func isOwnedBy(provenance map[string]string, configPath string, layer string) bool {
+	for path, pathLayer := range provenance {
+		if pathLayer == layer && (path == configPath || isUnder(path, configPath) || isUnder(configPath, path)) {
+			return true
+		}
+	}
+	return false
+}
+
+func isUnder(path string, parentPath string) bool {
+	return strings.HasPrefix(path, parentPath+".") || strings.HasPrefix(path, parentPath+"[")
+}
+
+func validateValue(path *field.Path, configPath string, value interface{}, t reflect.Type) []configError {
+	// null is accepted everywhere and decodes to the zero value
+	if value == nil {
+		return nil
+	}
+
+	switch t.Kind() {
+	case reflect.Ptr:
+		return validateValue(path, configPath, value, t.Elem())
+	case reflect.Interface:
+		return nil
+	case reflect.String:
+		if _, ok := value.(string); !ok {
+			return typeMismatch(path, configPath, value, "string")
+		}
+	case reflect.Bool:
+		if _, ok := value.(bool); !ok {
+			return typeMismatch(path, configPath, value, "boolean")
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if number, ok := value.(float64); !ok || number != math.Trunc(number) {
+			return typeMismatch(path, configPath, value, "integer")
+		}
+	case reflect.Float32, reflect.Float64:
+		if _, ok := value.(float64); !ok {
+			return typeMismatch(path, configPath, value, "number")
+		}
+	case reflect.Slice, reflect.Array:
+		items, ok := value.([]interface{})
+		if !ok {
+			return typeMismatch(path, configPath, value, "list")
+		}
+		errs := []configError{}
+		for i, item := range items {
+			errs = append(errs, validateValue(path.Index(i), configPath, item, t.Elem())...)
+		}
+		return errs
+	case reflect.Map:
+		entries, ok := value.(map[string]interface{})
+		if !ok {
+			return typeMismatch(path, configPath, value, "map")
+		}
+		errs := []configError{}
+		for _, key := range sortedKeys(entries) {
+			errs = append(errs, validateValue(path.Key(key), joinConfigPath(configPath, key), entries[key], t.Elem())...)
+		}
+		return errs
+	case reflect.Struct:
+		entries, ok := value.(map[string]interface{})
+		if !ok {
+			return typeMismatch(path, configPath, value, "object")
+		}
+		fields := yamlFields(t)
+		errs := []configError{}
+		for _, key := range sortedKeys(entries) {
+			fieldType, known := fields[key]
+			if !known {
+				errs = append(errs, configError{
+					configPath: joinConfigPath(configPath, key),
+					err:        field.NotSupported(childPath(path, key), key, sortedFieldNames(fields)),
+				})
+				continue
+			}
+			errs = append(errs, validateValue(childPath(path, key), joinConfigPath(configPath, key), entries[key], fieldType)...)
+		}
+		return errs
+	}
+	return nil
+}
+
+// yamlFields returns the keys gopkg.in/yaml.v2 decodes into the struct type t:
+// the name from the yaml tag, or the lowercased field name when there is none.
+func yamlFields(t reflect.Type) map[string]reflect.Type {
+	fields := map[string]reflect.Type{}
+	for i := 0; i < t.NumField(); i++ {
+		structField := t.Field(i)
+		if len(structField.PkgPath) != 0 && !structField.Anonymous {
+			continue
+		}
+		tag := structField.Tag.Get("yaml")
+		if tag == "-" {
+			continue
+		}
+		name, options, _ := strings.Cut(tag, ",")
+		if strings.Contains(options, "inline") {
+			for inlineName, inlineType := range yamlFields(structField.Type) {
+				fields[inlineName] = inlineType
+			}
+			continue
+		}
+		if len(name) == 0 {
+			name = strings.ToLower(structField.Name)
+		}
+		fields[name] = structField.Type
+	}
+	return fields
+}
+
+func typeMismatch(path *field.Path, configPath string, value interface{}, expected string) []configError {
+	return []configError{{configPath: configPath, err: field.Invalid(path, value, fmt.Sprintf("expected %s", expected))}}
+}
+
+func childPath(path *field.Path, name string) *field.Path {
+	if path == nil {
+		return field.NewPath(name)
+	}
+	return path.Child(name)
+}
+
+func sortedKeys(entries map[string]interface{}) []string {
+	keys := make([]string, 0, len(entries))
+	for key := range entries {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func sortedFieldNames(fields map[string]reflect.Type) []string {
+	names := make([]string, 0, len(fields))
+	for name := range fields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
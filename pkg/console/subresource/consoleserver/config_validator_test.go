@@ -0,0 +1,168 @@
+package consoleserver
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	v1 "github.com/openshift/api/operator/v1"
+)
+
+func TestValidateConfig(t *testing.T) {
+	tests := []struct {
+		name   string
+		input  func() []byte
+		output []string
+	}{
+		{
+			name: "Config generated by the builder should be valid",
+			input: func() []byte {
+				b := &ConsoleServerCLIConfigBuilder{}
+				conf, _ := b.
+					Host("https://console-openshift-console.apps.foobar.com").
+					Brand(v1.BrandOKD).
+					StatusPageID("status-12345").
+					Plugins(map[string]string{"plugin.a": "https://plugin-a.svc:8443/"}).
+					InactivityTimeout(300).
+					ConfigYAML()
+				return conf
+			},
+			output: []string{},
+		},
+		{
+			name: "Unknown fields should be reported with their path",
+			input: func() []byte {
+				return []byte(`apiVersion: console.openshift.io/v1
+kind: ConsoleConfig
+session:
+  cookieEncryptionKeyFiel: /var/session-secret/key
+customization:
+  perspectives:
+  - id: dev
+    visibility:
+      state: Disabled
+      acessReview: {}
+`)
+			},
+			output: []string{
+				`customization.perspectives[0].visibility.acessReview: Unsupported value: "acessReview": supported values: "accessReview", "state"`,
+				`session.cookieEncryptionKeyFiel: Unsupported value: "cookieEncryptionKeyFiel": supported values: "acceptedCookieKeys", "cookieAuthenticationKeyFile", "cookieEncryptionKeyFile"`,
+			},
+		},
+		{
+			name: "Type mismatches should be reported with their path",
+			input: func() []byte {
+				return []byte(`auth:
+  inactivityTimeoutSeconds: "300"
+clusterInfo:
+  copiedCSVsDisabled: 1
+plugins:
+  plugin-a: 8443
+servingInfo:
+  cipherSuites: TLS_AES_128_GCM_SHA256
+`)
+			},
+			output: []string{
+				`auth.inactivityTimeoutSeconds: Invalid value: "300": expected integer`,
+				`clusterInfo.copiedCSVsDisabled: Invalid value: 1: expected boolean`,
+				`plugins[plugin-a]: Invalid value: 8443: expected string`,
+				`servingInfo.cipherSuites: Invalid value: "TLS_AES_128_GCM_SHA256": expected list`,
+			},
+		},
+		{
+			name: "Null values should be accepted",
+			input: func() []byte {
+				return []byte(`providers: null
+customization:
+  developerCatalog:
+    categories: null
+`)
+			},
+			output: []string{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			errs := []string{}
+			for _, err := range ValidateConfig(tt.input()) {
+				errs = append(errs, err.Error())
+			}
+			if diff := cmp.Diff(tt.output, errs); len(diff) > 0 {
+				t.Error(diff)
+			}
+		})
+	}
+}
+
+func TestValidateConfigLayer(t *testing.T) {
+	managedConfig := []byte(`customization:
+  newManagedKey: true
+plugins:
+  plugin-a: https://plugin-a.svc:8443/
+`)
+	tests := []struct {
+		name      string
+		overrides string
+		output    []string
+	}{
+		{
+			name: "Errors of the other layers should not be reported",
+			overrides: `customization:
+  branding: okd
+`,
+			output: []string{},
+		},
+		{
+			name: "Errors on the values of the overrides should be reported",
+			overrides: `auth:
+  inactivityTimeoutSeconds: "300"
+session:
+  cookieEncryptionKeyFiel: /var/session-secret/key
+`,
+			output: []string{
+				`auth.inactivityTimeoutSeconds: Invalid value: "300": expected integer`,
+				`session.cookieEncryptionKeyFiel: Unsupported value: "cookieEncryptionKeyFiel": supported values: "acceptedCookieKeys", "cookieAuthenticationKeyFile", "cookieEncryptionKeyFile"`,
+			},
+		},
+		{
+			name: "Errors under a map the overrides set should be reported",
+			overrides: `providers:
+  unknown:
+    enabled: true
+`,
+			output: []string{
+				`providers.unknown: Unsupported value: "unknown": supported values: "statuspageID"`,
+			},
+		},
+		{
+			name: "Errors in a list the overrides replaced should be reported",
+			overrides: `customization:
+  perspectives:
+  - id: dev
+    visbility: {}
+`,
+			output: []string{
+				`customization.perspectives[0].visbility: Unsupported value: "visbility": supported values: "id", "pinnedResources", "visibility"`,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			merged, provenance, err := (&ConsoleYAMLMerger{}).MergeWithProvenance(
+				ConfigLayer{Name: ConfigLayerManaged, Config: managedConfig},
+				ConfigLayer{Name: ConfigLayerUnsupportedConfigOverrides, Config: []byte(tt.overrides)},
+			)
+			if err != nil {
+				t.Fatal(err)
+			}
+			errs := []string{}
+			for _, err := range ValidateConfigLayer(merged, provenance, ConfigLayerUnsupportedConfigOverrides) {
+				errs = append(errs, err.Error())
+			}
+			if diff := cmp.Diff(tt.output, errs); len(diff) > 0 {
+				t.Error(diff)
+			}
+		})
+	}
+}
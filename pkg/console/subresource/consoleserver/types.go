@@ -51,14 +51,15 @@ type ServingInfo struct {
 	CertFile     string `yaml:"certFile,omitempty"`
 	KeyFile      string `yaml:"keyFile,omitempty"`
 	RedirectPort int    `yaml:"redirectPort,omitempty"`
+	// MinTLSVersion and CipherSuites follow the cluster TLS security profile.
+	MinTLSVersion string   `yaml:"minTLSVersion,omitempty"`
+	CipherSuites  []string `yaml:"cipherSuites,omitempty"`
 
 	// These fields are defined in `HTTPServingInfo`, but are not supported for console. Fail if any are specified.
 	// https://github.com/openshift/api/blob/0cb4131a7636e1ada6b2769edc9118f0fe6844c8/config/v1/types.go#L7-L38
 	BindNetwork           string        `yaml:"bindNetwork,omitempty"`
 	ClientCA              string        `yaml:"clientCA,omitempty"`
 	NamedCertificates     []interface{} `yaml:"namedCertificates,omitempty"`
-	MinTLSVersion         string        `yaml:"minTLSVersion,omitempty"`
-	CipherSuites          []string      `yaml:"cipherSuites,omitempty"`
 	MaxRequestsInFlight   int64         `yaml:"maxRequestsInFlight,omitempty"`
 	RequestTimeoutSeconds int64         `yaml:"requestTimeoutSeconds,omitempty"`
 }
@@ -98,9 +99,17 @@ type Auth struct {
 type Session struct {
 	CookieEncryptionKeyFile     string `yaml:"cookieEncryptionKeyFile,omitempty"`
 	CookieAuthenticationKeyFile string `yaml:"cookieAuthenticationKeyFile,omitempty"`
+	// AcceptedCookieKeys are older keys sessions are still read with, not written with
+	AcceptedCookieKeys []CookieKeyFiles `yaml:"acceptedCookieKeys,omitempty"`
 	// TODO: move InactivityTimeoutSeconds here
 }
 
+// CookieKeyFiles is a pair of session cookie keys.
+type CookieKeyFiles struct {
+	CookieEncryptionKeyFile     string `yaml:"cookieEncryptionKeyFile"`
+	CookieAuthenticationKeyFile string `yaml:"cookieAuthenticationKeyFile"`
+}
+
 // Customization holds configuration such as what logo to use.
 type Customization struct {
 	Branding             string `yaml:"branding,omitempty"`
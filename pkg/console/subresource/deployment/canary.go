@@ -0,0 +1,86 @@
+package deployment
+
+import (
+	"fmt"
+
+	// kube
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/utils/ptr"
+
+	// openshift
+	"github.com/openshift/console-operator/pkg/api"
+)
+
+// canaryComponent labels the canary pods, keeping them out of the console service and
+// of the pods counted by the console deployment and disruption budget.
+const canaryComponent = "ui-canary"
+
+// CanaryDeployment derives a single pod deployment from the console deployment, running
+// the canary console-config in place of the live one.
+func CanaryDeployment(consoleDeployment *appsv1.Deployment, canaryConfigMap *corev1.ConfigMap) *appsv1.Deployment {
+	canary := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            api.ConsoleCanaryName,
+			Namespace:       api.OpenShiftConsoleNamespace,
+			Labels:          map[string]string{},
+			Annotations:     map[string]string{},
+			OwnerReferences: consoleDeployment.OwnerReferences,
+		},
+		Spec: *consoleDeployment.Spec.DeepCopy(),
+	}
+	for key, value := range consoleDeployment.Labels {
+		canary.Labels[key] = value
+	}
+	canary.Annotations[configMapContentHashAnnotation] = configMapContentHash(canaryConfigMap)
+
+	canary.Spec.Replicas = ptr.To[int32](1)
+	canary.Spec.Selector = &metav1.LabelSelector{MatchLabels: canaryPodLabels()}
+	template := &canary.Spec.Template
+	template.Labels = canaryPodLabels()
+	template.Annotations[configMapContentHashAnnotation] = canary.Annotations[configMapContentHashAnnotation]
+	for i, volume := range template.Spec.Volumes {
+		if volume.Name == api.OpenShiftConsoleConfigMapName && volume.ConfigMap != nil {
+			template.Spec.Volumes[i].ConfigMap.Name = canaryConfigMap.Name
+		}
+	}
+	return canary
+}
+
+// CanaryService exposes the canary pod, which serves the certificate of the console
+// service.
+func CanaryService() *corev1.Service {
+	return &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      api.ConsoleCanaryName,
+			Namespace: api.OpenShiftConsoleNamespace,
+			Labels:    map[string]string{"app": api.OpenShiftConsoleName},
+		},
+		Spec: corev1.ServiceSpec{
+			Ports: []corev1.ServicePort{{
+				Name:       api.ConsoleContainerPortName,
+				Protocol:   corev1.ProtocolTCP,
+				Port:       api.ConsoleContainerPort,
+				TargetPort: intstr.FromInt32(api.ConsoleContainerTargetPort),
+			}},
+			Selector: canaryPodLabels(),
+			Type:     corev1.ServiceTypeClusterIP,
+		},
+	}
+}
+
+// CanaryHealthURL is the health endpoint of the canary console behind its service, and
+// CanaryServerName the name its certificate is issued for.
+var (
+	CanaryHealthURL  = fmt.Sprintf("https://%s.%s.svc/health", api.ConsoleCanaryName, api.OpenShiftConsoleNamespace)
+	CanaryServerName = fmt.Sprintf("%s.%s.svc", api.OpenShiftConsoleServiceName, api.OpenShiftConsoleNamespace)
+)
+
+func canaryPodLabels() map[string]string {
+	return map[string]string{
+		"app":       api.OpenShiftConsoleName,
+		"component": canaryComponent,
+	}
+}
@@ -0,0 +1,49 @@
+package deployment
+
+import (
+	"testing"
+
+	"github.com/go-test/deep"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
+
+	configv1 "github.com/openshift/api/config/v1"
+	operatorsv1 "github.com/openshift/api/operator/v1"
+
+	"github.com/openshift/console-operator/pkg/api"
+)
+
+func TestCanaryDeployment(t *testing.T) {
+	consoleDeployment := DefaultDeployment(&operatorsv1.Console{}, &corev1.ConfigMap{}, &corev1.ConfigMap{}, nil, nil, &corev1.ConfigMap{}, &corev1.Secret{}, nil, &configv1.Proxy{}, infrastructureConfigWithTopology(configv1.HighlyAvailableTopologyMode, configv1.HighlyAvailableTopologyMode), nil, nil, nil, false)
+	canaryConfigMap := &corev1.ConfigMap{Data: map[string]string{"console-config.yaml": "kind: ConsoleConfig"}}
+	canaryConfigMap.Name = api.ConsoleCanaryConfigMapName
+
+	canary := CanaryDeployment(consoleDeployment, canaryConfigMap)
+	if canary.Name != api.ConsoleCanaryName || *canary.Spec.Replicas != 1 {
+		t.Errorf("expected a single %s pod, got %d %s pods", api.ConsoleCanaryName, *canary.Spec.Replicas, canary.Name)
+	}
+	consoleSelector, err := labels.ValidatedSelectorFromSet(consoleDeployment.Spec.Selector.MatchLabels)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if consoleSelector.Matches(labels.Set(canary.Spec.Template.Labels)) {
+		t.Errorf("canary pods %v must not be selected by the console deployment", canary.Spec.Template.Labels)
+	}
+	if diff := deep.Equal(CanaryService().Spec.Selector, canary.Spec.Template.Labels); diff != nil {
+		t.Error(diff)
+	}
+	for _, volume := range canary.Spec.Template.Spec.Volumes {
+		if volume.Name == api.OpenShiftConsoleConfigMapName && volume.ConfigMap.Name != api.ConsoleCanaryConfigMapName {
+			t.Errorf("canary mounts the %s config map, want %s", volume.ConfigMap.Name, api.ConsoleCanaryConfigMapName)
+		}
+	}
+	if canary.Annotations[configMapContentHashAnnotation] == consoleDeployment.Annotations[configMapContentHashAnnotation] {
+		t.Errorf("canary should track the content of the canary config map")
+	}
+	for _, volume := range consoleDeployment.Spec.Template.Spec.Volumes {
+		if volume.ConfigMap != nil && volume.ConfigMap.Name == api.ConsoleCanaryConfigMapName {
+			t.Errorf("the console deployment must be left untouched")
+		}
+	}
+}
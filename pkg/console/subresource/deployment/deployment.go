@@ -3,6 +3,7 @@ package deployment
 import (
 	"context"
 	"fmt"
+	"strings"
 
 	// kube
 	appsv1 "k8s.io/api/apps/v1"
@@ -17,6 +18,7 @@ import (
 	operatorv1 "github.com/openshift/api/operator/v1"
 	"github.com/openshift/console-operator/bindata"
 	"github.com/openshift/console-operator/pkg/api"
+	hpasub "github.com/openshift/console-operator/pkg/console/subresource/hpa"
 	"github.com/openshift/console-operator/pkg/console/subresource/util"
 	"github.com/openshift/library-go/pkg/operator/resource/resourceread"
 )
@@ -27,29 +29,38 @@ const (
 	SingleNodeConsoleReplicas = 1
 )
 
+// The pod template annotations tracking the console inputs hold a hash of the content
+// the console reads from them, their keys still end in -version as they once held the
+// resource version of the input.
 const (
-	configMapResourceVersionAnnotation             = "console.openshift.io/console-config-version"
-	proxyConfigResourceVersionAnnotation           = "console.openshift.io/proxy-config-version"
-	infrastructureConfigResourceVersionAnnotation  = "console.openshift.io/infrastructure-config-version"
-	serviceCAConfigMapResourceVersionAnnotation    = "console.openshift.io/service-ca-config-version"
-	trustedCAConfigMapResourceVersionAnnotation    = "console.openshift.io/trusted-ca-config-version"
-	secretResourceVersionAnnotation                = "console.openshift.io/oauth-secret-version"
-	consoleImageAnnotation                         = "console.openshift.io/image"
-	authnConfigVersionAnnotation                   = "console.openshift.io/authentication-config-version"
-	authnCATrustConfigMapResourceVersionAnnotation = "console.openshift.io/authn-ca-trust-config-version"
-	sessionSecretRVAnnotation                      = "console.openshift.io/session-secret-version"
+	configMapContentHashAnnotation             = "console.openshift.io/console-config-version"
+	proxyConfigContentHashAnnotation           = "console.openshift.io/proxy-config-version"
+	infrastructureConfigContentHashAnnotation  = "console.openshift.io/infrastructure-config-version"
+	serviceCAConfigMapContentHashAnnotation    = "console.openshift.io/service-ca-config-version"
+	trustedCAConfigMapContentHashAnnotation    = "console.openshift.io/trusted-ca-config-version"
+	secretContentHashAnnotation                = "console.openshift.io/oauth-secret-version"
+	consoleImageAnnotation                     = "console.openshift.io/image"
+	authnConfigVersionAnnotation               = "console.openshift.io/authentication-config-version"
+	authnCATrustConfigMapContentHashAnnotation = "console.openshift.io/authn-ca-trust-config-version"
+	sessionSecretContentHashAnnotation         = "console.openshift.io/session-secret-version"
 )
 
 var (
-	resourceAnnotations = []string{
-		configMapResourceVersionAnnotation,
-		proxyConfigResourceVersionAnnotation,
-		infrastructureConfigResourceVersionAnnotation,
-		serviceCAConfigMapResourceVersionAnnotation,
-		authnCATrustConfigMapResourceVersionAnnotation,
-		trustedCAConfigMapResourceVersionAnnotation,
-		secretResourceVersionAnnotation,
-		consoleImageAnnotation,
+	// resourceAnnotations maps the pod template annotations tracking the console inputs
+	// to the name of the input.
+	resourceAnnotations = []struct {
+		annotation string
+		input      string
+	}{
+		{configMapContentHashAnnotation, "console-config"},
+		{proxyConfigContentHashAnnotation, "proxy config"},
+		{infrastructureConfigContentHashAnnotation, "infrastructure config"},
+		{serviceCAConfigMapContentHashAnnotation, "service-ca"},
+		{authnCATrustConfigMapContentHashAnnotation, "authentication CA trust"},
+		{trustedCAConfigMapContentHashAnnotation, "trusted-ca-bundle"},
+		{secretContentHashAnnotation, "oauth client secret"},
+		{sessionSecretContentHashAnnotation, "session secret"},
+		{consoleImageAnnotation, "console image"},
 	}
 )
 
@@ -74,6 +85,9 @@ func DefaultDeployment(
 	sessionSecret *corev1.Secret,
 	proxyConfig *configv1.Proxy,
 	infrastructureConfig *configv1.Infrastructure,
+	resourceSizingConfigMap *corev1.ConfigMap,
+	nodes []*corev1.Node,
+	placement *Placement,
 	canMountCustomLogo bool,
 ) *appsv1.Deployment {
 	authnCATrustConfigMap := localOAuthServingCertConfigMap
@@ -106,6 +120,10 @@ func DefaultDeployment(
 	)
 	withConsoleContainerImage(deployment, operatorConfig, proxyConfig)
 	withConsoleNodeSelector(deployment, infrastructureConfig)
+	withPlacement(deployment, placement)
+	withTopologySpread(deployment, operatorConfig, nodes, "ui")
+	withResources(deployment, operatorConfig, resourceSizingConfigMap)
+	withAutoscaling(deployment, operatorConfig)
 	util.AddOwnerRef(deployment, util.OwnerRefFrom(operatorConfig))
 	return deployment
 }
@@ -113,6 +131,9 @@ func DefaultDeployment(
 func DefaultDownloadsDeployment(
 	operatorConfig *operatorv1.Console,
 	infrastructureConfig *configv1.Infrastructure,
+	resourceSizingConfigMap *corev1.ConfigMap,
+	nodes []*corev1.Node,
+	placement *Placement,
 ) *appsv1.Deployment {
 	downloadsDeployment := resourceread.ReadDeploymentV1OrDie(
 		bindata.MustAsset("assets/deployments/downloads-deployment.yaml"),
@@ -121,6 +142,9 @@ func DefaultDownloadsDeployment(
 	withAffinity(downloadsDeployment, infrastructureConfig, "downloads")
 	withStrategy(downloadsDeployment, infrastructureConfig)
 	withDownloadsContainerImage(downloadsDeployment)
+	withPlacement(downloadsDeployment, placement)
+	withTopologySpread(downloadsDeployment, operatorConfig, nodes, "downloads")
+	withResources(downloadsDeployment, operatorConfig, resourceSizingConfigMap)
 	util.AddOwnerRef(downloadsDeployment, util.OwnerRefFrom(operatorConfig))
 	return downloadsDeployment
 }
@@ -146,6 +170,30 @@ func withReplicas(deployment *appsv1.Deployment, infrastructureConfig *configv1.
 	deployment.Spec.Replicas = &replicas
 }
 
+// withAutoscaling starts the autoscaled console at the minimum replicas of its HPA, the
+// replicas are then left to the HPA. The pods only prefer to run on distinct nodes for
+// the HPA to scale beyond the number of nodes.
+func withAutoscaling(deployment *appsv1.Deployment, operatorConfig *operatorv1.Console) {
+	autoscaling := hpasub.GetValidAutoscaling(operatorConfig)
+	if autoscaling == nil {
+		return
+	}
+	replicas := autoscaling.MinReplicas
+	deployment.Spec.Replicas = &replicas
+
+	podAntiAffinity := deployment.Spec.Template.Spec.Affinity.PodAntiAffinity
+	if podAntiAffinity == nil {
+		return
+	}
+	for _, term := range podAntiAffinity.RequiredDuringSchedulingIgnoredDuringExecution {
+		podAntiAffinity.PreferredDuringSchedulingIgnoredDuringExecution = append(
+			podAntiAffinity.PreferredDuringSchedulingIgnoredDuringExecution,
+			corev1.WeightedPodAffinityTerm{Weight: 100, PodAffinityTerm: term},
+		)
+	}
+	podAntiAffinity.RequiredDuringSchedulingIgnoredDuringExecution = nil
+}
+
 func withAffinity(
 	deployment *appsv1.Deployment,
 	infrastructureConfig *configv1.Infrastructure,
@@ -189,8 +237,8 @@ func withStrategy(deployment *appsv1.Deployment, infrastructureConfig *configv1.
 }
 
 // withConsoleAnnotations adds annotations in the console deployment which are used to track
-// resources that when updated, trigger a new deployment rollout; this happens when the resource
-// version changes.
+// resources that when updated, trigger a new deployment rollout; this happens when the content
+// the console consumes from them changes, metadata-only updates are left out.
 func withConsoleAnnotations(
 	deployment *appsv1.Deployment,
 	consoleConfigMap *corev1.ConfigMap,
@@ -203,21 +251,21 @@ func withConsoleAnnotations(
 	infrastructureConfig *configv1.Infrastructure,
 ) {
 	deployment.ObjectMeta.Annotations = map[string]string{
-		configMapResourceVersionAnnotation:            consoleConfigMap.GetResourceVersion(),
-		serviceCAConfigMapResourceVersionAnnotation:   serviceCAConfigMap.GetResourceVersion(),
-		trustedCAConfigMapResourceVersionAnnotation:   trustedCAConfigMap.GetResourceVersion(),
-		proxyConfigResourceVersionAnnotation:          proxyConfig.GetResourceVersion(),
-		infrastructureConfigResourceVersionAnnotation: infrastructureConfig.GetResourceVersion(),
-		secretResourceVersionAnnotation:               oAuthClientSecret.GetResourceVersion(),
-		consoleImageAnnotation:                        util.GetImageEnv("CONSOLE_IMAGE"),
+		configMapContentHashAnnotation:            configMapContentHash(consoleConfigMap),
+		serviceCAConfigMapContentHashAnnotation:   configMapContentHash(serviceCAConfigMap),
+		trustedCAConfigMapContentHashAnnotation:   configMapContentHash(trustedCAConfigMap),
+		proxyConfigContentHashAnnotation:          proxyConfigContentHash(proxyConfig),
+		infrastructureConfigContentHashAnnotation: infrastructureConfigContentHash(infrastructureConfig),
+		secretContentHashAnnotation:               oauthClientSecretContentHash(oAuthClientSecret),
+		consoleImageAnnotation:                    util.GetImageEnv("CONSOLE_IMAGE"),
 	}
 
 	if authServerCAConfigMap != nil {
-		deployment.ObjectMeta.Annotations[authnCATrustConfigMapResourceVersionAnnotation] = authServerCAConfigMap.GetResourceVersion()
+		deployment.ObjectMeta.Annotations[authnCATrustConfigMapContentHashAnnotation] = configMapContentHash(authServerCAConfigMap)
 	}
 
 	if sessionSecret != nil {
-		deployment.ObjectMeta.Annotations[sessionSecretRVAnnotation] = sessionSecret.GetResourceVersion()
+		deployment.ObjectMeta.Annotations[sessionSecretContentHashAnnotation] = secretContentHash(sessionSecret)
 	}
 
 	podAnnotations := deployment.Spec.Template.ObjectMeta.Annotations
@@ -354,6 +402,8 @@ func Stub() *appsv1.Deployment {
 	return dep
 }
 
+// LogDeploymentAnnotationChanges reports the console inputs whose content changed since
+// the existing deployment, and so trigger a rollout.
 func LogDeploymentAnnotationChanges(
 	client appsclientv1.DeploymentsGetter,
 	updated *appsv1.Deployment,
@@ -365,15 +415,15 @@ func LogDeploymentAnnotationChanges(
 		return
 	}
 
-	changed := false
-	for _, annot := range resourceAnnotations {
-		if existing.ObjectMeta.Annotations[annot] != updated.ObjectMeta.Annotations[annot] {
-			changed = true
-			klog.V(4).Infof("deployment annotation[%v] has changed from: %v to %v", annot, existing.ObjectMeta.Annotations[annot], updated.ObjectMeta.Annotations[annot])
+	changed := []string{}
+	for _, resource := range resourceAnnotations {
+		if existing.ObjectMeta.Annotations[resource.annotation] != updated.ObjectMeta.Annotations[resource.annotation] {
+			changed = append(changed, resource.input)
+			klog.V(4).Infof("deployment annotation[%v] has changed from: %v to %v", resource.annotation, existing.ObjectMeta.Annotations[resource.annotation], updated.ObjectMeta.Annotations[resource.annotation])
 		}
 	}
-	if changed {
-		klog.V(4).Infoln("deployment resource versions have changed")
+	if len(changed) > 0 {
+		klog.V(2).Infof("deployment rolling out, content changed for: %s", strings.Join(changed, ", "))
 	}
 }
 
@@ -454,6 +504,73 @@ func IsAvailableAndUpdated(deployment *appsv1.Deployment) bool {
 	return available && currentGen && updated
 }
 
+// IsConsoleConfigRolledOut returns true once the deployment is available and updated
+// with the given console-config.
+func IsConsoleConfigRolledOut(deployment *appsv1.Deployment, consoleConfigMap *corev1.ConfigMap) bool {
+	return deployment.Annotations[configMapContentHashAnnotation] == configMapContentHash(consoleConfigMap) && IsAvailableAndUpdated(deployment)
+}
+
+// IsConsoleConfigFailing returns true when a pod of the deployment running the given
+// console-config has a container that crash loops, exited with an error, or runs without
+// being ready. Pods that are not scheduled yet or are still pulling their image are not
+// failing on the config.
+func IsConsoleConfigFailing(pods []*corev1.Pod, consoleConfigMap *corev1.ConfigMap) bool {
+	configHash := configMapContentHash(consoleConfigMap)
+	for _, pod := range pods {
+		if pod.Annotations[configMapContentHashAnnotation] != configHash || pod.DeletionTimestamp != nil {
+			continue
+		}
+		for _, containerStatus := range pod.Status.ContainerStatuses {
+			if isContainerFailing(containerStatus) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func isContainerFailing(containerStatus corev1.ContainerStatus) bool {
+	switch {
+	case containerStatus.State.Waiting != nil:
+		return containerStatus.State.Waiting.Reason == "CrashLoopBackOff"
+	case containerStatus.State.Terminated != nil:
+		return containerStatus.State.Terminated.ExitCode != 0
+	case containerStatus.State.Running != nil:
+		return !containerStatus.Ready
+	}
+	return false
+}
+
+// HasOAuthClientSecretContent returns true when the deployment mounts the content of the
+// given oauth client secret.
+func HasOAuthClientSecretContent(deployment *appsv1.Deployment, oAuthClientSecret *corev1.Secret) bool {
+	return deployment.Annotations[secretContentHashAnnotation] == oauthClientSecretContentHash(oAuthClientSecret)
+}
+
+// HasAuthnCATrustContent returns true when the deployment mounts the content of the given
+// authentication CA trust config map.
+func HasAuthnCATrustContent(deployment *appsv1.Deployment, authnCATrustConfigMap *corev1.ConfigMap) bool {
+	return deployment.Annotations[authnCATrustConfigMapContentHashAnnotation] == configMapContentHash(authnCATrustConfigMap)
+}
+
+// GetAuthServerCAConfigMapName returns the name of the authentication server CA config map
+// the deployment mounts, or an empty string when it mounts none.
+func GetAuthServerCAConfigMapName(deployment *appsv1.Deployment) string {
+	for _, container := range deployment.Spec.Template.Spec.Containers {
+		for _, mount := range container.VolumeMounts {
+			if mount.MountPath != api.AuthServerCAMountDir {
+				continue
+			}
+			for _, volume := range deployment.Spec.Template.Spec.Volumes {
+				if volume.Name == mount.Name && volume.ConfigMap != nil {
+					return volume.ConfigMap.Name
+				}
+			}
+		}
+	}
+	return ""
+}
+
 func defaultVolumeConfig() []volumeConfig {
 	return []volumeConfig{
 		{
@@ -523,7 +640,7 @@ func authServerCAVolumeConfig(cmName string) volumeConfig {
 func sessionSecretVolumeConfig() volumeConfig {
 	return volumeConfig{
 		name:     api.SessionSecretName,
-		path:     "/var/session-secret",
+		path:     api.SessionSecretMountDir,
 		readOnly: true,
 		isSecret: true,
 	}
@@ -18,6 +18,7 @@ import (
 	"github.com/openshift/console-operator/bindata"
 	"github.com/openshift/console-operator/pkg/api"
 	"github.com/openshift/console-operator/pkg/console/subresource/configmap"
+	hpasub "github.com/openshift/console-operator/pkg/console/subresource/hpa"
 	"github.com/openshift/console-operator/pkg/console/subresource/util"
 	"github.com/openshift/library-go/pkg/operator/resource/resourceread"
 )
@@ -75,14 +76,7 @@ func TestDefaultDeployment(t *testing.T) {
 		DeletionGracePeriodSeconds: nil,
 		Labels:                     labels,
 		Annotations: map[string]string{
-			configMapResourceVersionAnnotation:             "",
-			secretResourceVersionAnnotation:                "",
-			authnCATrustConfigMapResourceVersionAnnotation: "",
-			serviceCAConfigMapResourceVersionAnnotation:    "",
-			trustedCAConfigMapResourceVersionAnnotation:    "",
-			proxyConfigResourceVersionAnnotation:           "",
-			infrastructureConfigResourceVersionAnnotation:  "",
-			consoleImageAnnotation:                         "",
+			consoleImageAnnotation: "",
 		},
 		OwnerReferences: []metav1.OwnerReference{{
 			APIVersion: "operator.openshift.io/v1",
@@ -129,16 +123,9 @@ func TestDefaultDeployment(t *testing.T) {
 	}
 
 	consoleDeploymentTemplateAnnotations := map[string]string{
-		configMapResourceVersionAnnotation:             "",
-		secretResourceVersionAnnotation:                "",
-		authnCATrustConfigMapResourceVersionAnnotation: "",
-		serviceCAConfigMapResourceVersionAnnotation:    "",
-		trustedCAConfigMapResourceVersionAnnotation:    "",
-		proxyConfigResourceVersionAnnotation:           "",
-		infrastructureConfigResourceVersionAnnotation:  "",
-		consoleImageAnnotation:                         "",
-		workloadManagementAnnotation:                   workloadManagementAnnotationValue,
-		requiredSCCAnnotation:                          "restricted-v2",
+		consoleImageAnnotation:       "",
+		workloadManagementAnnotation: workloadManagementAnnotationValue,
+		requiredSCCAnnotation:        "restricted-v2",
 	}
 
 	consoleDeploymentAffinity := &corev1.Affinity{
@@ -192,10 +179,23 @@ func TestDefaultDeployment(t *testing.T) {
 	consoleDeploymentContainerTrusted := consoleDeploymentTemplate.Spec.Template.Spec.Containers[0]
 	consoleDeploymentVolumesTrusted := consoleDeploymentTemplate.Spec.Template.Spec.Volumes
 
+	// the content hashes of the inputs shared by the test cases, a test case lists the
+	// hashes of the inputs it changes
+	defaultHashes := map[string]string{
+		configMapContentHashAnnotation:             "6fdf4f59a8166567ad2387072b935f84e4399bdebd486b6f6974a10d6c50a957",
+		secretContentHashAnnotation:                "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855",
+		authnCATrustConfigMapContentHashAnnotation: "995bce421be22f2ce3510447c49c04f0d88af5bf0dd18d525c5106d54d417cdf",
+		serviceCAConfigMapContentHashAnnotation:    "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855",
+		trustedCAConfigMapContentHashAnnotation:    "e9edbd2bf235173239a6a27eb6f6213cf9101a86fdecb1a0b7b6a2b7e230b950",
+		proxyConfigContentHashAnnotation:           "3fcfd464595cf6e7e6441bbb710e6ff29682aa41dd8190aeb9e0cb51e8e3f24e",
+		infrastructureConfigContentHashAnnotation:  "ebbced28e926a15500a7cd569e435c9dd06ee6d39f79305c763cc53142a4a16f",
+	}
+
 	tests := []struct {
-		name string
-		args args
-		want *appsv1.Deployment
+		name       string
+		args       args
+		wantHashes map[string]string
+		want       *appsv1.Deployment
 	}{
 		{
 			name: "Test Default Config Map",
@@ -299,6 +299,9 @@ func TestDefaultDeployment(t *testing.T) {
 				proxyConfig:          proxyConfig,
 				infrastructureConfig: infrastructureConfigHighlyAvailable,
 			},
+			wantHashes: map[string]string{
+				trustedCAConfigMapContentHashAnnotation: "4d021361089d36d86fc23f3993ac886116b5a4e05a9a1d881654dee763ee0577",
+			},
 			want: &appsv1.Deployment{
 				TypeMeta: metav1.TypeMeta{
 					Kind:       "Deployment",
@@ -380,6 +383,9 @@ func TestDefaultDeployment(t *testing.T) {
 				proxyConfig:          proxyConfig,
 				infrastructureConfig: infrastructureConfigSingleReplica,
 			},
+			wantHashes: map[string]string{
+				infrastructureConfigContentHashAnnotation: "2b75c8b22230b28b9c5f4306679b91a2daab3a77d43acce4ba199eea4c9152d8",
+			},
 			want: &appsv1.Deployment{
 				TypeMeta: metav1.TypeMeta{
 					Kind:       "Deployment",
@@ -454,6 +460,9 @@ func TestDefaultDeployment(t *testing.T) {
 				proxyConfig:          proxyConfig,
 				infrastructureConfig: infrastructureConfigExternalTopologyMode,
 			},
+			wantHashes: map[string]string{
+				infrastructureConfigContentHashAnnotation: "04184b3f97d568e8bc319da8139a149a41d8a987ffe4acbc93d804cfe0b23890",
+			},
 			want: &appsv1.Deployment{
 				TypeMeta: metav1.TypeMeta{
 					Kind:       "Deployment",
@@ -515,6 +524,14 @@ func TestDefaultDeployment(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
+			want := tt.want.DeepCopy()
+			for annotation, hash := range defaultHashes {
+				if caseHash, ok := tt.wantHashes[annotation]; ok {
+					hash = caseHash
+				}
+				want.Annotations[annotation] = hash
+				want.Spec.Template.Annotations[annotation] = hash
+			}
 			if diff := deep.Equal(DefaultDeployment(
 				tt.args.consoleOperatorConfig,
 				tt.args.consoleConfig,
@@ -526,8 +543,11 @@ func TestDefaultDeployment(t *testing.T) {
 				tt.args.sessionSecret,
 				tt.args.proxyConfig,
 				tt.args.infrastructureConfig,
+				nil,
+				nil,
+				nil,
 				tt.args.canMountCustomLogo,
-			), tt.want); diff != nil {
+			), want); diff != nil {
 				t.Error(diff)
 			}
 		})
@@ -595,6 +615,9 @@ func TestWithConsoleAnnotations(t *testing.T) {
 		},
 	}
 
+	// the hash of the empty content of the config maps and secret
+	const emptyContentHash = "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"
+
 	tests := []struct {
 		name string
 		args args
@@ -628,29 +651,29 @@ func TestWithConsoleAnnotations(t *testing.T) {
 			want: &appsv1.Deployment{
 				ObjectMeta: metav1.ObjectMeta{
 					Annotations: map[string]string{
-						configMapResourceVersionAnnotation:             consoleConfigMap.GetResourceVersion(),
-						serviceCAConfigMapResourceVersionAnnotation:    serviceCAConfigMap.GetResourceVersion(),
-						authnCATrustConfigMapResourceVersionAnnotation: oauthServingCertConfigMap.GetResourceVersion(),
-						trustedCAConfigMapResourceVersionAnnotation:    trustedCAConfigMap.GetResourceVersion(),
-						proxyConfigResourceVersionAnnotation:           proxyConfig.GetResourceVersion(),
-						infrastructureConfigResourceVersionAnnotation:  infrastructureConfig.GetResourceVersion(),
-						secretResourceVersionAnnotation:                oAuthClientSecret.GetResourceVersion(),
-						consoleImageAnnotation:                         util.GetImageEnv("CONSOLE_IMAGE"),
+						configMapContentHashAnnotation:             "6fdf4f59a8166567ad2387072b935f84e4399bdebd486b6f6974a10d6c50a957",
+						serviceCAConfigMapContentHashAnnotation:    emptyContentHash,
+						authnCATrustConfigMapContentHashAnnotation: emptyContentHash,
+						trustedCAConfigMapContentHashAnnotation:    emptyContentHash,
+						proxyConfigContentHashAnnotation:           "67ab35323a05b816e90a7f9f496e9eb8d0058b231146ff561c60d13560d302de",
+						infrastructureConfigContentHashAnnotation:  "f5d590afae8b0ae6f169c79ba3497c6ec3a9c023e1cd0366a43294603ab17ef5",
+						secretContentHashAnnotation:                emptyContentHash,
+						consoleImageAnnotation:                     util.GetImageEnv("CONSOLE_IMAGE"),
 					},
 				},
 				Spec: appsv1.DeploymentSpec{
 					Template: corev1.PodTemplateSpec{
 						ObjectMeta: metav1.ObjectMeta{
 							Annotations: map[string]string{
-								workloadManagementAnnotation:                   workloadManagementAnnotationValue,
-								configMapResourceVersionAnnotation:             consoleConfigMap.GetResourceVersion(),
-								serviceCAConfigMapResourceVersionAnnotation:    serviceCAConfigMap.GetResourceVersion(),
-								authnCATrustConfigMapResourceVersionAnnotation: oauthServingCertConfigMap.GetResourceVersion(),
-								trustedCAConfigMapResourceVersionAnnotation:    trustedCAConfigMap.GetResourceVersion(),
-								proxyConfigResourceVersionAnnotation:           proxyConfig.GetResourceVersion(),
-								infrastructureConfigResourceVersionAnnotation:  infrastructureConfig.GetResourceVersion(),
-								secretResourceVersionAnnotation:                oAuthClientSecret.GetResourceVersion(),
-								consoleImageAnnotation:                         util.GetImageEnv("CONSOLE_IMAGE"),
+								workloadManagementAnnotation:               workloadManagementAnnotationValue,
+								configMapContentHashAnnotation:             "6fdf4f59a8166567ad2387072b935f84e4399bdebd486b6f6974a10d6c50a957",
+								serviceCAConfigMapContentHashAnnotation:    emptyContentHash,
+								authnCATrustConfigMapContentHashAnnotation: emptyContentHash,
+								trustedCAConfigMapContentHashAnnotation:    emptyContentHash,
+								proxyConfigContentHashAnnotation:           "67ab35323a05b816e90a7f9f496e9eb8d0058b231146ff561c60d13560d302de",
+								infrastructureConfigContentHashAnnotation:  "f5d590afae8b0ae6f169c79ba3497c6ec3a9c023e1cd0366a43294603ab17ef5",
+								secretContentHashAnnotation:                emptyContentHash,
+								consoleImageAnnotation:                     util.GetImageEnv("CONSOLE_IMAGE"),
 							},
 						},
 					},
@@ -896,6 +919,51 @@ func TestWithAffinity(t *testing.T) {
 	}
 }
 
+func TestWithAutoscaling(t *testing.T) {
+	infrastructureConfigHighlyAvailable := infrastructureConfigWithTopology(configv1.HighlyAvailableTopologyMode, configv1.HighlyAvailableTopologyMode)
+	tests := []struct {
+		name                     string
+		annotations              map[string]string
+		wantReplicas             int32
+		wantRequiredAntiAffinity bool
+	}{
+		{
+			name:                     "Fixed replicas",
+			wantReplicas:             DefaultConsoleReplicas,
+			wantRequiredAntiAffinity: true,
+		},
+		{
+			name:         "Autoscaled replicas",
+			annotations:  map[string]string{hpasub.AutoscalingAnnotation: `{"minReplicas":3,"maxReplicas":8}`},
+			wantReplicas: 3,
+		},
+		{
+			name:                     "Invalid autoscaling",
+			annotations:              map[string]string{hpasub.AutoscalingAnnotation: `{"minReplicas":3,"maxReplicas":1}`},
+			wantReplicas:             DefaultConsoleReplicas,
+			wantRequiredAntiAffinity: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			deployment := &appsv1.Deployment{}
+			withReplicas(deployment, infrastructureConfigHighlyAvailable)
+			withAffinity(deployment, infrastructureConfigHighlyAvailable, "ui")
+			withAutoscaling(deployment, &operatorsv1.Console{ObjectMeta: metav1.ObjectMeta{Annotations: tt.annotations}})
+			if *deployment.Spec.Replicas != tt.wantReplicas {
+				t.Errorf("replicas = %d, want %d", *deployment.Spec.Replicas, tt.wantReplicas)
+			}
+			podAntiAffinity := deployment.Spec.Template.Spec.Affinity.PodAntiAffinity
+			if required := len(podAntiAffinity.RequiredDuringSchedulingIgnoredDuringExecution) != 0; required != tt.wantRequiredAntiAffinity {
+				t.Errorf("required anti-affinity = %v, want %v", required, tt.wantRequiredAntiAffinity)
+			}
+			if len(podAntiAffinity.RequiredDuringSchedulingIgnoredDuringExecution)+len(podAntiAffinity.PreferredDuringSchedulingIgnoredDuringExecution) != 1 {
+				t.Errorf("expected a single anti-affinity term, got %v", podAntiAffinity)
+			}
+		})
+	}
+}
+
 func TestWithConsoleVolumes(t *testing.T) {
 	type args struct {
 		deployment         *appsv1.Deployment
@@ -1672,7 +1740,7 @@ func TestDefaultDownloadsDeployment(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			if diff := deep.Equal(DefaultDownloadsDeployment(tt.args.config, tt.args.infrastructure), tt.want); diff != nil {
+			if diff := deep.Equal(DefaultDownloadsDeployment(tt.args.config, tt.args.infrastructure, nil, nil, nil), tt.want); diff != nil {
 				t.Error(diff)
 			}
 		})
@@ -1940,3 +2008,67 @@ func infrastructureConfigWithTopology(controlPlaneTopologyMode, infrastructureTo
 		},
 	}
 }
+
+func TestIsConsoleConfigFailing(t *testing.T) {
+	consoleConfig := &corev1.ConfigMap{Data: map[string]string{"console-config.yaml": "kind: ConsoleConfig\n"}}
+	previousConfig := &corev1.ConfigMap{Data: map[string]string{"console-config.yaml": "kind: ConsoleConfig\nauth: {}\n"}}
+	podWith := func(configMap *corev1.ConfigMap, containerStatus corev1.ContainerStatus) *corev1.Pod {
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Annotations: map[string]string{configMapContentHashAnnotation: configMapContentHash(configMap)},
+			},
+			Status: corev1.PodStatus{ContainerStatuses: []corev1.ContainerStatus{containerStatus}},
+		}
+	}
+	crashLooping := corev1.ContainerStatus{State: corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{Reason: "CrashLoopBackOff"}}}
+
+	tests := []struct {
+		name string
+		pods []*corev1.Pod
+		want bool
+	}{
+		{
+			name: "Ready pod",
+			pods: []*corev1.Pod{podWith(consoleConfig, corev1.ContainerStatus{Ready: true, State: corev1.ContainerState{Running: &corev1.ContainerStateRunning{}}})},
+		},
+		{
+			name: "Crash looping pod",
+			pods: []*corev1.Pod{podWith(consoleConfig, crashLooping)},
+			want: true,
+		},
+		{
+			name: "Running pod that is not ready",
+			pods: []*corev1.Pod{podWith(consoleConfig, corev1.ContainerStatus{State: corev1.ContainerState{Running: &corev1.ContainerStateRunning{}}})},
+			want: true,
+		},
+		{
+			name: "Container that exited with an error",
+			pods: []*corev1.Pod{podWith(consoleConfig, corev1.ContainerStatus{State: corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{ExitCode: 2}}})},
+			want: true,
+		},
+		{
+			name: "Pod pulling its image",
+			pods: []*corev1.Pod{podWith(consoleConfig, corev1.ContainerStatus{State: corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{Reason: "ImagePullBackOff"}}})},
+		},
+		{
+			name: "Unscheduled pod",
+			pods: []*corev1.Pod{{
+				ObjectMeta: metav1.ObjectMeta{
+					Annotations: map[string]string{configMapContentHashAnnotation: configMapContentHash(consoleConfig)},
+				},
+				Status: corev1.PodStatus{Phase: corev1.PodPending},
+			}},
+		},
+		{
+			name: "Crash looping pod of the previous config",
+			pods: []*corev1.Pod{podWith(previousConfig, crashLooping)},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IsConsoleConfigFailing(tt.pods, consoleConfig); got != tt.want {
+				t.Errorf("IsConsoleConfigFailing() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
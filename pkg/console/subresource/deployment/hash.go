@@ -0,0 +1,91 @@
+package deployment
+
+import (
+	"crypto/sha256"
+	"fmt"
+
+	// kube
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	// openshift
+	configv1 "github.com/openshift/api/config/v1"
+	"github.com/openshift/console-operator/pkg/api"
+)
+
+// contentHash returns a hash of the content, its keys are hashed in order and every key
+// and value is prefixed with its length so that no two contents hash the same way.
+func contentHash(content map[string][]byte) string {
+	hash := sha256.New()
+	for _, key := range sets.List(sets.KeySet(content)) {
+		fmt.Fprintf(hash, "%d:%s%d:", len(key), key, len(content[key]))
+		hash.Write(content[key])
+	}
+	return fmt.Sprintf("%x", hash.Sum(nil))
+}
+
+// configMapContentHash hashes the data the console mounts from a config map, leaving out
+// its metadata. It is empty for a missing config map.
+func configMapContentHash(configMap *corev1.ConfigMap) string {
+	if configMap == nil {
+		return ""
+	}
+	content := map[string][]byte{}
+	for key, value := range configMap.Data {
+		content["data/"+key] = []byte(value)
+	}
+	for key, value := range configMap.BinaryData {
+		content["binaryData/"+key] = value
+	}
+	return contentHash(content)
+}
+
+// secretContentHash hashes the data the console mounts from a secret, leaving out its
+// metadata. It is empty for a missing secret.
+func secretContentHash(secret *corev1.Secret) string {
+	if secret == nil {
+		return ""
+	}
+	return contentHash(secret.Data)
+}
+
+// oauthClientSecretContentHash hashes the client secrets the console reads from the oauth
+// client secret. The secrets staged and kept around by a rotation are left out, the console
+// only rolls out when its own client secret changes.
+func oauthClientSecretContentHash(secret *corev1.Secret) string {
+	if secret == nil {
+		return ""
+	}
+	data := map[string][]byte{}
+	for key, value := range secret.Data {
+		if key != api.NextOAuthClientSecretKey && key != api.PreviousOAuthClientSecretKey {
+			data[key] = value
+		}
+	}
+	return contentHash(data)
+}
+
+// proxyConfigContentHash hashes the proxy settings the console gets through its
+// environment.
+func proxyConfigContentHash(proxyConfig *configv1.Proxy) string {
+	if proxyConfig == nil {
+		return ""
+	}
+	return contentHash(map[string][]byte{
+		"httpsProxy": []byte(proxyConfig.Status.HTTPSProxy),
+		"httpProxy":  []byte(proxyConfig.Status.HTTPProxy),
+		"noProxy":    []byte(proxyConfig.Status.NoProxy),
+	})
+}
+
+// infrastructureConfigContentHash hashes the cluster topology the console pods are laid
+// out from.
+func infrastructureConfigContentHash(infrastructureConfig *configv1.Infrastructure) string {
+	if infrastructureConfig == nil {
+		return ""
+	}
+	return contentHash(map[string][]byte{
+		"controlPlaneTopology":   []byte(infrastructureConfig.Status.ControlPlaneTopology),
+		"infrastructureTopology": []byte(infrastructureConfig.Status.InfrastructureTopology),
+	})
+}
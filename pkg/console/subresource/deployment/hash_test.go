@@ -0,0 +1,106 @@
+package deployment
+
+import (
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	configv1 "github.com/openshift/api/config/v1"
+
+	"github.com/openshift/console-operator/pkg/api"
+)
+
+func TestContentHashes(t *testing.T) {
+	configMap := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "trusted-ca-bundle", ResourceVersion: "1"},
+		Data:       map[string]string{"ca-bundle.crt": "bundle"},
+	}
+	relabeledConfigMap := configMap.DeepCopy()
+	relabeledConfigMap.ResourceVersion = "2"
+	relabeledConfigMap.Labels = map[string]string{"config.openshift.io/inject-trusted-cabundle": "true"}
+	updatedConfigMap := configMap.DeepCopy()
+	updatedConfigMap.Data["ca-bundle.crt"] = "rotated bundle"
+
+	proxyConfig := &configv1.Proxy{
+		ObjectMeta: metav1.ObjectMeta{ResourceVersion: "1"},
+		Status:     configv1.ProxyStatus{HTTPSProxy: "https://proxy.example.com"},
+	}
+	readinessUpdatedProxyConfig := proxyConfig.DeepCopy()
+	readinessUpdatedProxyConfig.ResourceVersion = "2"
+	readinessUpdatedProxyConfig.Spec.ReadinessEndpoints = []string{"https://example.com"}
+	updatedProxyConfig := proxyConfig.DeepCopy()
+	updatedProxyConfig.Status.NoProxy = ".cluster.local"
+
+	infrastructureConfig := infrastructureConfigWithTopology(configv1.HighlyAvailableTopologyMode, configv1.HighlyAvailableTopologyMode)
+	annotatedInfrastructureConfig := infrastructureConfig.DeepCopy()
+	annotatedInfrastructureConfig.ResourceVersion = "2"
+	annotatedInfrastructureConfig.Annotations = map[string]string{"example.com/owner": "platform"}
+	updatedInfrastructureConfig := infrastructureConfigWithTopology(configv1.SingleReplicaTopologyMode, configv1.SingleReplicaTopologyMode)
+
+	oAuthClientSecret := &corev1.Secret{Data: map[string][]byte{"clientSecret": []byte("current")}}
+	stagedOAuthClientSecret := oAuthClientSecret.DeepCopy()
+	stagedOAuthClientSecret.Data[api.NextOAuthClientSecretKey] = []byte("next")
+
+	tests := []struct {
+		name        string
+		hash        string
+		updatedHash string
+		wantChange  bool
+	}{
+		{
+			name:        "Config map metadata update",
+			hash:        configMapContentHash(configMap),
+			updatedHash: configMapContentHash(relabeledConfigMap),
+		},
+		{
+			name:        "Config map data update",
+			hash:        configMapContentHash(configMap),
+			updatedHash: configMapContentHash(updatedConfigMap),
+			wantChange:  true,
+		},
+		{
+			name:        "OAuth client secret staged for a rotation",
+			hash:        secretContentHash(oAuthClientSecret),
+			updatedHash: oauthClientSecretContentHash(stagedOAuthClientSecret),
+		},
+		{
+			name:        "Proxy update the console does not consume",
+			hash:        proxyConfigContentHash(proxyConfig),
+			updatedHash: proxyConfigContentHash(readinessUpdatedProxyConfig),
+		},
+		{
+			name:        "Proxy update",
+			hash:        proxyConfigContentHash(proxyConfig),
+			updatedHash: proxyConfigContentHash(updatedProxyConfig),
+			wantChange:  true,
+		},
+		{
+			name:        "Infrastructure metadata update",
+			hash:        infrastructureConfigContentHash(infrastructureConfig),
+			updatedHash: infrastructureConfigContentHash(annotatedInfrastructureConfig),
+		},
+		{
+			name:        "Infrastructure topology update",
+			hash:        infrastructureConfigContentHash(infrastructureConfig),
+			updatedHash: infrastructureConfigContentHash(updatedInfrastructureConfig),
+			wantChange:  true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if changed := tt.hash != tt.updatedHash; changed != tt.wantChange {
+				t.Errorf("hash changed = %v, want %v", changed, tt.wantChange)
+			}
+		})
+	}
+}
+
+func TestContentHashKeyBoundaries(t *testing.T) {
+	if contentHash(map[string][]byte{"a": []byte("bc")}) == contentHash(map[string][]byte{"ab": []byte("c")}) {
+		t.Error("expected content with different keys to hash differently")
+	}
+	if contentHash(map[string][]byte{"a": []byte("1:b1:c")}) == contentHash(map[string][]byte{"a": []byte(""), "b": []byte("c")}) {
+		t.Error("expected a value spelling out another key to hash differently")
+	}
+}
@@ -0,0 +1,207 @@
+package deployment
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	// kube
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1validation "k8s.io/apimachinery/pkg/apis/meta/v1/validation"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	corev1listers "k8s.io/client-go/listers/core/v1"
+	schedulingv1listers "k8s.io/client-go/listers/scheduling/v1"
+
+	// openshift
+	configv1 "github.com/openshift/api/config/v1"
+	operatorv1 "github.com/openshift/api/operator/v1"
+	operatorv1listers "github.com/openshift/client-go/operator/listers/operator/v1"
+	"github.com/openshift/console-operator/bindata"
+	"github.com/openshift/console-operator/pkg/api"
+	"github.com/openshift/library-go/pkg/operator/resource/resourceread"
+)
+
+const (
+	// PlacementAnnotation is set on the operator config to a JSON map of deployment name,
+	// console or downloads, to the Placement of its pods.
+	PlacementAnnotation = "console.openshift.io/placement"
+	// PlacementConfigMapAnnotation is set on the operator config to the name of a config
+	// map in openshift-config holding the placements under PlacementConfigMapKey, in
+	// place of the PlacementAnnotation.
+	PlacementConfigMapAnnotation = "console.openshift.io/placement-configmap"
+	PlacementConfigMapKey        = "placement.json"
+)
+
+// GetPlacementConfigMapNames returns the name of the openshift-config config map
+// referenced by the operator config for the placements, if any.
+func GetPlacementConfigMapNames(operatorConfigLister operatorv1listers.ConsoleLister) []string {
+	operatorConfig, err := operatorConfigLister.Get(api.ConfigResourceName)
+	if err != nil {
+		return nil
+	}
+	configMapName, ok := operatorConfig.Annotations[PlacementConfigMapAnnotation]
+	if !ok {
+		return nil
+	}
+	return []string{configMapName}
+}
+
+// Placement pins the pods of a deployment to a set of nodes. The node selector replaces
+// the default one, the tolerations are added to the default ones.
+type Placement struct {
+	NodeSelector      map[string]string   `json:"nodeSelector,omitempty"`
+	Tolerations       []corev1.Toleration `json:"tolerations,omitempty"`
+	PriorityClassName string              `json:"priorityClassName,omitempty"`
+}
+
+// GetPlacement reads the placement of a deployment set on the operator config, or in the
+// config map it references, nil when the deployment keeps its default placement. The
+// returned reason tells why the placement is invalid.
+func GetPlacement(operatorConfig *operatorv1.Console, configMapLister corev1listers.ConfigMapLister, deploymentName string) (*Placement, string, error) {
+	value, inline := operatorConfig.Annotations[PlacementAnnotation]
+	configMapName, referenced := operatorConfig.Annotations[PlacementConfigMapAnnotation]
+	switch {
+	case inline && referenced:
+		return nil, "InvalidPlacement", fmt.Errorf("%s and %s annotations are mutually exclusive", PlacementAnnotation, PlacementConfigMapAnnotation)
+	case referenced:
+		configMap, err := configMapLister.ConfigMaps(api.OpenShiftConfigNamespace).Get(configMapName)
+		if err != nil {
+			return nil, "FailedGetPlacementConfigMap", err
+		}
+		if value, inline = configMap.Data[PlacementConfigMapKey]; !inline {
+			return nil, "InvalidPlacement", fmt.Errorf("%s config map has no %s key", configMapName, PlacementConfigMapKey)
+		}
+	case !inline:
+		return nil, "", nil
+	}
+
+	placements := map[string]*Placement{}
+	if err := json.Unmarshal([]byte(value), &placements); err != nil {
+		return nil, "InvalidPlacement", fmt.Errorf("invalid placement: %w", err)
+	}
+	for name := range placements {
+		if name != api.OpenShiftConsoleDeploymentName && name != api.OpenShiftConsoleDownloadsDeploymentName {
+			return nil, "InvalidPlacement", fmt.Errorf("invalid placement: unknown deployment %q", name)
+		}
+	}
+	placement := placements[deploymentName]
+	if placement == nil {
+		return nil, "", nil
+	}
+	if err := validatePlacement(placement); err != nil {
+		return nil, "InvalidPlacement", fmt.Errorf("invalid %s placement: %w", deploymentName, err)
+	}
+	return placement, "", nil
+}
+
+// GetSchedulablePlacement is GetPlacement for placements whose priority class exists and
+// that at least one node can run, the deployment keeps its default placement otherwise.
+func GetSchedulablePlacement(operatorConfig *operatorv1.Console, configMapLister corev1listers.ConfigMapLister, priorityClassLister schedulingv1listers.PriorityClassLister, deploymentName string, infrastructureConfig *configv1.Infrastructure, nodes []*corev1.Node) (*Placement, string, error) {
+	placement, reason, err := GetPlacement(operatorConfig, configMapLister, deploymentName)
+	if placement == nil {
+		return nil, reason, err
+	}
+	if len(placement.PriorityClassName) != 0 {
+		_, err := priorityClassLister.Get(placement.PriorityClassName)
+		if apierrors.IsNotFound(err) {
+			return nil, "MissingPriorityClass", fmt.Errorf("priority class %q of the %s placement does not exist", placement.PriorityClassName, deploymentName)
+		}
+		if err != nil {
+			return nil, "FailedGetPriorityClass", err
+		}
+	}
+	if err := validatePlacementNodes(deploymentName, placement, infrastructureConfig, nodes); err != nil {
+		return nil, "NoMatchingNodes", err
+	}
+	return placement, "", nil
+}
+
+func validatePlacement(placement *Placement) error {
+	if errs := metav1validation.ValidateLabels(placement.NodeSelector, field.NewPath("nodeSelector")); len(errs) > 0 {
+		return errs.ToAggregate()
+	}
+	for _, toleration := range placement.Tolerations {
+		switch toleration.Operator {
+		case corev1.TolerationOpExists:
+			if len(toleration.Value) != 0 {
+				return fmt.Errorf("toleration %q with the Exists operator must not have a value", toleration.Key)
+			}
+		case corev1.TolerationOpEqual, "":
+			if len(toleration.Key) == 0 {
+				return fmt.Errorf("toleration without a key must use the Exists operator")
+			}
+		default:
+			return fmt.Errorf("toleration %q has an unsupported operator %q", toleration.Key, toleration.Operator)
+		}
+	}
+	if len(placement.PriorityClassName) != 0 {
+		if errs := validation.IsDNS1123Subdomain(placement.PriorityClassName); len(errs) > 0 {
+			return fmt.Errorf("invalid priorityClassName %q: %s", placement.PriorityClassName, strings.Join(errs, ", "))
+		}
+	}
+	return nil
+}
+
+// validatePlacementNodes checks that once placed, the pods of the console or downloads
+// deployment can be scheduled on at least one node.
+func validatePlacementNodes(deploymentName string, placement *Placement, infrastructureConfig *configv1.Infrastructure, nodes []*corev1.Node) error {
+	asset := "assets/deployments/downloads-deployment.yaml"
+	if deploymentName == api.OpenShiftConsoleDeploymentName {
+		asset = "assets/deployments/console-deployment.yaml"
+	}
+	deployment := resourceread.ReadDeploymentV1OrDie(bindata.MustAsset(asset))
+	if deploymentName == api.OpenShiftConsoleDeploymentName {
+		withConsoleNodeSelector(deployment, infrastructureConfig)
+	}
+	withPlacement(deployment, placement)
+
+	podSpec := deployment.Spec.Template.Spec
+	selector := labels.SelectorFromSet(podSpec.NodeSelector)
+	for _, node := range nodes {
+		if node.Spec.Unschedulable || !selector.Matches(labels.Set(node.Labels)) {
+			continue
+		}
+		if toleratesTaints(podSpec.Tolerations, node.Spec.Taints) {
+			return nil
+		}
+	}
+	return fmt.Errorf("no schedulable node matches the %s node selector %q and tolerations", deploymentName, selector.String())
+}
+
+func toleratesTaints(tolerations []corev1.Toleration, taints []corev1.Taint) bool {
+	for i := range taints {
+		if taints[i].Effect == corev1.TaintEffectPreferNoSchedule {
+			continue
+		}
+		tolerated := false
+		for j := range tolerations {
+			if tolerations[j].ToleratesTaint(&taints[i]) {
+				tolerated = true
+				break
+			}
+		}
+		if !tolerated {
+			return false
+		}
+	}
+	return true
+}
+
+// withPlacement pins the pods to the nodes of the placement.
+func withPlacement(deployment *appsv1.Deployment, placement *Placement) {
+	if placement == nil {
+		return
+	}
+	podSpec := &deployment.Spec.Template.Spec
+	if placement.NodeSelector != nil {
+		podSpec.NodeSelector = placement.NodeSelector
+	}
+	podSpec.Tolerations = append(podSpec.Tolerations, placement.Tolerations...)
+	if len(placement.PriorityClassName) != 0 {
+		podSpec.PriorityClassName = placement.PriorityClassName
+	}
+}
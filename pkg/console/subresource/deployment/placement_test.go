@@ -0,0 +1,166 @@
+package deployment
+
+import (
+	"testing"
+
+	"github.com/go-test/deep"
+
+	corev1 "k8s.io/api/core/v1"
+	schedulingv1 "k8s.io/api/scheduling/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	corev1listers "k8s.io/client-go/listers/core/v1"
+	schedulingv1listers "k8s.io/client-go/listers/scheduling/v1"
+	"k8s.io/client-go/tools/cache"
+
+	configv1 "github.com/openshift/api/config/v1"
+	operatorsv1 "github.com/openshift/api/operator/v1"
+
+	"github.com/openshift/console-operator/pkg/api"
+)
+
+const infraPlacement = `{"console":{"nodeSelector":{"node-role.kubernetes.io/infra":""},"tolerations":[{"key":"node-role.kubernetes.io/infra","operator":"Exists","effect":"NoSchedule"}],"priorityClassName":"console-critical"}}`
+
+func placementConfigMapLister(t *testing.T, configMaps ...*corev1.ConfigMap) corev1listers.ConfigMapLister {
+	indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
+	for _, configMap := range configMaps {
+		if err := indexer.Add(configMap); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return corev1listers.NewConfigMapLister(indexer)
+}
+
+func priorityClassLister(t *testing.T, names ...string) schedulingv1listers.PriorityClassLister {
+	indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+	for _, name := range names {
+		if err := indexer.Add(&schedulingv1.PriorityClass{ObjectMeta: metav1.ObjectMeta{Name: name}}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return schedulingv1listers.NewPriorityClassLister(indexer)
+}
+
+func infraNode(name string, unschedulable bool) *corev1.Node {
+	return &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Labels: map[string]string{"node-role.kubernetes.io/infra": ""}},
+		Spec: corev1.NodeSpec{
+			Unschedulable: unschedulable,
+			Taints:        []corev1.Taint{{Key: "node-role.kubernetes.io/infra", Effect: corev1.TaintEffectNoSchedule}},
+		},
+	}
+}
+
+func TestGetSchedulablePlacement(t *testing.T) {
+	placementConfigMap := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "console-placement", Namespace: api.OpenShiftConfigNamespace},
+		Data:       map[string]string{PlacementConfigMapKey: infraPlacement},
+	}
+	lister := placementConfigMapLister(t, placementConfigMap)
+	priorityClasses := priorityClassLister(t, "console-critical")
+	infrastructureConfig := &configv1.Infrastructure{}
+	infraNodes := []*corev1.Node{infraNode("infra-0", false)}
+
+	tests := []struct {
+		name           string
+		annotations    map[string]string
+		deploymentName string
+		nodes          []*corev1.Node
+		wantPlaced     bool
+		wantReason     string
+	}{
+		{
+			name:           "Default placement",
+			deploymentName: api.OpenShiftConsoleDeploymentName,
+			nodes:          infraNodes,
+		},
+		{
+			name:           "Inline placement",
+			annotations:    map[string]string{PlacementAnnotation: infraPlacement},
+			deploymentName: api.OpenShiftConsoleDeploymentName,
+			nodes:          infraNodes,
+			wantPlaced:     true,
+		},
+		{
+			name:           "Referenced placement",
+			annotations:    map[string]string{PlacementConfigMapAnnotation: "console-placement"},
+			deploymentName: api.OpenShiftConsoleDeploymentName,
+			nodes:          infraNodes,
+			wantPlaced:     true,
+		},
+		{
+			name:           "Placement of the other deployment",
+			annotations:    map[string]string{PlacementAnnotation: infraPlacement},
+			deploymentName: api.OpenShiftConsoleDownloadsDeploymentName,
+			nodes:          infraNodes,
+		},
+		{
+			name:           "Missing config map",
+			annotations:    map[string]string{PlacementConfigMapAnnotation: "missing"},
+			deploymentName: api.OpenShiftConsoleDeploymentName,
+			wantReason:     "FailedGetPlacementConfigMap",
+		},
+		{
+			name:           "Invalid toleration",
+			annotations:    map[string]string{PlacementAnnotation: `{"console":{"tolerations":[{"key":"infra","operator":"Exists","value":"true"}]}}`},
+			deploymentName: api.OpenShiftConsoleDeploymentName,
+			wantReason:     "InvalidPlacement",
+		},
+		{
+			name:           "Unknown deployment",
+			annotations:    map[string]string{PlacementAnnotation: `{"console-ui":{}}`},
+			deploymentName: api.OpenShiftConsoleDeploymentName,
+			wantReason:     "InvalidPlacement",
+		},
+		{
+			name:           "Missing priority class",
+			annotations:    map[string]string{PlacementAnnotation: `{"console":{"priorityClassName":"missing"}}`},
+			deploymentName: api.OpenShiftConsoleDeploymentName,
+			nodes:          infraNodes,
+			wantReason:     "MissingPriorityClass",
+		},
+		{
+			name:           "Cordoned nodes",
+			annotations:    map[string]string{PlacementAnnotation: infraPlacement},
+			deploymentName: api.OpenShiftConsoleDeploymentName,
+			nodes:          []*corev1.Node{infraNode("infra-0", true)},
+			wantReason:     "NoMatchingNodes",
+		},
+		{
+			name:           "Untolerated taint",
+			annotations:    map[string]string{PlacementAnnotation: `{"console":{"nodeSelector":{"node-role.kubernetes.io/infra":""}}}`},
+			deploymentName: api.OpenShiftConsoleDeploymentName,
+			nodes:          infraNodes,
+			wantReason:     "NoMatchingNodes",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			operatorConfig := &operatorsv1.Console{ObjectMeta: metav1.ObjectMeta{Annotations: tt.annotations}}
+			placement, reason, err := GetSchedulablePlacement(operatorConfig, lister, priorityClasses, tt.deploymentName, infrastructureConfig, tt.nodes)
+			if (placement != nil) != tt.wantPlaced {
+				t.Errorf("placement = %v, wantPlaced %v", placement, tt.wantPlaced)
+			}
+			if reason != tt.wantReason || (err != nil) != (tt.wantReason != "") {
+				t.Errorf("reason = %q, error = %v, want reason %q", reason, err, tt.wantReason)
+			}
+		})
+	}
+}
+
+func TestWithPlacement(t *testing.T) {
+	placement, _, err := GetPlacement(&operatorsv1.Console{ObjectMeta: metav1.ObjectMeta{Annotations: map[string]string{PlacementAnnotation: infraPlacement}}}, nil, api.OpenShiftConsoleDeploymentName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	deployment := DefaultDeployment(&operatorsv1.Console{}, &corev1.ConfigMap{}, &corev1.ConfigMap{}, nil, nil, &corev1.ConfigMap{}, &corev1.Secret{}, nil, &configv1.Proxy{}, &configv1.Infrastructure{}, nil, nil, placement, false)
+	podSpec := deployment.Spec.Template.Spec
+	if diff := deep.Equal(podSpec.NodeSelector, map[string]string{"node-role.kubernetes.io/infra": ""}); diff != nil {
+		t.Error(diff)
+	}
+	if podSpec.PriorityClassName != "console-critical" {
+		t.Errorf("priorityClassName = %q, want console-critical", podSpec.PriorityClassName)
+	}
+	if lastToleration := podSpec.Tolerations[len(podSpec.Tolerations)-1]; lastToleration.Key != "node-role.kubernetes.io/infra" {
+		t.Errorf("expected the infra toleration to be added to the default ones, got %v", podSpec.Tolerations)
+	}
+}
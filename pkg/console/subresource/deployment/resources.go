@@ -0,0 +1,259 @@
+package deployment
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+
+	// kube
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/klog/v2"
+
+	// openshift
+	operatorv1 "github.com/openshift/api/operator/v1"
+	"github.com/openshift/console-operator/bindata"
+	"github.com/openshift/console-operator/pkg/api"
+	hpasub "github.com/openshift/console-operator/pkg/console/subresource/hpa"
+	"github.com/openshift/console-operator/pkg/console/subresource/util"
+	"github.com/openshift/library-go/pkg/operator/resource/resourceread"
+)
+
+const (
+	// ContainerResourcesAnnotation is set on the operator config to a JSON map of
+	// container name to ResourceRequirements, overriding the requests and limits of the
+	// console and download-server containers.
+	ContainerResourcesAnnotation = "console.openshift.io/container-resources"
+	// ResourceAutoSizingAnnotation is set to "true" on the operator config for the
+	// requests to follow the observed usage of the pods.
+	ResourceAutoSizingAnnotation = "console.openshift.io/resource-auto-sizing"
+)
+
+const (
+	// requests are sized with headroom above the observed peak usage
+	autoSizingHeadroom = 1.25
+	// a recommendation is raised when the usage outgrows it by this ratio
+	autoSizingRaiseThreshold = 1.1
+	// a recommendation is lowered when the usage falls below this ratio of it, by
+	// autoSizingMaxDecrease at most for the pods not to be rolled out on every dip
+	autoSizingLowerThreshold = 0.7
+	autoSizingMaxDecrease    = 0.9
+)
+
+var (
+	sizedContainers = []string{api.ConsoleContainerName, api.DownloadsContainerName}
+	sizedResources  = []corev1.ResourceName{corev1.ResourceCPU, corev1.ResourceMemory}
+)
+
+// GetContainerResources reads the container resources set on the operator config. Only
+// the cpu and memory of the console and download-server containers can be set, and
+// requests must not exceed limits.
+func GetContainerResources(operatorConfig *operatorv1.Console) (map[string]corev1.ResourceRequirements, error) {
+	value, ok := operatorConfig.Annotations[ContainerResourcesAnnotation]
+	if !ok {
+		return nil, nil
+	}
+	containerResources := map[string]corev1.ResourceRequirements{}
+	if err := json.Unmarshal([]byte(value), &containerResources); err != nil {
+		return nil, fmt.Errorf("invalid %s annotation: %w", ContainerResourcesAnnotation, err)
+	}
+	for containerName, requirements := range containerResources {
+		if !isSizedContainer(containerName) {
+			return nil, fmt.Errorf("invalid %s annotation: unknown container %q", ContainerResourcesAnnotation, containerName)
+		}
+		if err := validateResourceList(requirements.Requests); err != nil {
+			return nil, fmt.Errorf("invalid %s requests: %w", containerName, err)
+		}
+		if err := validateResourceList(requirements.Limits); err != nil {
+			return nil, fmt.Errorf("invalid %s limits: %w", containerName, err)
+		}
+		for name, request := range requirements.Requests {
+			if limit, ok := requirements.Limits[name]; ok && request.Cmp(limit) > 0 {
+				return nil, fmt.Errorf("invalid %s resources: %s request %s exceeds limit %s", containerName, name, request.String(), limit.String())
+			}
+		}
+	}
+	return containerResources, nil
+}
+
+func validateResourceList(resources corev1.ResourceList) error {
+	for name, quantity := range resources {
+		if name != corev1.ResourceCPU && name != corev1.ResourceMemory {
+			return fmt.Errorf("unsupported resource %q", name)
+		}
+		if quantity.Sign() < 0 {
+			return fmt.Errorf("%s must not be negative", name)
+		}
+	}
+	return nil
+}
+
+func isSizedContainer(containerName string) bool {
+	for _, name := range sizedContainers {
+		if name == containerName {
+			return true
+		}
+	}
+	return false
+}
+
+func IsResourceAutoSizingEnabled(operatorConfig *operatorv1.Console) bool {
+	enabled, _ := strconv.ParseBool(operatorConfig.Annotations[ResourceAutoSizingAnnotation])
+	return enabled
+}
+
+// ResourceAutoSizingConflict returns an error when the auto-sizing is enabled along with
+// the console autoscaling. The autoscaler scales on the usage relative to the requests,
+// requests that follow the usage would keep it from ever scaling, so the auto-sizing is
+// rejected in that case.
+func ResourceAutoSizingConflict(operatorConfig *operatorv1.Console) error {
+	if !IsResourceAutoSizingEnabled(operatorConfig) || hpasub.GetValidAutoscaling(operatorConfig) == nil {
+		return nil
+	}
+	return fmt.Errorf("%s cannot be enabled along with %s, the requests would follow the usage the autoscaler scales on", ResourceAutoSizingAnnotation, hpasub.AutoscalingAnnotation)
+}
+
+// DefaultContainerRequests returns the requests the console and download-server
+// containers are deployed with when nothing else is set.
+func DefaultContainerRequests() map[string]corev1.ResourceList {
+	defaults := map[string]corev1.ResourceList{}
+	for _, asset := range []string{"assets/deployments/console-deployment.yaml", "assets/deployments/downloads-deployment.yaml"} {
+		deployment := resourceread.ReadDeploymentV1OrDie(bindata.MustAsset(asset))
+		for _, container := range deployment.Spec.Template.Spec.Containers {
+			defaults[container.Name] = container.Resources.Requests
+		}
+	}
+	return defaults
+}
+
+// GetResourceRecommendations reads the requests recommended by the auto-sizing from the
+// resource sizing config map, per container name.
+func GetResourceRecommendations(resourceSizingConfigMap *corev1.ConfigMap) map[string]corev1.ResourceList {
+	recommendations := map[string]corev1.ResourceList{}
+	if resourceSizingConfigMap == nil {
+		return recommendations
+	}
+	for containerName, value := range resourceSizingConfigMap.Data {
+		recommendation := corev1.ResourceList{}
+		if err := json.Unmarshal([]byte(value), &recommendation); err != nil {
+			klog.Errorf("ignoring the %s resource recommendation: %v", containerName, err)
+			continue
+		}
+		recommendations[containerName] = recommendation
+	}
+	return recommendations
+}
+
+// DefaultResourceSizingConfigMap stores the requests recommended by the auto-sizing.
+func DefaultResourceSizingConfigMap(operatorConfig *operatorv1.Console, recommendations map[string]corev1.ResourceList) (*corev1.ConfigMap, error) {
+	configMap := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      api.ResourceSizingConfigMapName,
+			Namespace: api.OpenShiftConsoleNamespace,
+			Labels:    util.LabelsForConsole(),
+		},
+		Data: map[string]string{},
+	}
+	for containerName, recommendation := range recommendations {
+		value, err := json.Marshal(recommendation)
+		if err != nil {
+			return nil, err
+		}
+		configMap.Data[containerName] = string(value)
+	}
+	util.AddOwnerRef(configMap, util.OwnerRefFrom(operatorConfig))
+	return configMap, nil
+}
+
+// RecommendRequests derives the requests of a container from the usage of its pods: the
+// peak usage with headroom, never below the default requests of the container. The
+// current recommendation is kept while the usage stays close to it.
+func RecommendRequests(usage []corev1.ResourceList, defaults corev1.ResourceList, current corev1.ResourceList) corev1.ResourceList {
+	recommendation := corev1.ResourceList{}
+	for _, name := range sizedResources {
+		peak := resource.Quantity{}
+		for _, podUsage := range usage {
+			if quantity, ok := podUsage[name]; ok && quantity.Cmp(peak) > 0 {
+				peak = quantity
+			}
+		}
+		target := scaleQuantity(peak, autoSizingHeadroom, name)
+		if currentRequest, ok := current[name]; ok {
+			target = dampenRecommendation(target, currentRequest, name)
+		}
+		if defaultRequest, ok := defaults[name]; ok && target.Cmp(defaultRequest) < 0 {
+			target = defaultRequest.DeepCopy()
+		}
+		recommendation[name] = target
+	}
+	return recommendation
+}
+
+func dampenRecommendation(target, current resource.Quantity, name corev1.ResourceName) resource.Quantity {
+	switch {
+	case target.Cmp(scaleQuantity(current, autoSizingRaiseThreshold, name)) > 0:
+		return target
+	case target.Cmp(scaleQuantity(current, autoSizingLowerThreshold, name)) < 0:
+		lowest := scaleQuantity(current, autoSizingMaxDecrease, name)
+		if target.Cmp(lowest) < 0 {
+			return lowest
+		}
+		return target
+	default:
+		return current.DeepCopy()
+	}
+}
+
+// scaleQuantity multiplies a quantity, rounding cpu up to the millicore and memory up to
+// the mebibyte.
+func scaleQuantity(quantity resource.Quantity, factor float64, name corev1.ResourceName) resource.Quantity {
+	if name == corev1.ResourceCPU {
+		return *resource.NewMilliQuantity(int64(math.Ceil(float64(quantity.MilliValue())*factor)), resource.DecimalSI)
+	}
+	mebibytes := int64(math.Ceil(float64(quantity.Value()) * factor / (1 << 20)))
+	return *resource.NewQuantity(mebibytes<<20, resource.BinarySI)
+}
+
+// withResources sets the requests and limits of the containers. The requests recommended
+// by the auto-sizing raise the default requests, the requests set on the operator config
+// are kept as a minimum. Requests never exceed the limits set on the operator config.
+func withResources(deployment *appsv1.Deployment, operatorConfig *operatorv1.Console, resourceSizingConfigMap *corev1.ConfigMap) {
+	containerResources, err := GetContainerResources(operatorConfig)
+	if err != nil {
+		klog.Errorf("using the default container resources: %v", err)
+	}
+	recommendations := map[string]corev1.ResourceList{}
+	if IsResourceAutoSizingEnabled(operatorConfig) {
+		recommendations = GetResourceRecommendations(resourceSizingConfigMap)
+	}
+
+	for i := range deployment.Spec.Template.Spec.Containers {
+		container := &deployment.Spec.Template.Spec.Containers[i]
+		requirements := container.Resources.DeepCopy()
+		if requirements.Requests == nil {
+			requirements.Requests = corev1.ResourceList{}
+		}
+		for name, quantity := range recommendations[container.Name] {
+			requirements.Requests[name] = quantity
+		}
+		if configured, ok := containerResources[container.Name]; ok {
+			for name, quantity := range configured.Requests {
+				if recommended, ok := recommendations[container.Name][name]; !ok || quantity.Cmp(recommended) > 0 {
+					requirements.Requests[name] = quantity
+				}
+			}
+			if len(configured.Limits) != 0 {
+				requirements.Limits = configured.Limits.DeepCopy()
+			}
+		}
+		for name, limit := range requirements.Limits {
+			if request, ok := requirements.Requests[name]; ok && request.Cmp(limit) > 0 {
+				requirements.Requests[name] = limit.DeepCopy()
+			}
+		}
+		container.Resources = *requirements
+	}
+}
@@ -0,0 +1,215 @@
+package deployment
+
+import (
+	"testing"
+
+	"github.com/go-test/deep"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	configv1 "github.com/openshift/api/config/v1"
+	operatorv1 "github.com/openshift/api/operator/v1"
+
+	"github.com/openshift/console-operator/pkg/api"
+	hpasub "github.com/openshift/console-operator/pkg/console/subresource/hpa"
+)
+
+func TestGetContainerResources(t *testing.T) {
+	tests := []struct {
+		name       string
+		annotation string
+		wantErr    bool
+	}{
+		{
+			name:       "Requests and limits",
+			annotation: `{"console":{"requests":{"cpu":"100m","memory":"512Mi"},"limits":{"memory":"1Gi"}}}`,
+		},
+		{
+			name:       "Invalid quantity",
+			annotation: `{"console":{"requests":{"memory":"lots"}}}`,
+			wantErr:    true,
+		},
+		{
+			name:       "Unknown container",
+			annotation: `{"sidecar":{"requests":{"cpu":"100m"}}}`,
+			wantErr:    true,
+		},
+		{
+			name:       "Unsupported resource",
+			annotation: `{"download-server":{"requests":{"nvidia.com/gpu":"1"}}}`,
+			wantErr:    true,
+		},
+		{
+			name:       "Request above limit",
+			annotation: `{"console":{"requests":{"memory":"2Gi"},"limits":{"memory":"1Gi"}}}`,
+			wantErr:    true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			operatorConfig := &operatorv1.Console{ObjectMeta: metav1.ObjectMeta{Annotations: map[string]string{ContainerResourcesAnnotation: tt.annotation}}}
+			if _, err := GetContainerResources(operatorConfig); (err != nil) != tt.wantErr {
+				t.Errorf("GetContainerResources() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestResourceAutoSizingConflict(t *testing.T) {
+	tests := []struct {
+		name        string
+		annotations map[string]string
+		wantErr     bool
+	}{
+		{
+			name:        "Auto-sizing alone",
+			annotations: map[string]string{ResourceAutoSizingAnnotation: "true"},
+		},
+		{
+			name:        "Autoscaling alone",
+			annotations: map[string]string{hpasub.AutoscalingAnnotation: `{"minReplicas":2,"maxReplicas":6}`},
+		},
+		{
+			name: "Auto-sizing along with autoscaling",
+			annotations: map[string]string{
+				ResourceAutoSizingAnnotation: "true",
+				hpasub.AutoscalingAnnotation: `{"minReplicas":2,"maxReplicas":6}`,
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			operatorConfig := &operatorv1.Console{ObjectMeta: metav1.ObjectMeta{Annotations: tt.annotations}}
+			if err := ResourceAutoSizingConflict(operatorConfig); (err != nil) != tt.wantErr {
+				t.Errorf("ResourceAutoSizingConflict() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestDeploymentResources(t *testing.T) {
+	infrastructureConfig := &configv1.Infrastructure{}
+	resourceSizingConfigMap := &corev1.ConfigMap{
+		Data: map[string]string{
+			api.ConsoleContainerName:   `{"cpu":"250m","memory":"1536Mi"}`,
+			api.DownloadsContainerName: `{"memory":"80Mi"}`,
+		},
+	}
+	tests := []struct {
+		name          string
+		annotations   map[string]string
+		wantConsole   corev1.ResourceRequirements
+		wantDownloads corev1.ResourceRequirements
+	}{
+		{
+			name: "Defaults",
+			wantConsole: corev1.ResourceRequirements{
+				Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("10m"), corev1.ResourceMemory: resource.MustParse("100Mi")},
+			},
+			wantDownloads: corev1.ResourceRequirements{
+				Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("10m"), corev1.ResourceMemory: resource.MustParse("50Mi")},
+			},
+		},
+		{
+			name: "Configured resources",
+			annotations: map[string]string{
+				ContainerResourcesAnnotation: `{"console":{"requests":{"memory":"512Mi"},"limits":{"memory":"1Gi"}}}`,
+			},
+			wantConsole: corev1.ResourceRequirements{
+				Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("10m"), corev1.ResourceMemory: resource.MustParse("512Mi")},
+				Limits:   corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("1Gi")},
+			},
+			wantDownloads: corev1.ResourceRequirements{
+				Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("10m"), corev1.ResourceMemory: resource.MustParse("50Mi")},
+			},
+		},
+		{
+			name: "Auto-sizing capped by limits",
+			annotations: map[string]string{
+				ResourceAutoSizingAnnotation: "true",
+				ContainerResourcesAnnotation: `{"console":{"requests":{"cpu":"500m"},"limits":{"memory":"1Gi"}}}`,
+			},
+			wantConsole: corev1.ResourceRequirements{
+				Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("500m"), corev1.ResourceMemory: resource.MustParse("1Gi")},
+				Limits:   corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("1Gi")},
+			},
+			wantDownloads: corev1.ResourceRequirements{
+				Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("10m"), corev1.ResourceMemory: resource.MustParse("80Mi")},
+			},
+		},
+		{
+			name: "Invalid resources fall back to the defaults",
+			annotations: map[string]string{
+				ContainerResourcesAnnotation: `{"console":{"requests":{"memory":"2Gi"},"limits":{"memory":"1Gi"}}}`,
+			},
+			wantConsole: corev1.ResourceRequirements{
+				Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("10m"), corev1.ResourceMemory: resource.MustParse("100Mi")},
+			},
+			wantDownloads: corev1.ResourceRequirements{
+				Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("10m"), corev1.ResourceMemory: resource.MustParse("50Mi")},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			operatorConfig := &operatorv1.Console{ObjectMeta: metav1.ObjectMeta{Annotations: tt.annotations}}
+			consoleDeployment := DefaultDeployment(operatorConfig, &corev1.ConfigMap{}, &corev1.ConfigMap{}, nil, nil, &corev1.ConfigMap{}, &corev1.Secret{}, nil, &configv1.Proxy{}, infrastructureConfig, resourceSizingConfigMap, nil, nil, false)
+			if diff := deep.Equal(consoleDeployment.Spec.Template.Spec.Containers[0].Resources, tt.wantConsole); diff != nil {
+				t.Errorf("console: %v", diff)
+			}
+			downloadsDeployment := DefaultDownloadsDeployment(operatorConfig, infrastructureConfig, resourceSizingConfigMap, nil, nil)
+			if diff := deep.Equal(downloadsDeployment.Spec.Template.Spec.Containers[0].Resources, tt.wantDownloads); diff != nil {
+				t.Errorf("downloads: %v", diff)
+			}
+		})
+	}
+}
+
+func TestRecommendRequests(t *testing.T) {
+	defaults := corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("10m"), corev1.ResourceMemory: resource.MustParse("100Mi")}
+	usage := func(cpu, memory string) corev1.ResourceList {
+		return corev1.ResourceList{corev1.ResourceCPU: resource.MustParse(cpu), corev1.ResourceMemory: resource.MustParse(memory)}
+	}
+	tests := []struct {
+		name    string
+		usage   []corev1.ResourceList
+		current corev1.ResourceList
+		want    corev1.ResourceList
+	}{
+		{
+			name:  "Peak usage with headroom",
+			usage: []corev1.ResourceList{usage("80m", "400Mi"), usage("200m", "800Mi")},
+			want:  usage("250m", "1000Mi"),
+		},
+		{
+			name:  "Never below the defaults",
+			usage: []corev1.ResourceList{usage("1m", "20Mi")},
+			want:  defaults,
+		},
+		{
+			name:    "Current recommendation kept while close to the usage",
+			usage:   []corev1.ResourceList{usage("180m", "720Mi")},
+			current: usage("250m", "1000Mi"),
+			want:    usage("250m", "1000Mi"),
+		},
+		{
+			name:    "Recommendation lowered gradually",
+			usage:   []corev1.ResourceList{usage("20m", "200Mi")},
+			current: usage("250m", "1000Mi"),
+			want:    usage("225m", "900Mi"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := RecommendRequests(tt.usage, defaults, tt.current)
+			for name, want := range tt.want {
+				if quantity := got[name]; quantity.Cmp(want) != 0 {
+					t.Errorf("%s = %s, want %s", name, quantity.String(), want.String())
+				}
+			}
+		})
+	}
+}
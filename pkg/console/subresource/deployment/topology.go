@@ -0,0 +1,95 @@
+package deployment
+
+import (
+	"fmt"
+
+	// kube
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/klog/v2"
+
+	// openshift
+	operatorv1 "github.com/openshift/api/operator/v1"
+)
+
+// ZoneSpreadAnnotation is set on the operator config to the way the console and
+// downloads pods are spread across zones: Soft, the default, Hard or Disabled.
+const ZoneSpreadAnnotation = "console.openshift.io/zone-spread"
+
+type ZoneSpread string
+
+const (
+	ZoneSpreadSoft     ZoneSpread = "Soft"
+	ZoneSpreadHard     ZoneSpread = "Hard"
+	ZoneSpreadDisabled ZoneSpread = "Disabled"
+)
+
+// GetZoneSpread reads the zone spreading set on the operator config.
+func GetZoneSpread(operatorConfig *operatorv1.Console) (ZoneSpread, error) {
+	value, ok := operatorConfig.Annotations[ZoneSpreadAnnotation]
+	if !ok {
+		return ZoneSpreadSoft, nil
+	}
+	switch zoneSpread := ZoneSpread(value); zoneSpread {
+	case ZoneSpreadSoft, ZoneSpreadHard, ZoneSpreadDisabled:
+		return zoneSpread, nil
+	default:
+		return ZoneSpreadSoft, fmt.Errorf("invalid %s annotation %q, must be one of %s, %s or %s", ZoneSpreadAnnotation, value, ZoneSpreadSoft, ZoneSpreadHard, ZoneSpreadDisabled)
+	}
+}
+
+// GetNodeZones returns the zones of the nodes matching a node selector.
+func GetNodeZones(nodes []*corev1.Node, nodeSelector map[string]string) []string {
+	selector := labels.SelectorFromSet(nodeSelector)
+	zones := sets.New[string]()
+	for _, node := range nodes {
+		if !selector.Matches(labels.Set(node.Labels)) {
+			continue
+		}
+		if zone := node.Labels[corev1.LabelTopologyZone]; zone != "" {
+			zones.Insert(zone)
+		}
+	}
+	return sets.List(zones)
+}
+
+// withTopologySpread spreads the pods across the zones of the nodes they can run on,
+// when there is more than one. The pods of each ReplicaSet are spread on their own, for
+// the pods surged during a rollout not to be held back by the ones they replace.
+func withTopologySpread(
+	deployment *appsv1.Deployment,
+	operatorConfig *operatorv1.Console,
+	nodes []*corev1.Node,
+	component string,
+) {
+	zoneSpread, err := GetZoneSpread(operatorConfig)
+	if err != nil {
+		klog.Errorf("using the %s zone spread: %v", zoneSpread, err)
+	}
+	if zoneSpread == ZoneSpreadDisabled {
+		return
+	}
+	if len(GetNodeZones(nodes, deployment.Spec.Template.Spec.NodeSelector)) < 2 {
+		return
+	}
+
+	whenUnsatisfiable := corev1.ScheduleAnyway
+	if zoneSpread == ZoneSpreadHard {
+		whenUnsatisfiable = corev1.DoNotSchedule
+	}
+	deployment.Spec.Template.Spec.TopologySpreadConstraints = []corev1.TopologySpreadConstraint{{
+		MaxSkew:           1,
+		TopologyKey:       corev1.LabelTopologyZone,
+		WhenUnsatisfiable: whenUnsatisfiable,
+		LabelSelector: &metav1.LabelSelector{
+			MatchLabels: map[string]string{
+				"app":       "console",
+				"component": component,
+			},
+		},
+		MatchLabelKeys: []string{appsv1.DefaultDeploymentUniqueLabelKey},
+	}}
+}
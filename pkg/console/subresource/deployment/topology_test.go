@@ -0,0 +1,106 @@
+package deployment
+
+import (
+	"testing"
+
+	"github.com/go-test/deep"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	operatorsv1 "github.com/openshift/api/operator/v1"
+)
+
+func zonedNode(name string, zone string, master bool) *corev1.Node {
+	nodeLabels := map[string]string{"kubernetes.io/os": "linux"}
+	if zone != "" {
+		nodeLabels[corev1.LabelTopologyZone] = zone
+	}
+	if master {
+		nodeLabels["node-role.kubernetes.io/master"] = ""
+	}
+	return &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: name, Labels: nodeLabels}}
+}
+
+func TestGetNodeZones(t *testing.T) {
+	nodes := []*corev1.Node{
+		zonedNode("master-0", "us-east-1a", true),
+		zonedNode("master-1", "us-east-1a", true),
+		zonedNode("worker-0", "us-east-1b", false),
+		zonedNode("worker-1", "", false),
+	}
+	if diff := deep.Equal(GetNodeZones(nodes, map[string]string{"node-role.kubernetes.io/master": ""}), []string{"us-east-1a"}); diff != nil {
+		t.Error(diff)
+	}
+	if diff := deep.Equal(GetNodeZones(nodes, map[string]string{"kubernetes.io/os": "linux"}), []string{"us-east-1a", "us-east-1b"}); diff != nil {
+		t.Error(diff)
+	}
+}
+
+func TestWithTopologySpread(t *testing.T) {
+	multiZoneNodes := []*corev1.Node{
+		zonedNode("master-0", "us-east-1a", true),
+		zonedNode("master-1", "us-east-1b", true),
+		zonedNode("master-2", "us-east-1c", true),
+	}
+	singleZoneNodes := []*corev1.Node{
+		zonedNode("master-0", "us-east-1a", true),
+		zonedNode("master-1", "us-east-1a", true),
+	}
+	tests := []struct {
+		name        string
+		annotations map[string]string
+		nodes       []*corev1.Node
+		want        []corev1.TopologySpreadConstraint
+	}{
+		{
+			name:  "Soft spread across zones",
+			nodes: multiZoneNodes,
+			want:  []corev1.TopologySpreadConstraint{zoneSpreadConstraint(corev1.ScheduleAnyway)},
+		},
+		{
+			name:        "Hard spread across zones",
+			annotations: map[string]string{ZoneSpreadAnnotation: string(ZoneSpreadHard)},
+			nodes:       multiZoneNodes,
+			want:        []corev1.TopologySpreadConstraint{zoneSpreadConstraint(corev1.DoNotSchedule)},
+		},
+		{
+			name:        "Disabled spread",
+			annotations: map[string]string{ZoneSpreadAnnotation: string(ZoneSpreadDisabled)},
+			nodes:       multiZoneNodes,
+		},
+		{
+			name:        "Invalid spread falls back to soft",
+			annotations: map[string]string{ZoneSpreadAnnotation: "Strict"},
+			nodes:       multiZoneNodes,
+			want:        []corev1.TopologySpreadConstraint{zoneSpreadConstraint(corev1.ScheduleAnyway)},
+		},
+		{
+			name:  "Single zone",
+			nodes: singleZoneNodes,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			deployment := &appsv1.Deployment{}
+			deployment.Spec.Template.Spec.NodeSelector = map[string]string{"node-role.kubernetes.io/master": ""}
+			withTopologySpread(deployment, &operatorsv1.Console{ObjectMeta: metav1.ObjectMeta{Annotations: tt.annotations}}, tt.nodes, "ui")
+			if diff := deep.Equal(deployment.Spec.Template.Spec.TopologySpreadConstraints, tt.want); diff != nil {
+				t.Error(diff)
+			}
+		})
+	}
+}
+
+func zoneSpreadConstraint(whenUnsatisfiable corev1.UnsatisfiableConstraintAction) corev1.TopologySpreadConstraint {
+	return corev1.TopologySpreadConstraint{
+		MaxSkew:           1,
+		TopologyKey:       corev1.LabelTopologyZone,
+		WhenUnsatisfiable: whenUnsatisfiable,
+		LabelSelector: &metav1.LabelSelector{
+			MatchLabels: map[string]string{"app": "console", "component": "ui"},
+		},
+		MatchLabelKeys: []string{appsv1.DefaultDeploymentUniqueLabelKey},
+	}
+}
@@ -0,0 +1,156 @@
+package hpa
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	// kube
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	autoscalingclientv2 "k8s.io/client-go/kubernetes/typed/autoscaling/v2"
+	"k8s.io/klog/v2"
+
+	// openshift
+	operatorv1 "github.com/openshift/api/operator/v1"
+	"github.com/openshift/library-go/pkg/operator/resource/resourcemerge"
+
+	// console-operator
+	"github.com/openshift/console-operator/pkg/api"
+	"github.com/openshift/console-operator/pkg/console/subresource/util"
+)
+
+// AutoscalingAnnotation is set on the operator config to a JSON Autoscaling for the
+// console deployment to be scaled by a HorizontalPodAutoscaler instead of running a
+// fixed number of replicas.
+const AutoscalingAnnotation = "console.openshift.io/autoscaling"
+
+// DefaultTargetCPUUtilizationPercentage is the CPU target of the HPA when no target is set.
+const DefaultTargetCPUUtilizationPercentage = 75
+
+// Autoscaling bounds the replicas of the console deployment. The utilization targets
+// are percentages of the container requests.
+type Autoscaling struct {
+	MinReplicas                       int32  `json:"minReplicas"`
+	MaxReplicas                       int32  `json:"maxReplicas"`
+	TargetCPUUtilizationPercentage    *int32 `json:"targetCPUUtilizationPercentage,omitempty"`
+	TargetMemoryUtilizationPercentage *int32 `json:"targetMemoryUtilizationPercentage,omitempty"`
+}
+
+// GetAutoscaling reads the autoscaling set on the operator config, nil when the console
+// runs a fixed number of replicas.
+func GetAutoscaling(operatorConfig *operatorv1.Console) (*Autoscaling, error) {
+	value, ok := operatorConfig.Annotations[AutoscalingAnnotation]
+	if !ok {
+		return nil, nil
+	}
+	autoscaling := &Autoscaling{}
+	if err := json.Unmarshal([]byte(value), autoscaling); err != nil {
+		return nil, fmt.Errorf("invalid %s annotation: %w", AutoscalingAnnotation, err)
+	}
+	if autoscaling.MinReplicas < 1 {
+		return nil, fmt.Errorf("invalid autoscaling: minReplicas must be at least 1")
+	}
+	if autoscaling.MaxReplicas < autoscaling.MinReplicas {
+		return nil, fmt.Errorf("invalid autoscaling: maxReplicas %d is lower than minReplicas %d", autoscaling.MaxReplicas, autoscaling.MinReplicas)
+	}
+	for name, target := range map[string]*int32{
+		"targetCPUUtilizationPercentage":    autoscaling.TargetCPUUtilizationPercentage,
+		"targetMemoryUtilizationPercentage": autoscaling.TargetMemoryUtilizationPercentage,
+	} {
+		if target != nil && *target < 1 {
+			return nil, fmt.Errorf("invalid autoscaling: %s must be positive", name)
+		}
+	}
+	if autoscaling.TargetCPUUtilizationPercentage == nil && autoscaling.TargetMemoryUtilizationPercentage == nil {
+		target := int32(DefaultTargetCPUUtilizationPercentage)
+		autoscaling.TargetCPUUtilizationPercentage = &target
+	}
+	return autoscaling, nil
+}
+
+// GetValidAutoscaling is GetAutoscaling for the consumers that fall back to a fixed
+// number of replicas when the autoscaling is invalid, the error is reported by the HPA
+// controller.
+func GetValidAutoscaling(operatorConfig *operatorv1.Console) *Autoscaling {
+	autoscaling, err := GetAutoscaling(operatorConfig)
+	if err != nil {
+		klog.V(4).Infof("ignoring the console autoscaling: %v", err)
+		return nil
+	}
+	return autoscaling
+}
+
+// DefaultHorizontalPodAutoscaler scales the console deployment.
+func DefaultHorizontalPodAutoscaler(operatorConfig *operatorv1.Console, autoscaling *Autoscaling) *autoscalingv2.HorizontalPodAutoscaler {
+	minReplicas := autoscaling.MinReplicas
+	hpa := &autoscalingv2.HorizontalPodAutoscaler{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      api.OpenShiftConsoleDeploymentName,
+			Namespace: api.OpenShiftConsoleNamespace,
+			Labels:    util.LabelsForConsole(),
+		},
+		Spec: autoscalingv2.HorizontalPodAutoscalerSpec{
+			ScaleTargetRef: autoscalingv2.CrossVersionObjectReference{
+				APIVersion: "apps/v1",
+				Kind:       "Deployment",
+				Name:       api.OpenShiftConsoleDeploymentName,
+			},
+			MinReplicas: &minReplicas,
+			MaxReplicas: autoscaling.MaxReplicas,
+			Metrics:     []autoscalingv2.MetricSpec{},
+		},
+	}
+	if autoscaling.TargetCPUUtilizationPercentage != nil {
+		hpa.Spec.Metrics = append(hpa.Spec.Metrics, utilizationMetric(corev1.ResourceCPU, *autoscaling.TargetCPUUtilizationPercentage))
+	}
+	if autoscaling.TargetMemoryUtilizationPercentage != nil {
+		hpa.Spec.Metrics = append(hpa.Spec.Metrics, utilizationMetric(corev1.ResourceMemory, *autoscaling.TargetMemoryUtilizationPercentage))
+	}
+	util.AddOwnerRef(hpa, util.OwnerRefFrom(operatorConfig))
+	return hpa
+}
+
+func utilizationMetric(name corev1.ResourceName, averageUtilization int32) autoscalingv2.MetricSpec {
+	return autoscalingv2.MetricSpec{
+		Type: autoscalingv2.ResourceMetricSourceType,
+		Resource: &autoscalingv2.ResourceMetricSource{
+			Name: name,
+			Target: autoscalingv2.MetricTarget{
+				Type:               autoscalingv2.UtilizationMetricType,
+				AverageUtilization: &averageUtilization,
+			},
+		},
+	}
+}
+
+func ApplyHorizontalPodAutoscaler(ctx context.Context, client autoscalingclientv2.HorizontalPodAutoscalersGetter, required *autoscalingv2.HorizontalPodAutoscaler) (*autoscalingv2.HorizontalPodAutoscaler, bool, error) {
+	existing, err := client.HorizontalPodAutoscalers(required.Namespace).Get(ctx, required.Name, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		actual, err := client.HorizontalPodAutoscalers(required.Namespace).Create(ctx, required, metav1.CreateOptions{})
+		return actual, true, err
+	}
+	if err != nil {
+		return nil, false, err
+	}
+
+	existingCopy := existing.DeepCopy()
+	modified := resourcemerge.BoolPtr(false)
+	resourcemerge.EnsureObjectMeta(modified, &existingCopy.ObjectMeta, required.ObjectMeta)
+	// the API server defaults the scaling behavior, it is left as is
+	required = required.DeepCopy()
+	required.Spec.Behavior = existingCopy.Spec.Behavior
+	specSame := equality.Semantic.DeepEqual(existingCopy.Spec, required.Spec)
+
+	if specSame && !*modified {
+		klog.V(4).Infof("%s horizontal pod autoscaler exists and is in the correct state", existingCopy.Name)
+		return existingCopy, false, nil
+	}
+
+	existingCopy.Spec = required.Spec
+	actual, err := client.HorizontalPodAutoscalers(required.Namespace).Update(ctx, existingCopy, metav1.UpdateOptions{})
+	return actual, true, err
+}
@@ -0,0 +1,105 @@
+package hpa
+
+import (
+	"context"
+	"testing"
+
+	"github.com/go-test/deep"
+
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+)
+
+func int32Ptr(i int32) *int32 {
+	return &i
+}
+
+func TestGetAutoscaling(t *testing.T) {
+	tests := []struct {
+		name       string
+		annotation *string
+		want       *Autoscaling
+		wantErr    bool
+	}{
+		{
+			name: "Fixed replicas",
+		},
+		{
+			name:       "Default CPU target",
+			annotation: stringPtr(`{"minReplicas":2,"maxReplicas":6}`),
+			want:       &Autoscaling{MinReplicas: 2, MaxReplicas: 6, TargetCPUUtilizationPercentage: int32Ptr(DefaultTargetCPUUtilizationPercentage)},
+		},
+		{
+			name:       "Memory target",
+			annotation: stringPtr(`{"minReplicas":3,"maxReplicas":10,"targetMemoryUtilizationPercentage":80}`),
+			want:       &Autoscaling{MinReplicas: 3, MaxReplicas: 10, TargetMemoryUtilizationPercentage: int32Ptr(80)},
+		},
+		{
+			name:       "No minimum",
+			annotation: stringPtr(`{"maxReplicas":6}`),
+			wantErr:    true,
+		},
+		{
+			name:       "Maximum below minimum",
+			annotation: stringPtr(`{"minReplicas":4,"maxReplicas":2}`),
+			wantErr:    true,
+		},
+		{
+			name:       "Negative target",
+			annotation: stringPtr(`{"minReplicas":2,"maxReplicas":6,"targetCPUUtilizationPercentage":-5}`),
+			wantErr:    true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			operatorConfig := &operatorv1.Console{}
+			if tt.annotation != nil {
+				operatorConfig.Annotations = map[string]string{AutoscalingAnnotation: *tt.annotation}
+			}
+			got, err := GetAutoscaling(operatorConfig)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("GetAutoscaling() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if diff := deep.Equal(got, tt.want); diff != nil {
+				t.Error(diff)
+			}
+		})
+	}
+}
+
+func TestApplyHorizontalPodAutoscaler(t *testing.T) {
+	client := fake.NewSimpleClientset()
+	operatorConfig := &operatorv1.Console{ObjectMeta: metav1.ObjectMeta{Name: "cluster"}}
+	autoscaling := &Autoscaling{MinReplicas: 2, MaxReplicas: 6, TargetCPUUtilizationPercentage: int32Ptr(70), TargetMemoryUtilizationPercentage: int32Ptr(80)}
+	required := DefaultHorizontalPodAutoscaler(operatorConfig, autoscaling)
+
+	actual, changed, err := ApplyHorizontalPodAutoscaler(context.TODO(), client.AutoscalingV2(), required)
+	if err != nil || !changed {
+		t.Fatalf("expected the HPA to be created, changed=%v err=%v", changed, err)
+	}
+	if _, changed, err = ApplyHorizontalPodAutoscaler(context.TODO(), client.AutoscalingV2(), required); err != nil || changed {
+		t.Fatalf("expected the HPA to be unchanged, changed=%v err=%v", changed, err)
+	}
+
+	if *actual.Spec.MinReplicas != 2 || actual.Spec.MaxReplicas != 6 || actual.Spec.ScaleTargetRef.Name != "console" {
+		t.Errorf("unexpected HPA spec %v", actual.Spec)
+	}
+	metricNames := []corev1.ResourceName{}
+	for _, metric := range actual.Spec.Metrics {
+		if metric.Type != autoscalingv2.ResourceMetricSourceType || metric.Resource.Target.Type != autoscalingv2.UtilizationMetricType {
+			t.Errorf("unexpected HPA metric %v", metric)
+		}
+		metricNames = append(metricNames, metric.Resource.Name)
+	}
+	if diff := deep.Equal(metricNames, []corev1.ResourceName{corev1.ResourceCPU, corev1.ResourceMemory}); diff != nil {
+		t.Error(diff)
+	}
+}
+
+func stringPtr(s string) *string {
+	return &s
+}
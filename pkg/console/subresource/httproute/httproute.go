@@ -0,0 +1,224 @@
+package httproute
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+
+	// kube
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/klog/v2"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+
+	"github.com/openshift/console-operator/pkg/api"
+)
+
+const (
+	// GatewayAnnotation is set on the operator config to the "<namespace>/<name>" of a
+	// Gateway. The console and downloads are then exposed with Gateway API HTTPRoutes
+	// attached to it instead of OpenShift Routes.
+	GatewayAnnotation = "console.openshift.io/gateway"
+	// GatewayListenerAnnotation optionally names the Gateway listener the HTTPRoutes attach to,
+	// the listener is expected to terminate TLS for the console hostnames.
+	GatewayListenerAnnotation = "console.openshift.io/gateway-listener"
+
+	// BackendCAConfigMapName holds the service CA under the key BackendTLSPolicy expects,
+	// so that the Gateway can verify the console service.
+	BackendCAConfigMapName = "console-gateway-backend-ca"
+
+	gatewayAPIGroup = "gateway.networking.k8s.io"
+)
+
+var (
+	HTTPRouteResource        = schema.GroupVersionResource{Group: gatewayAPIGroup, Version: "v1", Resource: "httproutes"}
+	BackendTLSPolicyResource = schema.GroupVersionResource{Group: gatewayAPIGroup, Version: "v1", Resource: "backendtlspolicies"}
+)
+
+// GatewayReference points to the Gateway listener the HTTPRoutes attach to.
+type GatewayReference struct {
+	Namespace   string
+	Name        string
+	SectionName string
+}
+
+// GetGatewayReference returns the Gateway set on the operator config, or nil when
+// the console is exposed with Routes.
+func GetGatewayReference(operatorConfig *operatorv1.Console) (*GatewayReference, error) {
+	value, ok := operatorConfig.Annotations[GatewayAnnotation]
+	if !ok || len(value) == 0 {
+		return nil, nil
+	}
+	namespace, name, found := strings.Cut(value, "/")
+	if !found || len(namespace) == 0 || len(name) == 0 {
+		return nil, fmt.Errorf("invalid %s annotation %q, expected <namespace>/<name>", GatewayAnnotation, value)
+	}
+	return &GatewayReference{
+		Namespace:   namespace,
+		Name:        name,
+		SectionName: operatorConfig.Annotations[GatewayListenerAnnotation],
+	}, nil
+}
+
+// IsGatewayEnabled returns true when the console is exposed through a Gateway, an empty
+// gateway annotation leaves it on Routes.
+func IsGatewayEnabled(operatorConfig *operatorv1.Console) bool {
+	return len(operatorConfig.Annotations[GatewayAnnotation]) != 0
+}
+
+// DefaultHTTPRoute creates an HTTPRoute sending all the traffic for hostname to the
+// given port of the service.
+func DefaultHTTPRoute(name string, hostname string, serviceName string, servicePort int64, gateway *GatewayReference) *unstructured.Unstructured {
+	parentRef := map[string]interface{}{
+		"group":     gatewayAPIGroup,
+		"kind":      "Gateway",
+		"namespace": gateway.Namespace,
+		"name":      gateway.Name,
+	}
+	if len(gateway.SectionName) != 0 {
+		parentRef["sectionName"] = gateway.SectionName
+	}
+	httpRoute := &unstructured.Unstructured{}
+	httpRoute.SetAPIVersion(HTTPRouteResource.GroupVersion().String())
+	httpRoute.SetKind("HTTPRoute")
+	httpRoute.SetName(name)
+	httpRoute.SetNamespace(api.OpenShiftConsoleNamespace)
+	httpRoute.SetLabels(map[string]string{"app": api.OpenShiftConsoleName})
+	httpRoute.Object["spec"] = map[string]interface{}{
+		"parentRefs": []interface{}{parentRef},
+		"hostnames":  []interface{}{hostname},
+		"rules": []interface{}{
+			map[string]interface{}{
+				"backendRefs": []interface{}{
+					map[string]interface{}{
+						"name": serviceName,
+						"port": servicePort,
+					},
+				},
+			},
+		},
+	}
+	return httpRoute
+}
+
+// DefaultBackendTLSPolicy makes the Gateway re-encrypt the traffic to the console
+// service and verify it with the service CA.
+func DefaultBackendTLSPolicy() *unstructured.Unstructured {
+	policy := &unstructured.Unstructured{}
+	policy.SetAPIVersion(BackendTLSPolicyResource.GroupVersion().String())
+	policy.SetKind("BackendTLSPolicy")
+	policy.SetName(api.OpenShiftConsoleName)
+	policy.SetNamespace(api.OpenShiftConsoleNamespace)
+	policy.SetLabels(map[string]string{"app": api.OpenShiftConsoleName})
+	policy.Object["spec"] = map[string]interface{}{
+		"targetRefs": []interface{}{
+			map[string]interface{}{
+				"group": "",
+				"kind":  "Service",
+				"name":  api.OpenShiftConsoleServiceName,
+			},
+		},
+		"validation": map[string]interface{}{
+			"caCertificateRefs": []interface{}{
+				map[string]interface{}{
+					"group": "",
+					"kind":  "ConfigMap",
+					"name":  BackendCAConfigMapName,
+				},
+			},
+			"hostname": fmt.Sprintf("%s.%s.svc", api.OpenShiftConsoleServiceName, api.OpenShiftConsoleNamespace),
+		},
+	}
+	return policy
+}
+
+// Apply creates or updates a Gateway API object, only the spec, labels and
+// annotations are reconciled.
+func Apply(ctx context.Context, client dynamic.Interface, resource schema.GroupVersionResource, required *unstructured.Unstructured) (*unstructured.Unstructured, bool, error) {
+	resourceClient := client.Resource(resource).Namespace(required.GetNamespace())
+	existing, err := resourceClient.Get(ctx, required.GetName(), metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		actual, err := resourceClient.Create(ctx, required, metav1.CreateOptions{})
+		return actual, true, err
+	}
+	if err != nil {
+		return nil, false, err
+	}
+
+	existingCopy := existing.DeepCopy()
+	modified := false
+	labels := existingCopy.GetLabels()
+	for key, value := range required.GetLabels() {
+		if labels == nil {
+			labels = map[string]string{}
+		}
+		if labels[key] != value {
+			labels[key] = value
+			modified = true
+		}
+	}
+	existingCopy.SetLabels(labels)
+	if !equality.Semantic.DeepEqual(existingCopy.Object["spec"], required.Object["spec"]) {
+		existingCopy.Object["spec"] = required.Object["spec"]
+		modified = true
+	}
+	if !modified {
+		klog.V(4).Infof("%s %s exists and is in the correct state", existingCopy.GetKind(), existingCopy.GetName())
+		return existingCopy, false, nil
+	}
+	actual, err := resourceClient.Update(ctx, existingCopy, metav1.UpdateOptions{})
+	return actual, true, err
+}
+
+// Delete removes a Gateway API object, it is not an error if it does not exist.
+func Delete(ctx context.Context, client dynamic.Interface, resource schema.GroupVersionResource, name string) error {
+	err := client.Resource(resource).Namespace(api.OpenShiftConsoleNamespace).Delete(ctx, name, metav1.DeleteOptions{})
+	if apierrors.IsNotFound(err) {
+		return nil
+	}
+	return err
+}
+
+// GetActiveHTTPRouteInfo returns the URL an HTTPRoute serves once a Gateway accepted it.
+func GetActiveHTTPRouteInfo(ctx context.Context, client dynamic.Interface, name string) (httpRoute *unstructured.Unstructured, routeURL *url.URL, reason string, err error) {
+	httpRoute, err = client.Resource(HTTPRouteResource).Namespace(api.OpenShiftConsoleNamespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return nil, nil, "FailedGet", err
+	}
+	hostnames, _, _ := unstructured.NestedStringSlice(httpRoute.Object, "spec", "hostnames")
+	if len(hostnames) == 0 {
+		return nil, nil, "FailedHostname", fmt.Errorf("%s HTTPRoute has no hostname", name)
+	}
+	if !IsAccepted(httpRoute) {
+		return nil, nil, "NotAccepted", fmt.Errorf("%s HTTPRoute is not accepted by its gateway", name)
+	}
+	return httpRoute, &url.URL{Scheme: "https", Host: hostnames[0]}, "", nil
+}
+
+// IsAccepted returns true when one of the parents of the HTTPRoute accepted it.
+func IsAccepted(httpRoute *unstructured.Unstructured) bool {
+	parents, _, _ := unstructured.NestedSlice(httpRoute.Object, "status", "parents")
+	for _, parent := range parents {
+		parentMap, ok := parent.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		conditions, _, _ := unstructured.NestedSlice(parentMap, "conditions")
+		for _, condition := range conditions {
+			conditionMap, ok := condition.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			if conditionMap["type"] == "Accepted" && conditionMap["status"] == string(metav1.ConditionTrue) {
+				return true
+			}
+		}
+	}
+	return false
+}
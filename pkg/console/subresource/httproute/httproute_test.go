@@ -0,0 +1,115 @@
+package httproute
+
+import (
+	"context"
+	"testing"
+
+	"github.com/go-test/deep"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+)
+
+func TestGetGatewayReference(t *testing.T) {
+	tests := []struct {
+		name        string
+		annotations map[string]string
+		want        *GatewayReference
+		wantErr     bool
+	}{
+		{
+			name: "No gateway",
+		},
+		{
+			name:        "Gateway with a listener",
+			annotations: map[string]string{GatewayAnnotation: "gateways/public", GatewayListenerAnnotation: "https"},
+			want:        &GatewayReference{Namespace: "gateways", Name: "public", SectionName: "https"},
+		},
+		{
+			name:        "Empty gateway",
+			annotations: map[string]string{GatewayAnnotation: ""},
+		},
+		{
+			name:        "Gateway without a namespace",
+			annotations: map[string]string{GatewayAnnotation: "public"},
+			wantErr:     true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			operatorConfig := &operatorv1.Console{ObjectMeta: metav1.ObjectMeta{Annotations: tt.annotations}}
+			got, err := GetGatewayReference(operatorConfig)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if diff := deep.Equal(got, tt.want); diff != nil {
+				t.Error(diff)
+			}
+			if IsGatewayEnabled(operatorConfig) != (got != nil || tt.wantErr) {
+				t.Errorf("IsGatewayEnabled does not match the gateway reference %v", got)
+			}
+		})
+	}
+}
+
+func TestApplyAndGetActiveHTTPRouteInfo(t *testing.T) {
+	client := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), map[schema.GroupVersionResource]string{
+		HTTPRouteResource: "HTTPRouteList",
+	})
+	gateway := &GatewayReference{Namespace: "gateways", Name: "public"}
+	required := DefaultHTTPRoute("console", "console.apps.example.com", "console", 443, gateway)
+
+	if _, changed, err := Apply(context.TODO(), client, HTTPRouteResource, required); err != nil || !changed {
+		t.Fatalf("expected the HTTPRoute to be created, got changed=%v err=%v", changed, err)
+	}
+	if _, changed, err := Apply(context.TODO(), client, HTTPRouteResource, required); err != nil || changed {
+		t.Fatalf("expected the HTTPRoute to be unchanged, got changed=%v err=%v", changed, err)
+	}
+
+	if _, _, reason, err := GetActiveHTTPRouteInfo(context.TODO(), client, "console"); err == nil || reason != "NotAccepted" {
+		t.Fatalf("expected the HTTPRoute not to be accepted, got reason=%q err=%v", reason, err)
+	}
+
+	accepted := required.DeepCopy()
+	accepted.Object["status"] = map[string]interface{}{
+		"parents": []interface{}{
+			map[string]interface{}{
+				"conditions": []interface{}{
+					map[string]interface{}{"type": "Accepted", "status": "True"},
+				},
+			},
+		},
+	}
+	if _, err := client.Resource(HTTPRouteResource).Namespace(accepted.GetNamespace()).Update(context.TODO(), accepted, metav1.UpdateOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	_, routeURL, _, err := GetActiveHTTPRouteInfo(context.TODO(), client, "console")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if routeURL.String() != "https://console.apps.example.com" {
+		t.Errorf("unexpected url %q", routeURL)
+	}
+}
+
+func TestIsAccepted(t *testing.T) {
+	httpRoute := &unstructured.Unstructured{Object: map[string]interface{}{
+		"status": map[string]interface{}{
+			"parents": []interface{}{
+				map[string]interface{}{
+					"conditions": []interface{}{
+						map[string]interface{}{"type": "Accepted", "status": "False"},
+					},
+				},
+			},
+		},
+	}}
+	if IsAccepted(httpRoute) {
+		t.Error("expected the HTTPRoute not to be accepted")
+	}
+}
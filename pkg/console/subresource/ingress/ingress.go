@@ -0,0 +1,169 @@
+package ingress
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+
+	// kube
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	networkingclientv1 "k8s.io/client-go/kubernetes/typed/networking/v1"
+	networkingv1listers "k8s.io/client-go/listers/networking/v1"
+	"k8s.io/klog/v2"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+	"github.com/openshift/library-go/pkg/operator/resource/resourcemerge"
+
+	"github.com/openshift/console-operator/pkg/api"
+	httproutesub "github.com/openshift/console-operator/pkg/console/subresource/httproute"
+	routesub "github.com/openshift/console-operator/pkg/console/subresource/route"
+	"github.com/openshift/console-operator/pkg/console/subresource/util"
+)
+
+const (
+	// IngressClassAnnotation is set on the operator config to the IngressClass that
+	// serves the console. The console and downloads are then exposed with Ingresses
+	// instead of OpenShift Routes. A Gateway set with the gateway annotation takes
+	// precedence.
+	IngressClassAnnotation = "console.openshift.io/ingress-class"
+	// IngressAnnotationsAnnotation is set on the operator config to a JSON object of the
+	// annotations added to the Ingresses, e.g. to tell the ingress controller that the
+	// console backend is served over https.
+	IngressAnnotationsAnnotation = "console.openshift.io/ingress-annotations"
+)
+
+// IsIngressEnabled returns true when the console is exposed through Ingresses.
+func IsIngressEnabled(operatorConfig *operatorv1.Console) bool {
+	_, ok := operatorConfig.Annotations[IngressClassAnnotation]
+	return ok && !httproutesub.IsGatewayEnabled(operatorConfig)
+}
+
+// GetIngressClassName returns the IngressClass set on the operator config.
+func GetIngressClassName(operatorConfig *operatorv1.Console) string {
+	return operatorConfig.Annotations[IngressClassAnnotation]
+}
+
+// GetIngressAnnotations reads the annotations to set on the Ingresses from the operator config.
+func GetIngressAnnotations(operatorConfig *operatorv1.Console) (map[string]string, error) {
+	annotations := map[string]string{}
+	value, ok := operatorConfig.Annotations[IngressAnnotationsAnnotation]
+	if !ok {
+		return annotations, nil
+	}
+	if err := json.Unmarshal([]byte(value), &annotations); err != nil {
+		return nil, fmt.Errorf("invalid %s annotation: %w", IngressAnnotationsAnnotation, err)
+	}
+	return annotations, nil
+}
+
+// GetTLSSecretName returns the name of the secret, in the console namespace, holding
+// the custom certificate and key of an Ingress.
+func GetTLSSecretName(ingressName string) string {
+	return fmt.Sprintf("%s-ingress-tls", ingressName)
+}
+
+// DefaultIngress creates an Ingress sending all the traffic for hostname to the given
+// port of the service. Without a TLS secret the ingress controller serves its default
+// certificate.
+func DefaultIngress(name string, hostname string, serviceName string, servicePort int32, className string, annotations map[string]string, tlsSecretName string) *networkingv1.Ingress {
+	pathType := networkingv1.PathTypePrefix
+	ingress := &networkingv1.Ingress{
+		ObjectMeta: util.SharedMeta(),
+		Spec: networkingv1.IngressSpec{
+			TLS: []networkingv1.IngressTLS{
+				{
+					Hosts:      []string{hostname},
+					SecretName: tlsSecretName,
+				},
+			},
+			Rules: []networkingv1.IngressRule{
+				{
+					Host: hostname,
+					IngressRuleValue: networkingv1.IngressRuleValue{
+						HTTP: &networkingv1.HTTPIngressRuleValue{
+							Paths: []networkingv1.HTTPIngressPath{
+								{
+									Path:     "/",
+									PathType: &pathType,
+									Backend: networkingv1.IngressBackend{
+										Service: &networkingv1.IngressServiceBackend{
+											Name: serviceName,
+											Port: networkingv1.ServiceBackendPort{Number: servicePort},
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+	ingress.Name = name
+	ingress.Annotations = annotations
+	if len(className) != 0 {
+		ingress.Spec.IngressClassName = &className
+	}
+	return ingress
+}
+
+// DefaultTLSSecret copies the custom certificate and key into the console namespace,
+// where the Ingress can reference them.
+func DefaultTLSSecret(ingressName string, customTLS *routesub.CustomTLSCert) *corev1.Secret {
+	secret := &corev1.Secret{
+		ObjectMeta: util.SharedMeta(),
+		Type:       corev1.SecretTypeTLS,
+		Data: map[string][]byte{
+			corev1.TLSCertKey:       []byte(customTLS.Certificate),
+			corev1.TLSPrivateKeyKey: []byte(customTLS.Key),
+		},
+	}
+	secret.Name = GetTLSSecretName(ingressName)
+	return secret
+}
+
+func ApplyIngress(ctx context.Context, client networkingclientv1.IngressesGetter, required *networkingv1.Ingress) (*networkingv1.Ingress, bool, error) {
+	existing, err := client.Ingresses(required.Namespace).Get(ctx, required.Name, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		actual, err := client.Ingresses(required.Namespace).Create(ctx, required, metav1.CreateOptions{})
+		return actual, true, err
+	}
+	if err != nil {
+		return nil, false, err
+	}
+
+	existingCopy := existing.DeepCopy()
+	modified := resourcemerge.BoolPtr(false)
+	resourcemerge.EnsureObjectMeta(modified, &existingCopy.ObjectMeta, required.ObjectMeta)
+	specSame := equality.Semantic.DeepEqual(existingCopy.Spec, required.Spec)
+
+	if specSame && !*modified {
+		klog.V(4).Infof("%s ingress exists and is in the correct state", existingCopy.Name)
+		return existingCopy, false, nil
+	}
+
+	existingCopy.Spec = required.Spec
+	actual, err := client.Ingresses(required.Namespace).Update(ctx, existingCopy, metav1.UpdateOptions{})
+	return actual, true, err
+}
+
+// GetActiveIngressInfo returns the URL an Ingress serves once its ingress controller
+// admitted it.
+func GetActiveIngressInfo(ingressLister networkingv1listers.IngressLister, name string) (ingress *networkingv1.Ingress, ingressURL *url.URL, reason string, err error) {
+	ingress, err = ingressLister.Ingresses(api.OpenShiftConsoleNamespace).Get(name)
+	if err != nil {
+		return nil, nil, "FailedGet", err
+	}
+	if len(ingress.Spec.Rules) == 0 || len(ingress.Spec.Rules[0].Host) == 0 {
+		return nil, nil, "FailedHostname", fmt.Errorf("%s ingress has no host", name)
+	}
+	if len(ingress.Status.LoadBalancer.Ingress) == 0 {
+		return nil, nil, "NotAdmitted", fmt.Errorf("%s ingress is not admitted by its ingress controller", name)
+	}
+	return ingress, &url.URL{Scheme: "https", Host: ingress.Spec.Rules[0].Host}, "", nil
+}
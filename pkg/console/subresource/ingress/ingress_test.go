@@ -0,0 +1,87 @@
+package ingress
+
+import (
+	"context"
+	"testing"
+
+	"github.com/go-test/deep"
+
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+	networkingv1listers "k8s.io/client-go/listers/networking/v1"
+	"k8s.io/client-go/tools/cache"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+
+	"github.com/openshift/console-operator/pkg/api"
+	httproutesub "github.com/openshift/console-operator/pkg/console/subresource/httproute"
+)
+
+func TestIsIngressEnabled(t *testing.T) {
+	tests := []struct {
+		name        string
+		annotations map[string]string
+		want        bool
+	}{
+		{
+			name: "Routes",
+		},
+		{
+			name:        "Ingress class set",
+			annotations: map[string]string{IngressClassAnnotation: "nginx"},
+			want:        true,
+		},
+		{
+			name:        "Gateway takes precedence",
+			annotations: map[string]string{IngressClassAnnotation: "nginx", httproutesub.GatewayAnnotation: "gateways/public"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			operatorConfig := &operatorv1.Console{ObjectMeta: metav1.ObjectMeta{Annotations: tt.annotations}}
+			if got := IsIngressEnabled(operatorConfig); got != tt.want {
+				t.Errorf("IsIngressEnabled() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestApplyAndGetActiveIngressInfo(t *testing.T) {
+	client := fake.NewSimpleClientset()
+	annotations := map[string]string{"nginx.ingress.kubernetes.io/backend-protocol": "HTTPS"}
+	required := DefaultIngress("console", "console.apps.example.com", "console", 443, "nginx", annotations, "")
+
+	actual, changed, err := ApplyIngress(context.TODO(), client.NetworkingV1(), required)
+	if err != nil || !changed {
+		t.Fatalf("expected the ingress to be created, changed=%v err=%v", changed, err)
+	}
+	if _, changed, err = ApplyIngress(context.TODO(), client.NetworkingV1(), required); err != nil || changed {
+		t.Fatalf("expected the ingress to be unchanged, changed=%v err=%v", changed, err)
+	}
+
+	indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
+	if err := indexer.Add(actual); err != nil {
+		t.Fatal(err)
+	}
+	lister := networkingv1listers.NewIngressLister(indexer)
+	if _, _, reason, err := GetActiveIngressInfo(lister, "console"); err == nil || reason != "NotAdmitted" {
+		t.Errorf("expected a not admitted ingress, got reason %q and error %v", reason, err)
+	}
+
+	admitted := actual.DeepCopy()
+	admitted.Status.LoadBalancer.Ingress = []networkingv1.IngressLoadBalancerIngress{{IP: "192.0.2.10"}}
+	if err := indexer.Update(admitted); err != nil {
+		t.Fatal(err)
+	}
+	_, url, _, err := GetActiveIngressInfo(lister, "console")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := deep.Equal(url.String(), "https://console.apps.example.com"); diff != nil {
+		t.Error(diff)
+	}
+	if actual.Namespace != api.OpenShiftConsoleNamespace || *actual.Spec.IngressClassName != "nginx" {
+		t.Errorf("unexpected ingress %s/%s with class %q", actual.Namespace, actual.Name, *actual.Spec.IngressClassName)
+	}
+}
@@ -14,6 +14,7 @@ import (
 	"github.com/openshift/console-operator/pkg/api"
 	"github.com/openshift/console-operator/pkg/console/subresource/util"
 	"github.com/openshift/console-operator/pkg/crypto"
+	"golang.org/x/exp/slices"
 )
 
 // TODO: ApplyOauth should be a generic Apply that could be used for any oauth-client
@@ -36,14 +37,15 @@ func CustomApplyOAuth(client oauthclient.OAuthClientsGetter, required *oauthv1.O
 	resourcemerge.EnsureObjectMeta(modified, &existing.ObjectMeta, required.ObjectMeta)
 	// at present, we only care about these two fields. this is NOT generic to all oauth clients
 	secretSame := equality.Semantic.DeepEqual(existing.Secret, required.Secret)
+	additionalSecretsSame := equality.Semantic.DeepEqual(existing.AdditionalSecrets, required.AdditionalSecrets)
 	redirectsSame := equality.Semantic.DeepEqual(existing.RedirectURIs, required.RedirectURIs)
 	// nothing changed, so don't update
-	if secretSame && redirectsSame && !*modified {
+	if secretSame && additionalSecretsSame && redirectsSame && !*modified {
 		// per ApplyService, etc, if nothing changed, return nil.
 		return nil, false, nil
 	}
 	existing.Secret = required.Secret
-	// existing.AdditionalSecrets = required.AdditionalSecrets
+	existing.AdditionalSecrets = required.AdditionalSecrets
 	// existing.RespondWithChallenges = required.RespondWithChallenges
 	existing.RedirectURIs = required.RedirectURIs
 	// existing.GrantMethod = required.GrantMethod
@@ -54,9 +56,10 @@ func CustomApplyOAuth(client oauthclient.OAuthClientsGetter, required *oauthv1.O
 	return actual, true, err
 }
 
-// registers the console on the oauth client as a valid application
-func RegisterConsoleToOAuthClient(client *oauthv1.OAuthClient, host string, randomBits string) *oauthv1.OAuthClient {
-	SetRedirectURI(client, host)
+// registers the console on the oauth client as a valid application,
+// reachable on its host and its admitted hostname aliases
+func RegisterConsoleToOAuthClient(client *oauthv1.OAuthClient, host string, randomBits string, aliasHosts ...string) *oauthv1.OAuthClient {
+	SetRedirectURI(client, host, aliasHosts...)
 	// client.Secret = randomBits
 	SetSecretString(client, randomBits)
 	return client
@@ -94,14 +97,35 @@ func SetSecretString(client *oauthv1.OAuthClient, randomBits string) *oauthv1.OA
 }
 
 // we are the only application for this client
-// in the future we may accept multiple routes
-// for now, we can clobber the slice & reset the entire thing
-func SetRedirectURI(client *oauthv1.OAuthClient, host string) *oauthv1.OAuthClient {
+// the console host comes first, followed by its hostname aliases
+// we can clobber the slice & reset the entire thing
+func SetRedirectURI(client *oauthv1.OAuthClient, host string, aliasHosts ...string) *oauthv1.OAuthClient {
 	client.RedirectURIs = []string{}
 	client.RedirectURIs = append(client.RedirectURIs, util.HTTPS(host)+"/auth/callback")
+	for _, aliasHost := range aliasHosts {
+		client.RedirectURIs = append(client.RedirectURIs, util.HTTPS(aliasHost)+"/auth/callback")
+	}
 	return client
 }
 
+// SetAdditionalSecrets sets the secrets accepted along with the client secret while it
+// rotates, the empty ones are left out.
+func SetAdditionalSecrets(client *oauthv1.OAuthClient, additionalSecrets ...string) *oauthv1.OAuthClient {
+	client.AdditionalSecrets = nil
+	for _, additionalSecret := range additionalSecrets {
+		if len(additionalSecret) > 0 {
+			client.AdditionalSecrets = append(client.AdditionalSecrets, additionalSecret)
+		}
+	}
+	return client
+}
+
+// AcceptsSecret returns true when the client secret or one of the additional secrets of
+// the oauth client is the given secret.
+func AcceptsSecret(client *oauthv1.OAuthClient, secret string) bool {
+	return client.Secret == secret || slices.Contains(client.AdditionalSecrets, secret)
+}
+
 func GetRedirectURIs(client *oauthv1.OAuthClient) []string {
 	return client.RedirectURIs
 }
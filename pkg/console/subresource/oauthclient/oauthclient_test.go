@@ -86,8 +86,9 @@ func TestStub(t *testing.T) {
 
 func TestSetRedirectURI(t *testing.T) {
 	type args struct {
-		client *oauthv1.OAuthClient
-		host   string
+		client     *oauthv1.OAuthClient
+		host       string
+		aliasHosts []string
 	}
 	tests := []struct {
 		name string
@@ -124,10 +125,25 @@ func TestSetRedirectURI(t *testing.T) {
 				AccessTokenInactivityTimeoutSeconds: nil,
 			},
 		},
+		{
+			name: "Test set redirect URIs with hostname aliases",
+			args: args{
+				client:     &oauthv1.OAuthClient{},
+				host:       "example.com",
+				aliasHosts: []string{"https://console.example.org", "console.example.net"},
+			},
+			want: &oauthv1.OAuthClient{
+				RedirectURIs: []string{
+					"https://example.com/auth/callback",
+					"https://console.example.org/auth/callback",
+					"https://console.example.net/auth/callback",
+				},
+			},
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			if diff := deep.Equal(SetRedirectURI(tt.args.client, tt.args.host), tt.want); diff != nil {
+			if diff := deep.Equal(SetRedirectURI(tt.args.client, tt.args.host, tt.args.aliasHosts...), tt.want); diff != nil {
 				t.Error(diff)
 			}
 		})
@@ -0,0 +1,115 @@
+package route
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+
+	// kube
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/validation"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+	routev1 "github.com/openshift/api/route/v1"
+	routev1listers "github.com/openshift/client-go/route/listers/route/v1"
+	"github.com/openshift/library-go/pkg/route/routeapihelpers"
+
+	"github.com/openshift/console-operator/pkg/api"
+)
+
+const (
+	// HostnameAliasesAnnotation is set on the operator config to a JSON list of
+	// HostnameAlias, vanity hostnames the console is served on in addition to its
+	// default and custom hostnames. The admitted aliases are registered as redirect URIs
+	// of the console OAuth client, but the console server redirects the login to its
+	// single base address, so a login started on an alias ends on the main host.
+	HostnameAliasesAnnotation = "console.openshift.io/hostname-aliases"
+	// HostnameAliasLabel is set on the alias routes to the name of their alias.
+	HostnameAliasLabel = "console.openshift.io/hostname-alias"
+)
+
+// HostnameAlias is an additional hostname of the console, exposed with its own route.
+type HostnameAlias struct {
+	// Name identifies the alias, its route is named console-alias-<name>.
+	Name     string `json:"name"`
+	Hostname string `json:"hostname"`
+	// ServingCertKeyPairSecret names a TLS secret in openshift-config holding the
+	// certificate and key for the hostname. It can be omitted for hostnames under the
+	// cluster domain, the ingress controller certificate is then used.
+	ServingCertKeyPairSecret string `json:"servingCertKeyPairSecret,omitempty"`
+}
+
+// GetHostnameAliases reads the hostname aliases set on the operator config.
+func GetHostnameAliases(operatorConfig *operatorv1.Console) ([]HostnameAlias, error) {
+	value, ok := operatorConfig.Annotations[HostnameAliasesAnnotation]
+	if !ok {
+		return nil, nil
+	}
+	aliases := []HostnameAlias{}
+	if err := json.Unmarshal([]byte(value), &aliases); err != nil {
+		return nil, fmt.Errorf("invalid %s annotation: %w", HostnameAliasesAnnotation, err)
+	}
+	names := map[string]bool{}
+	hostnames := map[string]bool{}
+	for _, alias := range aliases {
+		if errs := validation.IsDNS1123Label(alias.Name); len(errs) > 0 {
+			return nil, fmt.Errorf("invalid hostname alias name %q: %s", alias.Name, strings.Join(errs, ", "))
+		}
+		if errs := validation.IsDNS1123Subdomain(alias.Hostname); len(errs) > 0 {
+			return nil, fmt.Errorf("invalid hostname %q for alias %s: %s", alias.Hostname, alias.Name, strings.Join(errs, ", "))
+		}
+		if names[alias.Name] || hostnames[alias.Hostname] {
+			return nil, fmt.Errorf("duplicate hostname alias %s (%s)", alias.Name, alias.Hostname)
+		}
+		names[alias.Name] = true
+		hostnames[alias.Hostname] = true
+	}
+	return aliases, nil
+}
+
+// HostnameAliasRouteNamePrefix starts the name of every alias route.
+var HostnameAliasRouteNamePrefix = fmt.Sprintf("%s-alias-", api.OpenShiftConsoleRouteName)
+
+func GetHostnameAliasRouteName(aliasName string) string {
+	return HostnameAliasRouteNamePrefix + aliasName
+}
+
+// AliasRoute creates the route of a hostname alias, it points to the console service
+// like the custom route does.
+func (rc *RouteConfig) AliasRoute(alias HostnameAlias, tlsConfig *CustomTLSCert) *routev1.Route {
+	route := rc.CustomRoute(tlsConfig, rc.routeName)
+	route.Name = GetHostnameAliasRouteName(alias.Name)
+	route.Labels[HostnameAliasLabel] = alias.Name
+	route.Spec.Host = alias.Hostname
+	return route
+}
+
+// GetHostnameAliasRoutes lists the alias routes that exist in the console namespace.
+func GetHostnameAliasRoutes(routeLister routev1listers.RouteLister) ([]*routev1.Route, error) {
+	selector, err := labels.Parse(HostnameAliasLabel)
+	if err != nil {
+		return nil, err
+	}
+	return routeLister.Routes(api.OpenShiftConsoleNamespace).List(selector)
+}
+
+// GetAdmittedHostnameAliasURLs returns the URLs of the alias routes, of the aliases set
+// on the operator config, that the router admitted.
+func GetAdmittedHostnameAliasURLs(routeLister routev1listers.RouteLister, operatorConfig *operatorv1.Console) ([]*url.URL, error) {
+	aliases, err := GetHostnameAliases(operatorConfig)
+	if err != nil || len(aliases) == 0 {
+		return nil, err
+	}
+	aliasURLs := []*url.URL{}
+	for _, alias := range aliases {
+		route, err := routeLister.Routes(api.OpenShiftConsoleNamespace).Get(GetHostnameAliasRouteName(alias.Name))
+		if err != nil {
+			continue
+		}
+		if aliasURL, _, err := routeapihelpers.IngressURI(route, route.Spec.Host); err == nil {
+			aliasURLs = append(aliasURLs, aliasURL)
+		}
+	}
+	return aliasURLs, nil
+}
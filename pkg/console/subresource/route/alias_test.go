@@ -0,0 +1,128 @@
+package route
+
+import (
+	"testing"
+
+	"github.com/go-test/deep"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/cache"
+
+	configv1 "github.com/openshift/api/config/v1"
+	operatorv1 "github.com/openshift/api/operator/v1"
+	routev1 "github.com/openshift/api/route/v1"
+	routev1listers "github.com/openshift/client-go/route/listers/route/v1"
+
+	"github.com/openshift/console-operator/pkg/api"
+)
+
+func TestGetHostnameAliases(t *testing.T) {
+	tests := []struct {
+		name    string
+		value   *string
+		want    []HostnameAlias
+		wantErr bool
+	}{
+		{
+			name: "Test no hostname aliases",
+		},
+		{
+			name:  "Test hostname aliases",
+			value: toPtr(`[{"name":"sales","hostname":"console.sales.example.com","servingCertKeyPairSecret":"sales-tls"},{"name":"legacy","hostname":"console.apps.example.com"}]`),
+			want: []HostnameAlias{
+				{Name: "sales", Hostname: "console.sales.example.com", ServingCertKeyPairSecret: "sales-tls"},
+				{Name: "legacy", Hostname: "console.apps.example.com"},
+			},
+		},
+		{
+			name:    "Test invalid alias name",
+			value:   toPtr(`[{"name":"Sales_Unit","hostname":"console.sales.example.com"}]`),
+			wantErr: true,
+		},
+		{
+			name:    "Test duplicate hostname",
+			value:   toPtr(`[{"name":"a","hostname":"console.example.com"},{"name":"b","hostname":"console.example.com"}]`),
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			operatorConfig := &operatorv1.Console{}
+			if tt.value != nil {
+				operatorConfig.Annotations = map[string]string{HostnameAliasesAnnotation: *tt.value}
+			}
+			got, err := GetHostnameAliases(operatorConfig)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("GetHostnameAliases() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if diff := deep.Equal(got, tt.want); diff != nil {
+				t.Error(diff)
+			}
+		})
+	}
+}
+
+func TestAliasRoute(t *testing.T) {
+	ingressConfig := &configv1.Ingress{Spec: configv1.IngressSpec{Domain: "apps.example.com"}}
+	routeConfig := NewRouteConfig(&operatorv1.Console{ObjectMeta: metav1.ObjectMeta{}}, ingressConfig, api.OpenShiftConsoleRouteName)
+	alias := HostnameAlias{Name: "sales", Hostname: "console.sales.example.com"}
+
+	route := routeConfig.AliasRoute(alias, &CustomTLSCert{Certificate: "cert", Key: "key"})
+	if route.Name != "console-alias-sales" || route.Spec.Host != alias.Hostname || route.Labels[HostnameAliasLabel] != alias.Name {
+		t.Errorf("unexpected alias route %s for host %s with labels %v", route.Name, route.Spec.Host, route.Labels)
+	}
+	if route.Spec.To.Name != api.OpenShiftConsoleServiceName || route.Spec.TLS.Certificate != "cert" {
+		t.Errorf("alias route should serve the console service with the alias certificate")
+	}
+}
+
+func toPtr(value string) *string {
+	return &value
+}
+
+func TestGetAdmittedHostnameAliasURLs(t *testing.T) {
+	aliasRoute := func(aliasName, host string, admitted bool) *routev1.Route {
+		route := &routev1.Route{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      GetHostnameAliasRouteName(aliasName),
+				Namespace: api.OpenShiftConsoleNamespace,
+			},
+			Spec: routev1.RouteSpec{Host: host, TLS: &routev1.TLSConfig{Termination: routev1.TLSTerminationReencrypt}},
+		}
+		if admitted {
+			route.Status.Ingress = []routev1.RouteIngress{{
+				Host:       host,
+				Conditions: []routev1.RouteIngressCondition{{Type: routev1.RouteAdmitted, Status: corev1.ConditionTrue}},
+			}}
+		}
+		return route
+	}
+	indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
+	for _, route := range []*routev1.Route{
+		aliasRoute("org", "console.example.org", true),
+		aliasRoute("net", "console.example.net", false),
+		aliasRoute("old", "console.example.com", true),
+	} {
+		if err := indexer.Add(route); err != nil {
+			t.Fatal(err)
+		}
+	}
+	operatorConfig := &operatorv1.Console{
+		ObjectMeta: metav1.ObjectMeta{Annotations: map[string]string{
+			HostnameAliasesAnnotation: `[{"name":"org","hostname":"console.example.org"},{"name":"net","hostname":"console.example.net"},{"name":"missing","hostname":"console.example.io"}]`,
+		}},
+	}
+
+	aliasURLs, err := GetAdmittedHostnameAliasURLs(routev1listers.NewRouteLister(indexer), operatorConfig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := []string{}
+	for _, aliasURL := range aliasURLs {
+		got = append(got, aliasURL.String())
+	}
+	if diff := deep.Equal(got, []string{"https://console.example.org"}); diff != nil {
+		t.Error(diff)
+	}
+}
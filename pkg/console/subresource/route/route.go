@@ -2,10 +2,12 @@ package route
 
 import (
 	"context"
+	"crypto/tls"
 	"crypto/x509"
 	"encoding/pem"
 	"fmt"
 	"net/url"
+	"strconv"
 	"time"
 
 	// kube
@@ -32,6 +34,11 @@ const (
 	// ingress instance named "default" is the OOTB ingresscontroller
 	// this is an implicit stable API
 	defaultIngressController = "default"
+
+	// CertificateExpiryWarningDaysAnnotation is set on the operator config to the number
+	// of days before a custom route certificate expires from which the operator warns.
+	CertificateExpiryWarningDaysAnnotation = "console.openshift.io/route-certificate-expiry-warning-days"
+	defaultCertificateExpiryWarningDays    = 30
 )
 
 // holds information about custom TLS certificate and its key
@@ -147,6 +154,10 @@ func (rc *RouteConfig) GetDefaultTLSSecretName() string {
 	return rc.defaultRoute.secretName
 }
 
+func (rc *RouteConfig) GetDefaultRouteHostname() string {
+	return rc.defaultRoute.hostname
+}
+
 func (rc *RouteConfig) GetDomain() string {
 	return rc.domain
 }
@@ -240,9 +251,118 @@ func GetCustomTLS(customCertSecret *corev1.Secret) (*CustomTLSCert, error) {
 	}
 	customTLS.Key = string(key)
 
+	if _, err := tls.X509KeyPair(cert, key); err != nil {
+		return nil, fmt.Errorf("custom TLS key does not match the certificate: %v", err)
+	}
+
 	return customTLS, nil
 }
 
+// VerifyCustomTLSCert checks that the custom certificate chains up to a CA from the
+// trusted CA bundle or the system trust, and that its SANs cover the route hostname.
+// The certificates following the leaf in certPEM are used as intermediates. The
+// validity period of the leaf is left to CertificateExpiryWarning.
+func VerifyCustomTLSCert(certPEM []byte, hostname string, trustedCABundle []byte) error {
+	certificates, err := parseCertificates(certPEM)
+	if err != nil {
+		return err
+	}
+	roots, err := x509.SystemCertPool()
+	if err != nil {
+		klog.V(4).Infof("failed to load the system cert pool: %v", err)
+		roots = x509.NewCertPool()
+	}
+	if len(trustedCABundle) != 0 && !roots.AppendCertsFromPEM(trustedCABundle) {
+		klog.V(4).Infof("failed to parse the trusted CA bundle")
+	}
+	intermediates := x509.NewCertPool()
+	for _, intermediate := range certificates[1:] {
+		intermediates.AddCert(intermediate)
+	}
+
+	leaf := certificates[0]
+	if err := leaf.VerifyHostname(hostname); err != nil {
+		return fmt.Errorf("custom TLS certificate is not valid for the route hostname: %v", err)
+	}
+	verifyTime := time.Now()
+	if verifyTime.After(leaf.NotAfter) {
+		verifyTime = leaf.NotAfter
+	}
+	if verifyTime.Before(leaf.NotBefore) {
+		verifyTime = leaf.NotBefore
+	}
+	if _, err := leaf.Verify(x509.VerifyOptions{Roots: roots, Intermediates: intermediates, CurrentTime: verifyTime}); err != nil {
+		return fmt.Errorf("custom TLS certificate chain does not verify against the trusted CA bundle: %v", err)
+	}
+	return nil
+}
+
+// GetRouteCertificate returns the leaf certificate set on the route, or nil if the
+// route uses the default ingress certificate.
+func GetRouteCertificate(route *routev1.Route) (*x509.Certificate, error) {
+	if route == nil || route.Spec.TLS == nil || len(route.Spec.TLS.Certificate) == 0 {
+		return nil, nil
+	}
+	certificates, err := parseCertificates([]byte(route.Spec.TLS.Certificate))
+	if err != nil {
+		return nil, err
+	}
+	return certificates[0], nil
+}
+
+// GetCertificateExpiryWarningDays returns how many days before expiry a custom route
+// certificate is reported, 30 unless set on the operator config.
+func GetCertificateExpiryWarningDays(operatorConfig *operatorv1.Console) (int, error) {
+	value, ok := operatorConfig.Annotations[CertificateExpiryWarningDaysAnnotation]
+	if !ok {
+		return defaultCertificateExpiryWarningDays, nil
+	}
+	days, err := strconv.Atoi(value)
+	if err != nil || days < 0 {
+		return defaultCertificateExpiryWarningDays, fmt.Errorf("invalid %s annotation %q, expected a number of days", CertificateExpiryWarningDaysAnnotation, value)
+	}
+	return days, nil
+}
+
+// CertificateExpiryWarning returns an error when the certificate expires within the
+// given number of days.
+func CertificateExpiryWarning(certificate *x509.Certificate, warningDays int, now time.Time) error {
+	if certificate == nil {
+		return nil
+	}
+	remaining := certificate.NotAfter.Sub(now)
+	if remaining > time.Duration(warningDays)*24*time.Hour {
+		return nil
+	}
+	if remaining <= 0 {
+		return fmt.Errorf("custom TLS certificate expired on %s", certificate.NotAfter.UTC().Format(time.RFC3339))
+	}
+	return fmt.Errorf("custom TLS certificate expires in %d days, on %s", int(remaining.Hours()/24), certificate.NotAfter.UTC().Format(time.RFC3339))
+}
+
+func parseCertificates(certPEM []byte) ([]*x509.Certificate, error) {
+	certificates := []*x509.Certificate{}
+	for {
+		var block *pem.Block
+		block, certPEM = pem.Decode(certPEM)
+		if block == nil {
+			break
+		}
+		if block.Type != "CERTIFICATE" {
+			continue
+		}
+		certificate, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		certificates = append(certificates, certificate)
+	}
+	if len(certificates) == 0 {
+		return nil, fmt.Errorf("failed to decode certificate PEM")
+	}
+	return certificates, nil
+}
+
 func certificateVerifier(customCert []byte) error {
 	block, _ := pem.Decode([]byte(customCert))
 	if block == nil {
@@ -1,11 +1,18 @@
 package route
 
 import (
+	"crypto/x509"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/go-test/deep"
 
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+
 	configv1 "github.com/openshift/api/config/v1"
+	"github.com/openshift/library-go/pkg/crypto"
 )
 
 func TestGetDefaultRouteHost(t *testing.T) {
@@ -39,3 +46,128 @@ func TestGetDefaultRouteHost(t *testing.T) {
 		})
 	}
 }
+
+func TestVerifyCustomTLSCert(t *testing.T) {
+	caConfig, err := crypto.MakeSelfSignedCAConfig("route-test-ca", 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ca := &crypto.CA{Config: caConfig, SerialGenerator: &crypto.RandomSerialGenerator{}}
+	serverConfig, err := ca.MakeServerCert(sets.New("console.example.com"), 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	certPEM, _, err := serverConfig.GetPEMBytes()
+	if err != nil {
+		t.Fatal(err)
+	}
+	caPEM, _, err := caConfig.GetPEMBytes()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name            string
+		hostname        string
+		trustedCABundle []byte
+		wantErr         string
+	}{
+		{
+			name:            "Test certificate signed by a trusted CA",
+			hostname:        "console.example.com",
+			trustedCABundle: caPEM,
+		},
+		{
+			name:     "Test certificate signed by an untrusted CA",
+			hostname: "console.example.com",
+			wantErr:  "does not verify against the trusted CA bundle",
+		},
+		{
+			name:            "Test certificate not covering the hostname",
+			hostname:        "console.other.com",
+			trustedCABundle: caPEM,
+			wantErr:         "is not valid for the route hostname",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := VerifyCustomTLSCert(certPEM, tt.hostname, tt.trustedCABundle)
+			if len(tt.wantErr) == 0 {
+				if err != nil {
+					t.Errorf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("expected error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestCertificateExpiryWarning(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name        string
+		certificate *x509.Certificate
+		warningDays int
+		wantErr     bool
+	}{
+		{
+			name: "Test no custom certificate",
+		},
+		{
+			name:        "Test certificate expiring after the warning window",
+			certificate: &x509.Certificate{NotAfter: now.Add(60 * 24 * time.Hour)},
+			warningDays: 30,
+		},
+		{
+			name:        "Test certificate expiring within the warning window",
+			certificate: &x509.Certificate{NotAfter: now.Add(10 * 24 * time.Hour)},
+			warningDays: 30,
+			wantErr:     true,
+		},
+		{
+			name:        "Test expired certificate",
+			certificate: &x509.Certificate{NotAfter: now.Add(-24 * time.Hour)},
+			warningDays: 30,
+			wantErr:     true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := CertificateExpiryWarning(tt.certificate, tt.warningDays, now)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("CertificateExpiryWarning() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestGetCustomTLSKeyMismatch(t *testing.T) {
+	first, err := crypto.MakeSelfSignedCAConfig("first", 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := crypto.MakeSelfSignedCAConfig("second", 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	certPEM, _, err := first.GetPEMBytes()
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, keyPEM, err := second.GetPEMBytes()
+	if err != nil {
+		t.Fatal(err)
+	}
+	secret := &corev1.Secret{
+		Data: map[string][]byte{
+			corev1.TLSCertKey:       certPEM,
+			corev1.TLSPrivateKeyKey: keyPEM,
+		},
+	}
+	if _, err := GetCustomTLS(secret); err == nil || !strings.Contains(err.Error(), "does not match the certificate") {
+		t.Errorf("expected key mismatch error, got %v", err)
+	}
+}
@@ -0,0 +1,98 @@
+package secret
+
+import (
+	"fmt"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+	"github.com/openshift/console-operator/pkg/api"
+)
+
+const (
+	// ClientSecretRotationPeriodAnnotation sets how often the client secret the console
+	// registers on the integrated OAuth server is rotated, as a duration such as "2160h".
+	ClientSecretRotationPeriodAnnotation = "console.openshift.io/oauth-client-secret-rotation-period"
+	// RotateClientSecretAnnotation requests a rotation of the client secret on demand, the
+	// secret is rotated once for every new value.
+	RotateClientSecretAnnotation = "console.openshift.io/rotate-oauth-client-secret"
+
+	minClientSecretRotationPeriod = time.Hour
+
+	clientSecretCreatedAnnotation         = "console.openshift.io/oauth-client-secret-created"
+	clientSecretRotationRequestAnnotation = "console.openshift.io/oauth-client-secret-rotation-request"
+)
+
+// GetClientSecretRotationPeriod reads the client secret rotation period set on the operator
+// config, zero when the secret is only rotated on demand. An invalid period is not applied.
+func GetClientSecretRotationPeriod(operatorConfig *operatorv1.Console) (time.Duration, error) {
+	value, ok := operatorConfig.Annotations[ClientSecretRotationPeriodAnnotation]
+	if !ok {
+		return 0, nil
+	}
+	period, err := time.ParseDuration(value)
+	if err != nil || period < minClientSecretRotationPeriod {
+		return 0, fmt.Errorf("invalid %s annotation %q, must be a duration of at least %s", ClientSecretRotationPeriodAnnotation, value, minClientSecretRotationPeriod)
+	}
+	return period, nil
+}
+
+// IsClientSecretRotationDue returns true when the client secret is older than the rotation
+// period, or a rotation was requested on demand since the last one.
+func IsClientSecretRotationDue(operatorConfig *operatorv1.Console, secret *corev1.Secret, period time.Duration, now time.Time) bool {
+	if request := operatorConfig.Annotations[RotateClientSecretAnnotation]; len(request) > 0 && request != secret.Annotations[clientSecretRotationRequestAnnotation] {
+		return true
+	}
+	created, ok := ClientSecretCreated(secret)
+	return period > 0 && ok && !now.Before(created.Add(period))
+}
+
+// StageClientSecret puts a new client secret aside for the OAuthClient to accept it before
+// the console uses it, and records the on-demand request it answers.
+func StageClientSecret(operatorConfig *operatorv1.Console, secret *corev1.Secret, nextSecretString string) {
+	secret.Data[api.NextOAuthClientSecretKey] = []byte(nextSecretString)
+	if request := operatorConfig.Annotations[RotateClientSecretAnnotation]; len(request) > 0 {
+		secret.Annotations[clientSecretRotationRequestAnnotation] = request
+	}
+}
+
+// PromoteClientSecret makes the staged client secret the one the console uses, the
+// replaced one is kept as the previous client secret.
+func PromoteClientSecret(secret *corev1.Secret, now time.Time) {
+	secret.Data[api.PreviousOAuthClientSecretKey] = secret.Data[ClientSecretKey]
+	secret.Data[ClientSecretKey] = secret.Data[api.NextOAuthClientSecretKey]
+	delete(secret.Data, api.NextOAuthClientSecretKey)
+	SetClientSecretCreated(secret, now)
+}
+
+// GetNextSecretString returns the client secret staged by a rotation.
+func GetNextSecretString(secret *corev1.Secret) string {
+	return string(secret.Data[api.NextOAuthClientSecretKey])
+}
+
+// GetPreviousSecretString returns the client secret replaced by a rotation, while the
+// console rolls out with the new one.
+func GetPreviousSecretString(secret *corev1.Secret) string {
+	return string(secret.Data[api.PreviousOAuthClientSecretKey])
+}
+
+// ClientSecretCreated returns when the client secret the console uses was generated.
+func ClientSecretCreated(secret *corev1.Secret) (time.Time, bool) {
+	value, ok := secret.Annotations[clientSecretCreatedAnnotation]
+	if !ok {
+		return time.Time{}, false
+	}
+	created, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}, false
+	}
+	return created, true
+}
+
+func SetClientSecretCreated(secret *corev1.Secret, created time.Time) {
+	if secret.Annotations == nil {
+		secret.Annotations = map[string]string{}
+	}
+	secret.Annotations[clientSecretCreatedAnnotation] = created.UTC().Format(time.RFC3339)
+}
@@ -4,6 +4,8 @@ import (
 	"crypto/rand"
 	"crypto/sha256"
 	"encoding/base64"
+	"fmt"
+	"time"
 
 	corev1 "k8s.io/api/core/v1"
 
@@ -12,6 +14,31 @@ import (
 	"github.com/openshift/console-operator/pkg/console/subresource/util"
 )
 
+const (
+	// SessionSecretRotationPeriodAnnotation sets how often the session keys are rotated,
+	// as a duration such as "720h". The keys are only regenerated when invalid without it.
+	SessionSecretRotationPeriodAnnotation = "console.openshift.io/session-secret-rotation-period"
+	// SessionSecretRotationOverlapAnnotation sets how long the previous session keys are
+	// still accepted after a rotation, it has to be shorter than the period.
+	SessionSecretRotationOverlapAnnotation = "console.openshift.io/session-secret-rotation-overlap"
+
+	minSessionSecretRotationPeriod      = time.Hour
+	defaultSessionSecretRotationOverlap = 24 * time.Hour
+
+	sessionKeysCreatedAnnotation        = "console.openshift.io/session-keys-created"
+	previousSessionKeysExpireAnnotation = "console.openshift.io/previous-session-keys-expire"
+
+	sha256KeyLenBytes = sha256.BlockSize // max key size with HMAC SHA256
+	aes256KeyLenBytes = 32               // max key size with AES (AES-256)
+)
+
+// SessionSecretRotation is how often the session keys are rotated, and how long the
+// previous keys are accepted for after a rotation.
+type SessionSecretRotation struct {
+	Period  time.Duration
+	Overlap time.Duration
+}
+
 func DefaultSessionSecret(cr *operatorv1.Console) *corev1.Secret {
 	meta := util.SharedMeta()
 	meta.Name = api.SessionSecretName
@@ -21,35 +48,126 @@ func DefaultSessionSecret(cr *operatorv1.Console) *corev1.Secret {
 	}
 	util.AddOwnerRef(secret, util.OwnerRefFrom(cr))
 
-	ResetSessionSecretKeysIfNeeded(secret)
+	ResetSessionSecretKeysIfNeeded(secret, time.Now())
 	return secret
 }
 
-func ResetSessionSecretKeysIfNeeded(secret *corev1.Secret) bool {
-	const (
-		sha256KeyLenBytes = sha256.BlockSize // max key size with HMAC SHA256
-		aes256KeyLenBytes = 32               // max key size with AES (AES-256)
-	)
+// GetSessionSecretRotation reads the session key rotation set on the operator config, nil
+// when the keys are not rotated. An invalid rotation is not applied.
+func GetSessionSecretRotation(operatorConfig *operatorv1.Console) (*SessionSecretRotation, error) {
+	periodValue, ok := operatorConfig.Annotations[SessionSecretRotationPeriodAnnotation]
+	if !ok {
+		return nil, nil
+	}
+	period, err := time.ParseDuration(periodValue)
+	if err != nil || period < minSessionSecretRotationPeriod {
+		return nil, fmt.Errorf("invalid %s annotation %q, must be a duration of at least %s", SessionSecretRotationPeriodAnnotation, periodValue, minSessionSecretRotationPeriod)
+	}
 
+	// the default overlap is cut down to half of a shorter period
+	overlap := defaultSessionSecretRotationOverlap
+	if overlap >= period {
+		overlap = period / 2
+	}
+	if overlapValue, ok := operatorConfig.Annotations[SessionSecretRotationOverlapAnnotation]; ok {
+		overlap, err = time.ParseDuration(overlapValue)
+		if err != nil || overlap <= 0 || overlap >= period {
+			return nil, fmt.Errorf("invalid %s annotation %q, must be a positive duration shorter than the %s rotation period", SessionSecretRotationOverlapAnnotation, overlapValue, period)
+		}
+	}
+	return &SessionSecretRotation{Period: period, Overlap: overlap}, nil
+}
+
+func ResetSessionSecretKeysIfNeeded(secret *corev1.Secret, now time.Time) bool {
 	var changed bool
 
 	if secret.Data == nil {
 		secret.Data = map[string][]byte{}
 	}
 
-	if len(secret.Data["sessionEncryptionKey"]) != aes256KeyLenBytes {
-		secret.Data["sessionEncryptionKey"] = []byte(randomString(aes256KeyLenBytes))
+	if len(secret.Data[api.SessionEncryptionKey]) != aes256KeyLenBytes {
+		secret.Data[api.SessionEncryptionKey] = []byte(randomString(aes256KeyLenBytes))
 		changed = true
 	}
 
-	if len(secret.Data["sessionAuthenticationKey"]) != sha256KeyLenBytes {
-		secret.Data["sessionAuthenticationKey"] = []byte(randomString(sha256KeyLenBytes))
+	if len(secret.Data[api.SessionAuthenticationKey]) != sha256KeyLenBytes {
+		secret.Data[api.SessionAuthenticationKey] = []byte(randomString(sha256KeyLenBytes))
 		changed = true
 	}
 
+	if changed {
+		setSessionSecretAnnotation(secret, sessionKeysCreatedAnnotation, now)
+	}
 	return changed
 }
 
+// RotateSessionSecretKeysIfNeeded drops the previous session keys once their overlap is
+// over, and moves the current keys over to the previous ones when they are older than the
+// rotation period. It returns whether the secret changed, and whether the keys rotated.
+func RotateSessionSecretKeysIfNeeded(secret *corev1.Secret, rotation *SessionSecretRotation, now time.Time) (changed bool, rotated bool) {
+	if secret.Annotations == nil {
+		secret.Annotations = map[string]string{}
+	}
+	if _, hasPrevious := secret.Data[api.PreviousSessionEncryptionKey]; hasPrevious {
+		if expire, ok := PreviousSessionKeysExpire(secret); !ok || !now.Before(expire) {
+			delete(secret.Data, api.PreviousSessionEncryptionKey)
+			delete(secret.Data, api.PreviousSessionAuthenticationKey)
+			// drop the annotation on apply
+			delete(secret.Annotations, previousSessionKeysExpireAnnotation)
+			secret.Annotations[previousSessionKeysExpireAnnotation+"-"] = ""
+			changed = true
+		}
+	}
+
+	created, ok := SessionKeysCreated(secret)
+	if !ok {
+		// keys from before rotation was around start their period now
+		setSessionSecretAnnotation(secret, sessionKeysCreatedAnnotation, now)
+		return true, false
+	}
+	if rotation == nil || now.Before(created.Add(rotation.Period)) {
+		return changed, false
+	}
+
+	secret.Data[api.PreviousSessionEncryptionKey] = secret.Data[api.SessionEncryptionKey]
+	secret.Data[api.PreviousSessionAuthenticationKey] = secret.Data[api.SessionAuthenticationKey]
+	delete(secret.Data, api.SessionEncryptionKey)
+	delete(secret.Data, api.SessionAuthenticationKey)
+	ResetSessionSecretKeysIfNeeded(secret, now)
+	delete(secret.Annotations, previousSessionKeysExpireAnnotation+"-")
+	setSessionSecretAnnotation(secret, previousSessionKeysExpireAnnotation, now.Add(rotation.Overlap))
+	return true, true
+}
+
+// SessionKeysCreated returns when the current session keys were generated.
+func SessionKeysCreated(secret *corev1.Secret) (time.Time, bool) {
+	return getSessionSecretAnnotation(secret, sessionKeysCreatedAnnotation)
+}
+
+// PreviousSessionKeysExpire returns until when the previous session keys are accepted.
+func PreviousSessionKeysExpire(secret *corev1.Secret) (time.Time, bool) {
+	return getSessionSecretAnnotation(secret, previousSessionKeysExpireAnnotation)
+}
+
+func getSessionSecretAnnotation(secret *corev1.Secret, annotation string) (time.Time, bool) {
+	value, ok := secret.Annotations[annotation]
+	if !ok {
+		return time.Time{}, false
+	}
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}, false
+	}
+	return t, true
+}
+
+func setSessionSecretAnnotation(secret *corev1.Secret, annotation string, t time.Time) {
+	if secret.Annotations == nil {
+		secret.Annotations = map[string]string{}
+	}
+	secret.Annotations[annotation] = t.UTC().Format(time.RFC3339)
+}
+
 // needs to be in lib-go
 func randomBytes(size int) []byte {
 	b := make([]byte, size)
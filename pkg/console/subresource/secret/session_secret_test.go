@@ -0,0 +1,129 @@
+package secret
+
+import (
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+	"github.com/openshift/console-operator/pkg/api"
+)
+
+func TestGetSessionSecretRotation(t *testing.T) {
+	tests := []struct {
+		name         string
+		annotations  map[string]string
+		wantRotation *SessionSecretRotation
+		wantErr      bool
+	}{
+		{
+			name: "No rotation",
+		},
+		{
+			name:         "Default overlap",
+			annotations:  map[string]string{SessionSecretRotationPeriodAnnotation: "720h"},
+			wantRotation: &SessionSecretRotation{Period: 720 * time.Hour, Overlap: 24 * time.Hour},
+		},
+		{
+			name:         "Default overlap cut down to half of a short period",
+			annotations:  map[string]string{SessionSecretRotationPeriodAnnotation: "12h"},
+			wantRotation: &SessionSecretRotation{Period: 12 * time.Hour, Overlap: 6 * time.Hour},
+		},
+		{
+			name: "Custom overlap",
+			annotations: map[string]string{
+				SessionSecretRotationPeriodAnnotation:  "168h",
+				SessionSecretRotationOverlapAnnotation: "48h",
+			},
+			wantRotation: &SessionSecretRotation{Period: 168 * time.Hour, Overlap: 48 * time.Hour},
+		},
+		{
+			name:        "Period too short",
+			annotations: map[string]string{SessionSecretRotationPeriodAnnotation: "10m"},
+			wantErr:     true,
+		},
+		{
+			name: "Overlap as long as the period",
+			annotations: map[string]string{
+				SessionSecretRotationPeriodAnnotation:  "24h",
+				SessionSecretRotationOverlapAnnotation: "24h",
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			operatorConfig := &operatorv1.Console{ObjectMeta: metav1.ObjectMeta{Annotations: tt.annotations}}
+			rotation, err := GetSessionSecretRotation(operatorConfig)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if (rotation == nil) != (tt.wantRotation == nil) || (rotation != nil && *rotation != *tt.wantRotation) {
+				t.Errorf("rotation = %v, want %v", rotation, tt.wantRotation)
+			}
+		})
+	}
+}
+
+func TestRotateSessionSecretKeysIfNeeded(t *testing.T) {
+	created := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	rotation := &SessionSecretRotation{Period: 720 * time.Hour, Overlap: 24 * time.Hour}
+
+	secret := &corev1.Secret{}
+	ResetSessionSecretKeysIfNeeded(secret, created)
+	originalEncryptionKey := string(secret.Data[api.SessionEncryptionKey])
+
+	// within the period
+	if changed, rotated := RotateSessionSecretKeysIfNeeded(secret, rotation, created.Add(time.Hour)); changed || rotated {
+		t.Fatalf("changed = %v, rotated = %v within the rotation period", changed, rotated)
+	}
+
+	// past the period, the current keys become the previous ones
+	rotatedAt := created.Add(rotation.Period)
+	if changed, rotated := RotateSessionSecretKeysIfNeeded(secret, rotation, rotatedAt); !changed || !rotated {
+		t.Fatalf("changed = %v, rotated = %v past the rotation period", changed, rotated)
+	}
+	if string(secret.Data[api.PreviousSessionEncryptionKey]) != originalEncryptionKey {
+		t.Errorf("previous encryption key is not the rotated one")
+	}
+	if string(secret.Data[api.SessionEncryptionKey]) == originalEncryptionKey || len(secret.Data[api.SessionAuthenticationKey]) != sha256KeyLenBytes {
+		t.Errorf("current keys were not regenerated")
+	}
+	if keysCreated, _ := SessionKeysCreated(secret); !keysCreated.Equal(rotatedAt) {
+		t.Errorf("keys created at %s, want %s", keysCreated, rotatedAt)
+	}
+
+	// within the overlap the previous keys are kept
+	if changed, _ := RotateSessionSecretKeysIfNeeded(secret, rotation, rotatedAt.Add(time.Hour)); changed {
+		t.Errorf("previous keys dropped within the overlap")
+	}
+
+	// once the overlap is over they are dropped, along with their expiry
+	if changed, rotated := RotateSessionSecretKeysIfNeeded(secret, rotation, rotatedAt.Add(rotation.Overlap)); !changed || rotated {
+		t.Fatalf("changed = %v, rotated = %v past the overlap", changed, rotated)
+	}
+	if _, ok := secret.Data[api.PreviousSessionEncryptionKey]; ok {
+		t.Errorf("previous keys are still around past the overlap")
+	}
+	if _, ok := secret.Annotations[previousSessionKeysExpireAnnotation+"-"]; !ok {
+		t.Errorf("expiry of the previous keys is not removed on apply")
+	}
+}
+
+func TestRotateSessionSecretKeysIfNeededStartsPeriod(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	secret := &corev1.Secret{}
+	ResetSessionSecretKeysIfNeeded(secret, now)
+	delete(secret.Annotations, sessionKeysCreatedAnnotation)
+
+	// keys without a creation time are not rotated right away
+	changed, rotated := RotateSessionSecretKeysIfNeeded(secret, &SessionSecretRotation{Period: time.Hour, Overlap: time.Minute}, now)
+	if !changed || rotated {
+		t.Fatalf("changed = %v, rotated = %v for keys without a creation time", changed, rotated)
+	}
+	if keysCreated, _ := SessionKeysCreated(secret); !keysCreated.Equal(now) {
+		t.Errorf("keys created at %s, want %s", keysCreated, now)
+	}
+}